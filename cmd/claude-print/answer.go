@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runAnswerJSON runs a session for --quiet --json: nothing is displayed while
+// it runs, and at the end a single JSON object with the final answer, turns,
+// cost and any error (see output.AnswerJSON) is written to stdout. The
+// session goes through the headless display, so the loop guard, cost check,
+// audit log and edit hooks apply, and is retried like any other session
+// (see runWithResume). Diagnostics go to formatter, which writes to stderr.
+// The exit code, --junit report and --pipe-to command are handled as for a
+// displayed session.
+func runAnswerJSON(opts runner.RunOptions, display *output.Display, formatter *output.Formatter, flags cli.Flags) int {
+	started := time.Now()
+	outcome, err := runWithResume(displaySession(display, nil), opts, formatter, flags)
+	if err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Failed to start Claude: %v", err)
+		runOnStartError(err, formatter, flags)
+		return reportJUnit([]output.JUnitCase{junitStartFailure(flags.Prompt, err, time.Since(started), flags)}, started, 1, formatter, flags)
+	}
+
+	exitCode := sessionExitCode(outcome, formatter, flags)
+	runOnError(outcome, exitCode, formatter, flags)
+	errMsg := ""
+	if exitCode != 0 || outcome.failed() {
+		errMsg = failureMessage(outcome, "", exitCode)
+	}
+	if err := output.WriteAnswerJSON(os.Stdout, output.NewAnswerJSON(outcome.Result, errMsg)); err != nil {
+		fmt.Fprintf(os.Stderr, "claude-print: writing answer JSON: %v\n", err)
+		if exitCode == 0 {
+			exitCode = 1
+		}
+	}
+	return reportSession(outcome, exitCode, started, formatter, flags)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runBatch runs each prompt as its own session with run and the same
+// options, then shows an aggregate cost and usage report and, with
+// --batch-report, writes it there as CSV (for a .csv path) or JSON. With
+// --junit, each prompt is also a test case in a JUnit XML report. A signal,
+// a declined cost prompt or a failure to start Claude (which would fail the
+// same way for every prompt) stops the batch; the reports cover the prompts
+// run so far, including the one Claude couldn't be started for. Returns 0
+// if every prompt succeeded, otherwise the exit code of the last failure.
+func runBatch(prompts []string, run sessionRunner, opts runner.RunOptions, display *output.Display, formatter *output.Formatter, flags cli.Flags) int {
+	var runs []output.BatchRun
+	var cases []output.JUnitCase
+	exitCode := 0
+	batchStarted := time.Now()
+
+	for _, prompt := range prompts {
+		opts.Prompt = prompt
+		display.SetUserPrompt(prompt)
+		display.ShowStart()
+		display.Spinner.Start()
+
+		started := time.Now()
+		outcome, err := runWithResume(run, opts, formatter, flags)
+		if err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "Failed to start Claude: %v", err)
+			runOnStartError(err, formatter, flags)
+			runs = append(runs, output.BatchRun{Prompt: prompt, ExitCode: 1})
+			cases = append(cases, junitStartFailure(prompt, err, time.Since(started), flags))
+			exitCode = 1
+			break
+		}
+		code := sessionExitCode(outcome, formatter, flags)
+		runOnError(outcome, code, formatter, flags)
+		runs = append(runs, output.BatchRun{Prompt: prompt, ExitCode: code, Result: outcome.Result})
+		cases = append(cases, junitCase(prompt, outcome, code, time.Since(started), flags))
+		if code != 0 {
+			exitCode = code
+		}
+		if outcome.Signal != nil || outcome.CostDeclined {
+			break
+		}
+	}
+
+	display.ShowBatchReport(runs)
+	if flags.BatchReport != "" {
+		if err := writeBatchReport(flags.BatchReport, runs); err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "Error writing batch report: %v", err)
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+	return reportJUnit(cases, batchStarted, exitCode, formatter, flags)
+}
+
+// writeBatchReport writes runs to path, as CSV if it ends in ".csv" and as
+// JSON otherwise.
+func writeBatchReport(path string, runs []output.BatchRun) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = output.WriteBatchReportCSV(f, runs)
+	} else {
+		err = output.WriteBatchReportJSON(f, runs)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+func TestRunBatch_StartFailure(t *testing.T) {
+	dir := t.TempDir()
+	flags := cli.Flags{JUnit: filepath.Join(dir, "junit.xml"), BatchReport: filepath.Join(dir, "report.json")}
+	var buf bytes.Buffer
+	formatter := output.NewFormatter(false, false, &buf)
+	display := output.NewDisplay(formatter, output.VerbosityNormal)
+
+	// The first prompt runs; Claude can't be started for the second, which
+	// stops the batch before the third
+	var prompts []string
+	run := func(opts runner.RunOptions) (sessionOutcome, error) {
+		prompts = append(prompts, opts.Prompt)
+		if len(prompts) == 2 {
+			return sessionOutcome{}, errors.New("exec: claude: not found")
+		}
+		return resultOutcome("s1", false, "done"), nil
+	}
+	if code := runBatch([]string{"one", "two", "three"}, run, runner.RunOptions{}, display, formatter, flags); code != 1 {
+		t.Errorf("runBatch() = %d, want 1", code)
+	}
+	if len(prompts) != 2 {
+		t.Errorf("ran %q, want the batch stopped after the start failure", prompts)
+	}
+	if !strings.Contains(buf.String(), "Failed to start Claude: exec: claude: not found") {
+		t.Errorf("expected the start failure reported, got %q", buf.String())
+	}
+
+	junit, err := os.ReadFile(flags.JUnit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(junit), `tests="2" failures="1"`) || !strings.Contains(string(junit), "failed to start Claude") {
+		t.Errorf("expected both prompts in the JUnit report with the start failure, got:\n%s", junit)
+	}
+	report, err := os.ReadFile(flags.BatchReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), `"prompt": "two"`) {
+		t.Errorf("expected the failed prompt in the batch report, got:\n%s", report)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+// confirmCost returns a --confirm-cost callback that asks on the display
+// whether to continue and reads the answer from stdin. Only "y" or "yes"
+// continues; EOF declines. Ctrl+C at the prompt also declines, so the
+// event loop can go on to stop Claude (see streamSession).
+func confirmCost(formatter *output.Formatter) func(costUSD float64) bool {
+	return func(costUSD float64) bool {
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(interrupt)
+		return askContinue(formatter, costUSD, stdinLines(), interrupt)
+	}
+}
+
+// askContinue shows the --confirm-cost prompt and waits for an answer from
+// lines. It declines when lines is closed or a signal arrives on interrupt
+// first.
+func askContinue(formatter *output.Formatter, costUSD float64, lines <-chan string, interrupt <-chan os.Signal) bool {
+	formatter.Plain("")
+	formatter.WarningWithEmoji(output.EmojiWarning, "This run has cost about $%.2f so far (estimated).", costUSD)
+	formatter.PlainNoNewline("Continue? [y/N] ")
+	formatter.Flush()
+	select {
+	case line, ok := <-lines:
+		answer := strings.ToLower(strings.TrimSpace(line))
+		return ok && (answer == "y" || answer == "yes")
+	case <-interrupt:
+		formatter.Plain("")
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+func TestAskContinue(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		closed bool
+		want   bool
+	}{
+		{"yes", "Yes\n", false, true},
+		{"y", " y ", false, true},
+		{"no", "n", false, false},
+		{"empty", "", false, false},
+		{"EOF", "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make(chan string, 1)
+			if tt.closed {
+				close(lines)
+			} else {
+				lines <- tt.answer
+			}
+			var buf strings.Builder
+			got := askContinue(output.NewFormatter(false, false, &buf), 2.5, lines, nil)
+			if got != tt.want {
+				t.Errorf("askContinue(%q) = %v, want %v", tt.answer, got, tt.want)
+			}
+			if !strings.Contains(buf.String(), "$2.50") || !strings.HasSuffix(buf.String(), "Continue? [y/N] ") {
+				t.Errorf("unexpected prompt %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestAskContinue_InterruptDeclines(t *testing.T) {
+	interrupt := make(chan os.Signal, 1)
+	done := make(chan bool)
+	go func() {
+		// No answer ever arrives on stdin
+		done <- askContinue(output.NewFormatter(false, false, &strings.Builder{}), 1, make(chan string), interrupt)
+	}()
+	interrupt <- syscall.SIGINT
+	select {
+	case got := <-done:
+		if got {
+			t.Error("expected an interrupted prompt to decline")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("prompt still waiting for stdin after an interrupt")
+	}
+}
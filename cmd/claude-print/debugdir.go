@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// Artifacts written to a --debug-dir session directory.
+const (
+	debugStreamFile     = "stream.jsonl"   // raw JSON stream, as --debug-log writes it
+	debugTranscriptFile = "transcript.txt" // display output as plain text
+	debugStatsFile      = "stats.json"     // how each Claude session ended, as --batch-report writes it
+	debugCastFile       = "session.cast"   // display recording, as --record writes it
+	debugAuditFile      = "audit.jsonl"    // tool call log, as --audit-log writes it
+)
+
+// debugDir is the directory one run's debug artifacts are collected in, so
+// a run's diagnostics sit together instead of each in its own place. The
+// debug log, transcript, recording and audit log are written as the run
+// goes; stats.json is written by Close from Sessions.
+type debugDir struct {
+	path           string
+	transcriptFile *os.File
+	transcript     *output.TranscriptWriter
+	Sessions       *output.SessionLog // Sessions of the run, for stats.json
+}
+
+// newDebugDir creates a session directory named after the current time
+// under root, creating root if needed, and opens the transcript in it. A
+// numeric suffix keeps runs started in the same second apart.
+func newDebugDir(root string) (*debugDir, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	base := filepath.Join(root, "session-"+time.Now().Format("2006-01-02_150405"))
+	path := base
+	for n := 2; ; n++ {
+		err := os.Mkdir(path, 0o700)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		path = base + "-" + strconv.Itoa(n)
+	}
+	f, err := os.Create(filepath.Join(path, debugTranscriptFile))
+	if err != nil {
+		return nil, err
+	}
+	return &debugDir{
+		path:           path,
+		transcriptFile: f,
+		transcript:     output.NewTranscriptWriter(f),
+		Sessions:       &output.SessionLog{},
+	}, nil
+}
+
+// Path returns the session directory.
+func (d *debugDir) Path() string {
+	return d.path
+}
+
+// file returns the path of the named artifact in the directory.
+func (d *debugDir) file(name string) string {
+	return filepath.Join(d.path, name)
+}
+
+// Transcript returns the writer display output is copied to for the
+// transcript.
+func (d *debugDir) Transcript() io.Writer {
+	return d.transcript
+}
+
+// EnableDebugLog starts the runner's debug log in the directory.
+func (d *debugDir) EnableDebugLog(version string, opts runner.RunOptions) error {
+	return runner.EnableDebugLogFile(d.file(debugStreamFile), version, opts)
+}
+
+// startDebugLog starts the runner's debug log for --debug-log, or else in
+// debugArtifacts if --debug-dir was given, keeping only the event types
+// --debug-log-filter selects. It reports whether a log was started; the
+// caller closes it with runner.CloseDebugLogging.
+func startDebugLog(flags cli.Flags, debugArtifacts *debugDir, opts runner.RunOptions) (bool, error) {
+	if flags.DebugLog == "" && debugArtifacts == nil {
+		return false, nil
+	}
+	runner.SetDebugLogFilter(flags.DebugLogFilter)
+	runner.SetDebugHidePrompt(flags.HidePrompt)
+	var err error
+	if flags.DebugLog != "" {
+		err = runner.EnableDebugLogging(flags.DebugLog, version, opts)
+	} else {
+		err = debugArtifacts.EnableDebugLog(version, opts)
+	}
+	return err == nil, err
+}
+
+// Close finishes the transcript and writes stats.json.
+func (d *debugDir) Close() error {
+	err := d.transcript.Close()
+	if closeErr := d.transcriptFile.Close(); err == nil {
+		err = closeErr
+	}
+	if statsErr := writeBatchReport(d.file(debugStatsFile), d.Sessions.Runs()); err == nil {
+		err = statsErr
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+func TestNewDebugDir_SeparateSessionDirs(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "debug")
+	first, err := newDebugDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := newDebugDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	if first.Path() == second.Path() || filepath.Dir(first.Path()) != root || filepath.Dir(second.Path()) != root {
+		t.Errorf("expected two session directories under %s, got %s and %s", root, first.Path(), second.Path())
+	}
+	if !strings.HasPrefix(filepath.Base(first.Path()), "session-") {
+		t.Errorf("expected a session- directory, got %s", first.Path())
+	}
+	if got := first.file(debugCastFile); got != filepath.Join(first.Path(), "session.cast") {
+		t.Errorf("file(debugCastFile) = %s", got)
+	}
+}
+
+func TestDebugDir_CloseWritesArtifacts(t *testing.T) {
+	dir, err := newDebugDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	display := output.NewDisplay(output.NewFormatter(false, false, output.NewTeeWriter(&strings.Builder{}, dir.Transcript())), output.VerbosityNormal)
+	display.Sessions = dir.Sessions
+	display.HidePrompt = true
+
+	fmt.Fprint(display.Writer(), "answer\r\x1b[K")
+	fmt.Fprint(display.Writer(), "\x1b[32mDone\x1b[0m\n")
+	display.RecordSession("secret prompt", 0, &events.ResultEvent{NumTurns: 2, TotalCostUSD: 0.5})
+	display.RecordSession("secret prompt", 1, nil)
+	if err := dir.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript, err := os.ReadFile(dir.file(debugTranscriptFile))
+	if err != nil || string(transcript) != "Done\n" {
+		t.Errorf("unexpected transcript %q, %v", transcript, err)
+	}
+	stats, err := os.ReadFile(dir.file(debugStatsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"prompt": "[prompt hidden]"`, `"turns": 2`, `"status": "failed"`, `"runs": 2`} {
+		if !strings.Contains(string(stats), want) {
+			t.Errorf("expected stats.json to contain %s, got %s", want, stats)
+		}
+	}
+	if strings.Contains(string(stats), "secret") {
+		t.Errorf("expected the prompt hidden, got %s", stats)
+	}
+}
+
+func TestStartDebugLog_Filter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script as the Claude CLI")
+	}
+	if enabled, err := startDebugLog(cli.Flags{}, nil, runner.RunOptions{}); enabled || err != nil {
+		t.Errorf("startDebugLog() = %v, %v without --debug-log or --debug-dir, want no log", enabled, err)
+	}
+
+	dir, err := newDebugDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dir.Close()
+	t.Cleanup(func() { runner.SetDebugLogFilter(nil) })
+	claude := fakeClaude(t,
+		`{"type":"system","subtype":"init","session_id":"s1"}`,
+		`{"type":"result","subtype":"success","session_id":"s1","result":"done"}`)
+	opts := runner.RunOptions{ClaudePath: claude, Prompt: "hi"}
+	if enabled, err := startDebugLog(cli.Flags{DebugLogFilter: []string{"result"}}, dir, opts); !enabled || err != nil {
+		t.Fatalf("startDebugLog() = %v, %v, want the debug directory's log started", enabled, err)
+	}
+
+	display := output.NewDisplay(output.NewFormatter(false, false, &strings.Builder{}), output.VerbosityNormal)
+	_, err = runSession(opts, display, nil)
+	runner.CloseDebugLogging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := os.ReadFile(dir.file(debugStreamFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(log), `"type":"result"`) || strings.Contains(string(log), `"type":"system"`) {
+		t.Errorf("expected only the result event logged, got:\n%s", log)
+	}
+}
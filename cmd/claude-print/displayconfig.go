@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+// displayVerbosity returns the display's verbosity for cfg, the resolved
+// config. It only changes what claude-print shows: Claude runs the same way
+// at every verbosity.
+func displayVerbosity(cfg config.Config) output.Verbosity {
+	switch cfg.DefaultVerbosity {
+	case "verbose":
+		return output.VerbosityVerbose
+	case "quiet":
+		return output.VerbosityQuiet
+	default:
+		return output.VerbosityNormal
+	}
+}
+
+// applyDisplayConfig copies the display settings of cfg, the resolved
+// config (see resolveConfig), onto display.
+func applyDisplayConfig(display *output.Display, cfg config.Config) {
+	display.ShowMetadata = cfg.ShowMetadata
+	display.AssistantLabel = cfg.AssistantLabel
+	display.ToolLabel = cfg.ToolLabel
+	display.WarnCostUSD = cfg.WarnCostUSD
+	display.WarnDurationMS = cfg.WarnDurationMS
+	display.MatchLimit = cfg.VerboseMatchLimit
+	display.IndentWidth = cfg.IndentWidth
+	display.MaxToolParamBytes = cfg.MaxToolParamBytes
+	display.LoopGuard = cfg.LoopGuard
+	display.SummaryTemplate = cfg.SummaryTemplate
+	display.SummaryFields = cfg.SummaryFields
+	display.ShowCachedTokens = cfg.ShowCachedTokens
+	display.BlocksSpillBytes = cfg.BlocksSpillBytes
+}
+
+// applyDisplayFlags copies the display settings that only come from the
+// command line onto display.
+func applyDisplayFlags(display *output.Display, flags cli.Flags) {
+	display.ShowFileSummary = flags.FileSummary
+	display.HideToolOutput = flags.NoToolOutput
+	display.ShowLabels = flags.Labels
+	display.HidePrompt = flags.HidePrompt
+	display.RelativeTime = flags.RelativeTime
+	display.RawBashOutput = flags.RawBashOutput
+	display.UnsafeRawBashOutput = flags.RawBashUnsafe
+	display.Wrap = flags.Wrap
+	// These modes write their own output; the display only tracks events
+	display.Headless = flags.RawEvents || (flags.Quiet && flags.JSON)
+}
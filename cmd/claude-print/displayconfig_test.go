@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+func TestDisplayVerbosity(t *testing.T) {
+	tests := []struct {
+		args []string
+		want output.Verbosity
+	}{
+		{[]string{"hi"}, output.VerbosityNormal},
+		{[]string{"--verbose", "hi"}, output.VerbosityVerbose},
+		{[]string{"--quiet", "hi"}, output.VerbosityQuiet},
+	}
+	for _, tt := range tests {
+		flags := parseArgs(t, tt.args...)
+		cfg, _ := resolveConfig(config.DefaultConfig(), flags, noEnv)
+		if got := displayVerbosity(cfg); got != tt.want {
+			t.Errorf("%q: displayVerbosity() = %d, want %d", tt.args, got, tt.want)
+		}
+
+		// Claude is run the same way whatever the display shows, with the
+		// --verbose stream-json needs passed once
+		args := runner.ClaudeArgs(newRunOptions(cfg, flags, "claude", nil))
+		if n := slices.Index(args, "--verbose"); n < 0 || slices.Contains(args[n+1:], "--verbose") {
+			t.Errorf("%q: ran Claude with %q, want --verbose exactly once", tt.args, args)
+		}
+	}
+}
+
+func TestApplyDisplayConfig_SummaryTemplate(t *testing.T) {
+	cfg, _ := resolveConfig(config.DefaultConfig(), cli.Flags{}, noEnv)
+	cfg.SummaryTemplate = "{status} after {turns} turns"
+
+	var buf bytes.Buffer
+	display := output.NewDisplay(output.NewFormatter(false, false, &buf), output.VerbosityNormal)
+	applyDisplayConfig(display, cfg)
+	result := events.ResultEvent{Subtype: "success", NumTurns: 3}
+	result.Type = "result"
+	display.HandleEvent(result)
+
+	if !strings.Contains(buf.String(), "Session complete after 3 turns") {
+		t.Errorf("expected the completion line from summaryTemplate, got:\n%s", buf.String())
+	}
+	if display.MaxToolParamBytes != output.DefaultMaxToolParamBytes || display.IndentWidth != output.DefaultIndentWidth {
+		t.Errorf("expected the resolved defaults applied, got maxToolParamBytes %d, indentWidth %d", display.MaxToolParamBytes, display.IndentWidth)
+	}
+}
+
+func TestApplyDisplayFlags_FileSummary(t *testing.T) {
+	for _, args := range [][]string{{"hi"}, {"--file-summary", "hi"}} {
+		var buf bytes.Buffer
+		display := output.NewDisplay(output.NewFormatter(false, false, &buf), output.VerbosityNormal)
+		applyDisplayFlags(display, parseArgs(t, args...))
+
+		write := events.AssistantEvent{}
+		write.Type = "assistant"
+		write.Message.Content = []events.ContentBlock{{Type: "tool_use", ID: "w1", Name: "Write", Input: map[string]interface{}{"file_path": "main.go"}}}
+		display.HandleEvent(write)
+		result := events.ResultEvent{Subtype: "success"}
+		result.Type = "result"
+		display.HandleEvent(result)
+
+		want := len(args) > 1
+		if got := strings.Contains(buf.String(), "Written/Edited (1):"); got != want {
+			t.Errorf("%q: file summary shown %v, want %v:\n%s", args, got, want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+// displayWriterOptions selects the optional layers of the display writer.
+type displayWriterOptions struct {
+	StripANSI     bool           // --strip-ansi: remove ANSI sequences before the sink
+	Cast          io.WriteCloser // --record: cast file the output is recorded to, if non-nil
+	CastWidth     int            // Terminal width recorded in the cast header
+	FlushInterval time.Duration  // streamFlushMS: coalesce writes for this long, if positive
+}
+
+// newDisplayWriter builds the writer chain display output goes through on
+// its way to sink: ANSI stripping nearest the sink, then the cast recorder,
+// then write coalescing on top, so every layer sees all of the output. The
+// returned close function flushes the coalesced output and finishes the
+// cast; it must be called once the display is done. The cast file is
+// closed if the chain can't be built.
+func newDisplayWriter(sink io.Writer, opts displayWriterOptions) (io.Writer, func(), error) {
+	w := sink
+	var closers []func()
+
+	if opts.StripANSI {
+		w = output.NewANSIStripWriter(w)
+	}
+	if opts.Cast != nil {
+		recorder, err := output.NewCastWriter(w, opts.Cast, opts.CastWidth, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		closers = append(closers, func() { recorder.Close() })
+		w = recorder
+	}
+	if opts.FlushInterval > 0 {
+		coalescer := output.NewCoalescingWriter(w, opts.FlushInterval)
+		closers = append(closers, func() { coalescer.Close() })
+		w = coalescer
+	}
+
+	// Close from the top down, so buffered output reaches the recorder
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+	return w, closeAll, nil
+}
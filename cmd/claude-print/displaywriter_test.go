@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// closeBuffer is a bytes.Buffer that records being closed, standing in for
+// a cast file.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestNewDisplayWriter_RecordWithCoalescing(t *testing.T) {
+	var sink bytes.Buffer
+	cast := &closeBuffer{}
+	w, closeAll, err := newDisplayWriter(&sink, displayWriterOptions{
+		StripANSI:     true,
+		Cast:          cast,
+		FlushInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(w, "\x1b[32mhello\x1b[0m\n")
+	if sink.Len() != 0 {
+		t.Errorf("expected output held by the coalescer until flushed, got %q", sink.String())
+	}
+	closeAll()
+
+	if sink.String() != "hello\n" {
+		t.Errorf("expected the coalesced output stripped of ANSI at the sink, got %q", sink.String())
+	}
+	if !cast.closed || !strings.Contains(cast.String(), `hello\u001b[0m\r\n"]`) {
+		t.Errorf("expected the cast to record the coalesced output and be closed, got %q (closed %v)", cast.String(), cast.closed)
+	}
+}
+
+func TestNewDisplayWriter_NoLayers(t *testing.T) {
+	var sink bytes.Buffer
+	w, closeAll, err := newDisplayWriter(&sink, displayWriterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeAll()
+	if w != &sink {
+		t.Errorf("expected the sink itself without any layers, got %T", w)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/detect"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// doctor prints a pass/warn/fail checklist and remembers whether any
+// critical check failed.
+type doctor struct {
+	formatter *output.Formatter
+	failed    bool
+}
+
+func (d *doctor) pass(format string, args ...interface{}) {
+	d.formatter.SuccessWithEmoji(output.EmojiDone, "PASS  "+format, args...)
+}
+
+func (d *doctor) warn(format string, args ...interface{}) {
+	d.formatter.WarningWithEmoji(output.EmojiWarning, "WARN  "+format, args...)
+}
+
+func (d *doctor) fail(format string, args ...interface{}) {
+	d.formatter.ErrorWithEmoji(output.EmojiError, "FAIL  "+format, args...)
+	d.failed = true
+}
+
+// runDoctor checks the config file, Claude CLI path, version, and output
+// settings, printing a checklist to out. Returns 1 if any critical check
+// failed.
+func runDoctor(flags cli.Flags, out *os.File) int {
+	formatter := output.NewFormatter(
+		output.ShouldEnableColor(flags.NoColor, true, out),
+		output.ShouldEnableEmoji(flags.NoEmoji, true),
+		out,
+	)
+	d := &doctor{formatter: formatter}
+
+	formatter.Info("claude-print %s diagnostics", version)
+	formatter.Plain("")
+
+	// Config file
+	cfgPath, _ := config.FilePath()
+	cfg, err := config.LoadConfig()
+	if errors.Is(err, config.ErrNoHomeDir) {
+		d.warn("Config file: %v, using defaults", err)
+	} else if errors.Is(err, config.ErrInsecurePermissions) {
+		d.warn("Config file: %v", err)
+	} else if err != nil {
+		d.fail("Config file: %v", err)
+		cfg = config.DefaultConfig()
+	} else if _, statErr := os.Stat(cfgPath); statErr != nil {
+		d.pass("Config file: not present, using defaults (%s)", cfgPath)
+	} else {
+		d.pass("Config file parses (%s)", cfgPath)
+	}
+
+	// Auto-detection
+	detectedPath, detectErr := detect.DetectClaudePath()
+	if detectErr != nil {
+		if cfg.ClaudePath == "" {
+			d.fail("Claude CLI detection: %v", detectErr)
+		} else {
+			d.warn("Claude CLI not found on PATH (using configured claudePath)")
+		}
+	} else {
+		d.pass("Claude CLI detected on PATH: %s", detectedPath)
+	}
+
+	// Effective Claude path
+	claudePath := cfg.ClaudePath
+	if claudePath == "" {
+		claudePath = detectedPath
+	}
+	if claudePath != "" {
+		if err := config.ValidatePath(claudePath); err != nil {
+			d.fail("Claude CLI path: %v", err)
+			claudePath = ""
+		} else {
+			d.pass("Claude CLI path is valid: %s", claudePath)
+		}
+	}
+
+	// Claude CLI version
+	if claudePath != "" {
+		ver, err := detect.ClaudeVersion(claudePath)
+		switch {
+		case err != nil:
+			d.fail("Claude CLI version: %v", err)
+		case detect.CompareVersions(ver, detect.MinClaudeVersion) < 0:
+			d.fail("Claude CLI version %s is older than required %s", ver, detect.MinClaudeVersion)
+		default:
+			d.pass("Claude CLI version %s (>= %s)", ver, detect.MinClaudeVersion)
+		}
+	}
+
+	// Streaming flags claude-print runs Claude with
+	if claudePath != "" {
+		required := runner.RequiredArgs(cfg.RequiredFlags)
+		missing, err := detect.MissingFlags(claudePath, required)
+		switch {
+		case err != nil:
+			d.warn("Could not check the Claude CLI's flags: %v", err)
+		case len(missing) > 0:
+			d.fail("Claude CLI does not support %s", strings.Join(missing, ", "))
+		default:
+			d.pass("Claude CLI supports %s", strings.Join(required, " "))
+		}
+	}
+
+	// Verbosity setting
+	switch cfg.DefaultVerbosity {
+	case "", "normal", "verbose", "quiet":
+		d.pass("defaultVerbosity: %q", cfg.DefaultVerbosity)
+	default:
+		d.warn("defaultVerbosity %q is not one of normal, verbose, quiet (normal will be used)", cfg.DefaultVerbosity)
+	}
+
+	// CLI dialect overrides
+	if cfg.PromptFlag != "" || len(cfg.RequiredFlags) > 0 {
+		if err := cli.ValidateDialectFlags(cfg.PromptFlag, cfg.RequiredFlags); err != nil {
+			d.fail("CLI dialect: %v", err)
+		} else {
+			d.pass("CLI dialect overrides: promptFlag %q, requiredFlags %v", cfg.PromptFlag, cfg.RequiredFlags)
+		}
+	}
+
+	// Extra environment
+	if len(cfg.Env) > 0 {
+		if _, err := cli.EnvAssignments(cfg.Env, nil); err != nil {
+			d.fail("Config env: %v", err)
+		} else {
+			d.pass("Config env: %d extra variable(s) for Claude", len(cfg.Env))
+		}
+	}
+
+	// Color and emoji coherence
+	colorEnabled := output.ShouldEnableColor(flags.NoColor, cfg.ColorEnabled, out)
+	switch {
+	case cfg.ColorEnabled && !colorEnabled:
+		d.warn("colorEnabled is true but color is off here (--no-color, NO_COLOR, or stdout is not a TTY)")
+	default:
+		d.pass("Color output: %v", colorEnabled)
+	}
+	emojiEnabled := output.ShouldEnableEmoji(flags.NoEmoji, cfg.EmojiEnabled)
+	switch {
+	case cfg.EmojiEnabled && !emojiEnabled:
+		d.warn("emojiEnabled is true but emoji are off here (--no-emoji or NO_EMOJI)")
+	default:
+		d.pass("Emoji output: %v", emojiEnabled)
+	}
+
+	formatter.Plain("")
+	if d.failed {
+		formatter.Error("Some critical checks failed")
+		return 1
+	}
+	formatter.Success("All critical checks passed")
+	return 0
+}
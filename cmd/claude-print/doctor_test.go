@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+)
+
+// runDoctorWith runs the checks against a config file holding config, with
+// nothing on PATH, and returns the exit code and the checklist.
+func runDoctorWith(t *testing.T, config string) (int, string) {
+	t.Helper()
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".claude-print-config.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("PATH", t.TempDir())
+
+	out, err := os.Create(filepath.Join(home, "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	code := runDoctor(cli.Flags{NoColor: true, NoEmoji: true}, out)
+	checklist, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(checklist)
+}
+
+func TestRunDoctor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script as the Claude CLI")
+	}
+	claude := filepath.Join(t.TempDir(), "claude")
+	script := `#!/bin/sh
+case "$1" in
+--version) echo "2.1.0 (Claude Code)" ;;
+--help) echo "  -p, --print  --verbose  --include-partial-messages  --output-format <format> (text, json, stream-json)" ;;
+esac
+`
+	if err := os.WriteFile(claude, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Warnings alone don't fail the checks
+	code, checklist := runDoctorWith(t, `{"claudePath": "`+claude+`", "defaultVerbosity": "loud"}`)
+	if code != 0 {
+		t.Errorf("runDoctor() = %d, want 0:\n%s", code, checklist)
+	}
+	for _, want := range []string{
+		"WARN  Claude CLI not found on PATH (using configured claudePath)",
+		"PASS  Claude CLI version 2.1.0",
+		"PASS  Claude CLI supports",
+		`WARN  defaultVerbosity "loud"`,
+		"All critical checks passed",
+	} {
+		if !strings.Contains(checklist, want) {
+			t.Errorf("expected %q in the checklist, got:\n%s", want, checklist)
+		}
+	}
+
+	// A configured Claude CLI that isn't there is critical
+	code, checklist = runDoctorWith(t, `{"claudePath": "`+filepath.Join(t.TempDir(), "missing")+`"}`)
+	if code != 1 || !strings.Contains(checklist, "FAIL  Claude CLI path") {
+		t.Errorf("runDoctor() = %d, want 1 with the path failing:\n%s", code, checklist)
+	}
+}
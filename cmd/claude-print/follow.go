@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runFollow renders the events of a JSONL debug log through display as the
+// file grows (--follow), without running Claude. It stops on Ctrl+C or
+// SIGTERM, which is the normal way to end it, so that returns 0.
+func runFollow(path string, display *output.Display, formatter *output.Formatter) int {
+	stop := make(chan struct{})
+	eventChan, errChan, err := runner.FollowEvents(path, stop)
+	if err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Cannot follow %s: %v", path, err)
+		return 1
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+	go func() {
+		<-sigChan
+		close(stop)
+	}()
+
+	for event := range eventChan {
+		handleEventSafely(display, event)
+	}
+	display.ClearToolStatus()
+
+	if err := <-errChan; err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Reading %s failed: %v", path, err)
+		return 1
+	}
+	return 0
+}
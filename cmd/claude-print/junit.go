@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+// junitCase describes a finished session as a --junit test case named after
+// its prompt. A session failed by --fail-threshold reports its tool errors
+// as the failure; otherwise the failure is the same message --on-error gets.
+// Without --fail-threshold, any failed tool call fails the case, though not
+// the run.
+func junitCase(prompt string, outcome sessionOutcome, exitCode int, elapsed time.Duration, flags cli.Flags) output.JUnitCase {
+	if flags.HidePrompt {
+		prompt = output.HiddenPrompt
+	}
+	c := output.JUnitCase{Name: prompt, ExitCode: exitCode, Elapsed: elapsed, Result: outcome.Result}
+	switch {
+	case exitCode == 0 && flags.FailThreshold == nil && outcome.Result != nil && outcome.Result.TotalToolErrors > 0:
+		c.Failure = failureMessage(outcome, cli.OnErrorToolErrors, exitCode)
+		c.FailureType = "tool errors"
+	case exitCode == 0:
+	case exitCode == exitToolErrors && outcome.Result != nil:
+		c.Failure = failureMessage(outcome, cli.OnErrorToolErrors, exitCode)
+	default:
+		c.Failure = failureMessage(outcome, "", exitCode)
+	}
+	return c
+}
+
+// junitStartFailure describes a session Claude couldn't be started for as a
+// failed --junit test case.
+func junitStartFailure(prompt string, err error, elapsed time.Duration, flags cli.Flags) output.JUnitCase {
+	c := junitCase(prompt, sessionOutcome{ExitCode: 1}, 1, elapsed, flags)
+	c.Failure = "failed to start Claude: " + err.Error()
+	return c
+}
+
+// reportJUnit writes cases to the --junit report, if one was requested, and
+// returns exitCode, or 1 when the report can't be written for a run that
+// otherwise succeeded.
+func reportJUnit(cases []output.JUnitCase, started time.Time, exitCode int, formatter *output.Formatter, flags cli.Flags) int {
+	if flags.JUnit == "" {
+		return exitCode
+	}
+	if err := writeJUnitReport(flags.JUnit, cases, started); err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Error writing JUnit report: %v", err)
+		if exitCode == 0 {
+			return 1
+		}
+	}
+	return exitCode
+}
+
+// writeJUnitReport writes cases to path as a JUnit XML report.
+func writeJUnitReport(path string, cases []output.JUnitCase, started time.Time) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = output.WriteJUnitReport(f, cases, started)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
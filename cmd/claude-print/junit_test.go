@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+func TestJUnitCase(t *testing.T) {
+	threshold := 0.5
+	toolErrors := sessionOutcome{Result: &events.ResultEvent{TotalToolUse: 4, TotalToolErrors: 1}}
+	tests := []struct {
+		name        string
+		outcome     sessionOutcome
+		exitCode    int
+		flags       cli.Flags
+		failure     string
+		failureType string
+	}{
+		{"success", sessionOutcome{Result: &events.ResultEvent{TotalToolUse: 4}}, 0, cli.Flags{}, "", ""},
+		{"tool errors", toolErrors, 0, cli.Flags{}, "1 of 4 tool calls failed", "tool errors"},
+		{"tool errors under threshold", toolErrors, 0, cli.Flags{FailThreshold: &threshold}, "", ""},
+		{"tool errors over threshold", toolErrors, exitToolErrors, cli.Flags{FailThreshold: &threshold}, "1 of 4 tool calls failed", ""},
+		{"error result", sessionOutcome{Result: &events.ResultEvent{IsError: true, Result: "API Error: 500"}}, 1, cli.Flags{}, "API Error: 500", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := junitCase("prompt", tt.outcome, tt.exitCode, time.Second, tt.flags)
+			if c.Failure != tt.failure || c.FailureType != tt.failureType || c.ExitCode != tt.exitCode {
+				t.Errorf("junitCase() = %+v, want failure %q (%q)", c, tt.failure, tt.failureType)
+			}
+		})
+	}
+
+	if c := junitCase("secret", toolErrors, 0, time.Second, cli.Flags{HidePrompt: true}); c.Name != output.HiddenPrompt {
+		t.Errorf("expected a hidden prompt to name the case %q, got %q", output.HiddenPrompt, c.Name)
+	}
+}
+
+func TestReportJUnit_StartFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	flags := cli.Flags{JUnit: path}
+	formatter := output.NewFormatter(false, false, &bytes.Buffer{})
+
+	cases := []output.JUnitCase{junitStartFailure("hi", errors.New("exec: \"claude\": not found"), time.Second, flags)}
+	if code := reportJUnit(cases, time.Now(), 1, formatter, flags); code != 1 {
+		t.Errorf("reportJUnit() = %d, want 1", code)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `failures="1"`) || !strings.Contains(string(data), "failed to start Claude: exec: &#34;claude&#34;: not found") {
+		t.Errorf("expected the start failure in the report, got:\n%s", data)
+	}
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/peakflames/claude-print/internal/cli"
 	"github.com/peakflames/claude-print/internal/config"
@@ -28,15 +30,98 @@ func printUsage(ver string) {
 	fmt.Println("           This ensures flags like --permission-mode correctly receive their arguments.")
 	fmt.Println()
 	fmt.Println("PROXY FLAGS (consumed by claude-print):")
-	fmt.Println("    -v, --version      Print version and exit")
+	fmt.Println("    -v, --version      Print version and exit (add --json for build metadata as JSON)")
 	fmt.Println("    -h, --help         Show this help")
-	fmt.Println("        --verbose      Enable detailed output (also passed to Claude)")
+	fmt.Println("        --doctor       Check config and environment, then exit (alias: --validate-config)")
+	fmt.Println("        --print-config Print the effective config and each value's source as JSON, then exit")
+	fmt.Println("        --verbose      Enable detailed output")
 	fmt.Println("        --quiet        Enable minimal output (results only)")
+	fmt.Println("                       (add --json to write just {answer, turns, cost_usd, error} to stdout)")
 	fmt.Println("        --no-color     Disable colored output")
 	fmt.Println("        --no-emoji     Disable emoji in output")
 	fmt.Println("        --stream-json  Write structured JSON events to stdout; display goes to stderr")
+	fmt.Println("        --stream-json-out")
+	fmt.Println("                       Write every parsed event to stdout as an enveloped JSON line")
+	fmt.Println("        --raw-events   Write only parsed events to stdout as JSON lines; no display at all")
+	fmt.Println("        --blocks-json  At session end, write its text and tool blocks in order to stdout as JSON")
+	fmt.Println("        --json-prefix <p>")
+	fmt.Println("                       Prefix for --stream-json-out envelope field names")
+	fmt.Println("        --file-summary List files read and written/edited at session end")
+	fmt.Println("        --no-tool-output")
+	fmt.Println("                       Hide tool results (errors still shown); tool calls remain visible")
+	fmt.Println("        --no-thinking  Don't show the \"thinking...\" placeholder while Claude is silent")
+	fmt.Println("        --show-metadata")
+	fmt.Println("                       Show a one-line session summary (model, tools, MCP servers)")
+	fmt.Println("        --strip-ansi   Remove ANSI escape sequences from display output")
+	fmt.Println("        --wrap         Break long streamed lines at the terminal width")
+	fmt.Println("        --render-tables")
+	fmt.Println("                       Draw markdown tables in answers as aligned, bordered tables (color terminals)")
+	fmt.Println("        --git-context  Show the working directory's git branch and commit in the banner")
+	fmt.Println("        --hide-prompt  Show \"[prompt hidden]\" instead of the prompt in the banner and debug log header")
+	fmt.Println("        --relative-time")
+	fmt.Println("                       Show the time since the session started on each tool result, e.g. (+4.2s)")
+	fmt.Println("        --raw-bash-output")
+	fmt.Println("                       With --verbose, show Bash results in full with their colors (other escapes removed)")
+	fmt.Println("        --raw-bash-output-unsafe")
+	fmt.Println("                       With --raw-bash-output, keep every escape sequence, including cursor movement")
+	fmt.Println("        --no-trailing-newline")
+	fmt.Println("                       Don't end the display with a blank line (always off with -q)")
+	fmt.Println("        --labels       Prefix output with speaker labels (Assistant:, Tool (Bash):)")
+	fmt.Println("        --repl         Keep reading follow-up prompts from stdin after each turn")
 	fmt.Println("        --config       Path to config file (default: ~/.claude-print-config.json)")
 	fmt.Println("        --debug-log    Log raw JSON stream to directory")
+	fmt.Println("        --debug-dir <dir>")
+	fmt.Println("                       Collect the debug log, transcript, usage stats, cast and audit log in a new session directory under dir")
+	fmt.Println("        --env KEY=VALUE")
+	fmt.Println("                       Set an environment variable for Claude (repeatable)")
+	fmt.Println("        --proxy <url>  Route Claude through this HTTP(S) proxy (sets HTTPS_PROXY and HTTP_PROXY)")
+	fmt.Println("        --debug-log-filter <types>")
+	fmt.Println("                       Only log these comma-separated event types (e.g. result,assistant)")
+	fmt.Println("        --run-spec <file>")
+	fmt.Println("                       Load prompt and settings from a JSON run spec (flags override it)")
+	fmt.Println("        --template <name>")
+	fmt.Println("                       Use the named prompt template from the config file as the prompt")
+	fmt.Println("        --var NAME=VALUE")
+	fmt.Println("                       Fill the template's {NAME} placeholder (repeatable)")
+	fmt.Println("        --model-fallback <model>")
+	fmt.Println("                       Retry once with this model if the requested model is overloaded")
+	fmt.Println("        --record <path>")
+	fmt.Println("                       Record display output with timing as an asciinema v2 .cast file")
+	fmt.Println("        --batch <file> Run each prompt in file (one per line, # comments) as its own session")
+	fmt.Println("        --batch-report <path>")
+	fmt.Println("                       Write the batch cost report as JSON, or as CSV for a .csv path")
+	fmt.Println("        --junit <path> Write a JUnit XML report: one test case per session, failed on a non-zero exit")
+	fmt.Println("        --follow <file>")
+	fmt.Println("                       Render a --debug-log file live as it grows (like tail -f); Ctrl+C stops")
+	fmt.Println("        --strip-to-answer <file>")
+	fmt.Println("                       Print only the assistant text of a --debug-log or --raw-events file (- for stdin)")
+	fmt.Println("        --watch <glob> Re-run the prompt, continuing the session, when matching files change")
+	fmt.Println("                       (repeatable); Ctrl+C exits")
+	fmt.Println("        --audit-log <path>")
+	fmt.Println("                       Append one JSON line per tool call (tool, key parameters, outcome)")
+	fmt.Println("        --loop-guard <n>")
+	fmt.Println("                       Abort (exit 3) if the same tool call repeats more than n times in a row")
+	fmt.Println("        --auto-resume <n>")
+	fmt.Println("                       If Claude dies before finishing, resume the session with --resume (up to n times)")
+	fmt.Println("        --confirm-cost <usd>")
+	fmt.Println("                       On a terminal, ask before continuing each time the estimated cost")
+	fmt.Println("                       passes another multiple of usd; declining aborts (exit 4)")
+	fmt.Println("        --fail-threshold <ratio>")
+	fmt.Println("                       Exit 5 if a completed session's failed/total tool calls exceed ratio (0-1)")
+	fmt.Println("        --success-codes <list>")
+	fmt.Println("                       Exit 0 instead of these comma-separated codes; errors are still shown")
+	fmt.Println("        --on-error <command>")
+	fmt.Println("                       Run command when a session fails; details in CLAUDE_PRINT_* env vars")
+	fmt.Println("        --on-error-when <list>")
+	fmt.Println("                       Failures that trigger --on-error: exit, result, tool-errors (default: exit,result)")
+	fmt.Println("        --on-edit <command>")
+	fmt.Println("                       Run command on each file Claude writes or edits ({file} is its path)")
+	fmt.Println("        --max-tool-param-bytes <n>")
+	fmt.Println("                       Truncate tool parameter values above n bytes (default: 65536)")
+	fmt.Println("        --blocks-spill-bytes <n>")
+	fmt.Println("                       With --blocks-json, keep blocks in a temp file once they exceed n bytes")
+	fmt.Println("        --pipe-to <command>")
+	fmt.Println("                       Run command after completion with the final answer on its stdin")
 	fmt.Println()
 	fmt.Println("All other flags are passed through to Claude CLI unchanged.")
 	fmt.Println()
@@ -45,6 +130,18 @@ func printUsage(ver string) {
 	fmt.Println("    claude-print --verbose \"Explain this code\"")
 	fmt.Println("    claude-print --quiet \"Generate a haiku\"")
 	fmt.Println()
+	fmt.Println("    # Interactive follow-ups in one session (Ctrl+C or EOF to exit):")
+	fmt.Println("    claude-print --repl \"Review main.go\"")
+	fmt.Println()
+	fmt.Println("    # Re-review whenever a Go file changes (Ctrl+C to exit):")
+	fmt.Println("    claude-print --watch \"*.go\" \"Review the latest changes\"")
+	fmt.Println()
+	fmt.Println("    # Fill in a prompt template from the config file:")
+	fmt.Println("    claude-print --template review --var file=x.go --var concern=security")
+	fmt.Println()
+	fmt.Println("    # Copy the final answer to the clipboard:")
+	fmt.Println("    claude-print --pipe-to pbcopy \"Write a commit message\"")
+	fmt.Println()
 	fmt.Println("    # Read prompt from stdin:")
 	fmt.Println("    echo \"What is 2+2?\" | claude-print")
 	fmt.Println("    cat prompt.txt | claude-print --quiet")
@@ -57,9 +154,13 @@ func printUsage(ver string) {
 	fmt.Println("    claude-print \"Design a feature\" --permission-mode plan")
 	fmt.Println("    claude-print \"Fix the bug\" --allowedTools \"Read,Edit,Bash\"")
 	fmt.Println("    claude-print \"Refactor everything\" --dangerously-skip-permissions")
-	fmt.Println("    claude-print --continue")
+	fmt.Println("    claude-print --continue                  # resume the last session, no new input")
+	fmt.Println("    claude-print \"Now add tests\" --continue  # append a prompt to the last session")
 	fmt.Println("    claude-print \"Quick task\" --max-turns 5")
 	fmt.Println()
+	fmt.Println("    # Everything after -- is the prompt, even words that look like flags:")
+	fmt.Println("    claude-print --verbose -- explain the -p flag")
+	fmt.Println()
 	fmt.Println("PROTECTED FLAGS (cannot be used - required by claude-print):")
 	fmt.Println("    -p, --print                  Prompt is passed as positional argument")
 	fmt.Println("    --output-format              Must be stream-json")
@@ -71,16 +172,64 @@ func printUsage(ver string) {
 	fmt.Println("    Available settings:")
 	fmt.Println("      claudePath        Path to Claude CLI executable (auto-detected)")
 	fmt.Println("      defaultVerbosity  Default output level: normal, verbose, quiet")
+	fmt.Println("      defaultModel      Model used when --model is not passed (e.g. sonnet)")
+	fmt.Println("      defaultMaxTurns   --max-turns used when not passed (default: 0, unbounded)")
 	fmt.Println("      colorEnabled      Enable colored output (default: true)")
 	fmt.Println("      emojiEnabled      Enable emoji in output (default: true)")
+	fmt.Println("      emojiSet          Glyph per role: " + strings.Join(config.EmojiRoles, ", "))
+	fmt.Println("      streamFlushMS     Coalesce streamed text, flushing every N ms (default: 0, off)")
+	fmt.Println("      quietSpinner      Show a spinner on stderr while --quiet waits (default: false)")
+	fmt.Println("      thinkingDelayMS   Idle time before the \"thinking...\" placeholder (default: 1000, <0 off)")
+	fmt.Println("      onEdit            Command run on each written or edited file, e.g. \"gofmt -w {file}\"")
+	fmt.Println("      env               Extra environment variables for Claude, e.g. {\"ANTHROPIC_BASE_URL\": \"...\"}")
+	fmt.Println("      showMetadata      Show a one-line session summary in normal mode (default: false)")
+	fmt.Println("      assistantLabel    Label before assistant text with --labels (default: Assistant:)")
+	fmt.Println("      toolLabel         Label before tool calls with --labels (default: Tool ({tool}):)")
+	fmt.Println("      verboseMatchLimit Grep/Glob matches listed in verbose mode (default: 20)")
+	fmt.Println("      indentWidth       Spaces per nesting level in parameters, details and statistics (default: 2)")
+	fmt.Println("      loopGuard         Abort after n identical consecutive tool calls (default: 0, off)")
+	fmt.Println("      confirmCostUSD    Ask before continuing past each multiple of this estimated cost (default: 0, off)")
+	fmt.Println("      dangerousPatterns Regexes for Bash commands to flag in red (replaces built-in list)")
+	fmt.Println("      maxToolParamBytes Cap on each tool parameter value kept/shown (default: 65536)")
+	fmt.Println("      blocksSpillBytes  --blocks-json memory cap before blocks go to a temp file (default: 0, off)")
+	fmt.Println("      templates         Named prompt templates for --template, e.g. {\"review\": \"Review {file}\"}")
+	fmt.Println("      summaryTemplate   Completion line format using {status} {turns} {cost}")
+	fmt.Println("                        {total_duration} {api_duration} {in} {out}")
+	fmt.Println("      summaryFields     Completion line fields in order, e.g. [\"cost\", \"cache\"]")
+	fmt.Println("      showCachedTokens  Show the cache-read share of input tokens in the summary (default: false)")
+	fmt.Println("      promptFlag        Non-interactive prompt flag for Claude-compatible CLIs (default: -p)")
+	fmt.Println("      requiredFlags     Streaming flags for Claude-compatible CLIs (default: Claude CLI's)")
+	fmt.Println("      warnCostUSD       Highlight summary cost above this amount (default: 0, off)")
+	fmt.Println("      warnDurationMS    Highlight summary duration above this many ms (default: 0, off)")
 	fmt.Println()
 	fmt.Println("ENVIRONMENT:")
 	fmt.Println("    NO_COLOR    Set to disable colored output")
+	fmt.Println("    NO_EMOJI    Set to disable emoji (--no-emoji > NO_EMOJI > config)")
 	fmt.Println()
 	fmt.Println("MORE INFO:")
 	fmt.Println("    https://github.com/peakflames/claude-print")
 }
 
+// exitNotExecutable is returned when the Claude CLI exists but cannot be
+// executed, matching the shell's convention for "found but not executable".
+const exitNotExecutable = 126
+
+// exitNoPrompt is returned when there is no prompt and stdout isn't a
+// terminal, following the convention of 2 for command-line usage errors.
+const exitNoPrompt = 2
+
+// exitToolLoop is returned when --loop-guard aborts a session stuck repeating
+// the same tool call.
+const exitToolLoop = 3
+
+// exitCostDeclined is returned when the user stops a run at a --confirm-cost
+// prompt.
+const exitCostDeclined = 4
+
+// exitToolErrors is returned when a session completed but more of its tool
+// calls failed than --fail-threshold allows.
+const exitToolErrors = 5
+
 func main() {
 	os.Exit(run())
 }
@@ -95,7 +244,11 @@ func run() int {
 
 	// Handle version flag immediately (before any other setup)
 	if flags.Version {
-		fmt.Printf("claude-print %s\n", version)
+		if flags.JSON {
+			printVersionJSON()
+		} else {
+			fmt.Printf("claude-print %s\n", version)
+		}
 		return 0
 	}
 
@@ -105,49 +258,224 @@ func run() int {
 		return 0
 	}
 
-	// Determine where display output goes: stderr when --stream-json, stdout otherwise.
+	// Run diagnostics instead of a session
+	if flags.Doctor {
+		return runDoctor(flags, os.Stdout)
+	}
+
+	// Print the effective config instead of running a session
+	if flags.PrintConfig {
+		return runPrintConfig(flags)
+	}
+
+	// Extract the answer from a recorded session instead of running one
+	if flags.StripToAnswer != "" {
+		return runStripToAnswer(flags.StripToAnswer)
+	}
+
+	// Determine where display output goes: stderr when a JSON mode owns stdout.
 	displayFile := os.Stdout
-	if flags.StreamJSON {
+	if flags.StreamJSON || flags.StreamJSONOut || flags.RawEvents || flags.BlocksJSON || (flags.Quiet && flags.JSON) {
 		displayFile = os.Stderr
 	}
 
-	// Ensure we always end with a newline on the display stream
-	defer fmt.Fprintln(displayFile)
+	// Make the console render UTF-8 (Windows); fall back to ASCII glyphs and
+	// no emoji if it can't
+	asciiOnly := false
+	if output.IsTTY(displayFile) {
+		restoreConsole, ok := output.EnableUTF8Console()
+		defer restoreConsole()
+		if !ok {
+			output.UseASCIIGlyphs()
+			asciiOnly = true
+		}
+	}
 
 	// Load config (returns default if file doesn't exist)
+	// A missing home directory is not fatal: run with defaults, don't save.
+	// Neither is a config others can write to: it is used, with a warning.
 	cfg, err := config.LoadConfig()
-	if err != nil {
+	noHomeDir := errors.Is(err, config.ErrNoHomeDir)
+	var insecureConfig error
+	if errors.Is(err, config.ErrInsecurePermissions) {
+		insecureConfig = err
+	} else if err != nil && !noHomeDir {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		return 1
 	}
 
+	// Apply env vars and flags over the config, as --print-config shows it;
+	// the loaded config is kept for saving
+	loadedCfg := cfg
+	cfg, _ = resolveConfig(cfg, flags, os.LookupEnv)
+
 	// Determine color and emoji settings
 	colorEnabled := output.ShouldEnableColor(flags.NoColor, cfg.ColorEnabled, displayFile)
-	emojiEnabled := cfg.EmojiEnabled && !flags.NoEmoji
+	emojiEnabled := output.ShouldEnableEmoji(flags.NoEmoji, cfg.EmojiEnabled) && !asciiOnly
 
-	// Create formatter directed at the display file
-	formatter := output.NewFormatter(colorEnabled, emojiEnabled, displayFile)
+	// Collect the run's debug artifacts in one directory; its path is
+	// printed once everything else has been written
+	var debugArtifacts *debugDir
+	if flags.DebugDir != "" {
+		dir, err := newDebugDir(flags.DebugDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating debug directory: %v\n", err)
+			return 1
+		}
+		debugArtifacts = dir
+		defer func() {
+			if err := dir.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "claude-print: writing debug artifacts: %v\n", err)
+			}
+			fmt.Fprintf(os.Stderr, "Debug artifacts: %s\n", dir.Path())
+		}()
+	}
 
-	// Determine verbosity level
-	verbosity := output.VerbosityNormal
-	if flags.Verbose {
-		verbosity = output.VerbosityVerbose
-	} else if flags.Quiet {
-		verbosity = output.VerbosityQuiet
-	} else if cfg.DefaultVerbosity == "verbose" {
-		verbosity = output.VerbosityVerbose
-	} else if cfg.DefaultVerbosity == "quiet" {
-		verbosity = output.VerbosityQuiet
+	// Strip ANSI sequences (ours and any in Claude's text) before the sink,
+	// optionally record the display output, with timing, as an asciinema
+	// cast, and optionally coalesce rapid writes to the display file
+	writerOpts := displayWriterOptions{
+		StripANSI:     flags.StripANSI,
+		CastWidth:     output.TerminalWidth(displayFile),
+		FlushInterval: time.Duration(cfg.StreamFlushMS) * time.Millisecond,
+	}
+	castPath := flags.Record
+	if castPath == "" && debugArtifacts != nil {
+		castPath = debugArtifacts.file(debugCastFile)
+	}
+	if castPath != "" {
+		castFile, err := os.Create(castPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating recording: %v\n", err)
+			return 1
+		}
+		writerOpts.Cast = castFile
+	}
+	displayWriter, closeDisplayWriter, err := newDisplayWriter(displayFile, writerOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating recording: %v\n", err)
+		return 1
+	}
+	defer closeDisplayWriter()
+
+	// Keep a copy of the display with the run's other debug artifacts
+	if debugArtifacts != nil {
+		displayWriter = output.NewTeeWriter(displayWriter, debugArtifacts.Transcript())
+	}
+
+	// Determine verbosity level (--verbose, --quiet and --raw-events are
+	// resolved into the config)
+	verbosity := displayVerbosity(cfg)
+
+	// Always end the display on a complete line; the trailing blank line
+	// is kept for interactive use but left out where it would pad captured
+	// output. Registered last so it runs before the writers above close.
+	lineEnd := output.NewLineEndWriter(displayWriter)
+	trailingBlankLine := !flags.NoTrailingNewline && verbosity != output.VerbosityQuiet
+	defer func() {
+		lineEnd.Finish(trailingBlankLine)
+	}()
+
+	// Create formatter directed at the display writer, which the event
+	// loop, the thinking indicator and job control all write to
+	displayOut := output.NewSyncWriter(lineEnd)
+	formatter := output.NewFormatter(colorEnabled, emojiEnabled, displayOut)
+	formatter.EmojiSet = cfg.EmojiSet
+
+	if noHomeDir {
+		formatter.Warning("Could not determine home directory; using default config")
+	}
+	if insecureConfig != nil {
+		formatter.Warning("%v", insecureConfig)
+	}
+	// Bash results are only shown in verbose mode, which the config may set
+	if flags.RawBashOutput && verbosity != output.VerbosityVerbose {
+		formatter.Warning("--raw-bash-output has no effect without verbose mode (--verbose or defaultVerbosity \"verbose\")")
 	}
 
 	display := output.NewDisplay(formatter, verbosity)
-	if flags.StreamJSON {
-		display.JSONWriter = os.Stdout
+
+	// Track terminal width for width-aware truncation, following resizes
+	if width := output.TerminalWidth(displayFile); width > 0 {
+		display.SetWidth(width)
+		stopWatching := output.WatchTerminalWidth(displayFile, display.SetWidth)
+		defer stopWatching()
+	}
+	// In quiet mode, optionally show liveness on stderr (TTY only) while waiting
+	if verbosity == output.VerbosityQuiet && cfg.QuietSpinner && output.IsStderrTTY() && !flags.RawEvents {
+		display.Spinner = output.NewSpinner(os.Stderr, 100*time.Millisecond)
+		defer display.Spinner.Stop()
+	}
+	applyDisplayFlags(display, flags)
+	// Box-drawn tables need a color terminal; elsewhere the markdown stays raw
+	display.RenderTables = flags.RenderTables && output.IsTTY(displayFile) && colorEnabled && !asciiOnly
+	display.ShowToolStatus = output.IsTTY(displayFile) && !flags.StripANSI
+	// Between events, show that Claude is still reasoning (normal mode, TTY only)
+	if display.ShowToolStatus && verbosity == output.VerbosityNormal && cfg.ThinkingDelayMS > 0 {
+		delay := time.Duration(cfg.ThinkingDelayMS) * time.Millisecond
+		display.Thinking = output.NewThinkingIndicator(formatter, delay)
+		defer display.Thinking.Disarm()
+	}
+	// Leave the terminal clean when suspended with Ctrl+Z (Unix only)
+	stopJobControl := output.HandleJobControl(displayFile, displayOut, display)
+	defer stopJobControl()
+	applyDisplayConfig(display, cfg)
+	if len(cfg.DangerousPatterns) > 0 {
+		patterns, err := output.CompileDangerousPatterns(cfg.DangerousPatterns)
+		if err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "Invalid config: %v", err)
+			return 1
+		}
+		display.DangerousPatterns = patterns
+	}
+	// Pause for a spending check, only when there is someone to answer
+	display.ConfirmCostUSD = cfg.ConfirmCostUSD
+	if display.ConfirmCostUSD > 0 && output.IsTTY(os.Stdin) {
+		display.ConfirmCost = confirmCost(formatter)
+	}
+	// Run the --on-edit command on each file Claude writes or edits (not for
+	// a replayed log, whose edits were made by another run)
+	if cfg.OnEdit != "" && flags.Follow == "" {
+		if cwd, err := os.Getwd(); err != nil {
+			formatter.Warning("--on-edit disabled: %v", err)
+		} else {
+			display.EditHooks = newEditHooks(cfg.OnEdit, cwd)
+			defer display.EditHooks.Close()
+		}
+	}
+	if debugArtifacts != nil {
+		display.BlocksSpillDir = debugArtifacts.Path()
+		display.Sessions = debugArtifacts.Sessions
 	}
 
 	if flags.StreamJSON {
 		display.JSONWriter = os.Stdout
 	}
+	if flags.BlocksJSON {
+		display.BlocksWriter = os.Stdout
+	}
+	if flags.StreamJSONOut {
+		display.EventWriter = os.Stdout
+		display.EventFieldPrefix = flags.JSONPrefix
+	}
+	auditPath := flags.AuditLog
+	if auditPath == "" && debugArtifacts != nil {
+		auditPath = debugArtifacts.file(debugAuditFile)
+	}
+	if auditPath != "" {
+		auditFile, err := os.OpenFile(auditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "Error opening audit log: %v", err)
+			return 1
+		}
+		defer auditFile.Close()
+		display.AuditLog = auditFile
+	}
+
+	// Render a debug log as it grows; Claude isn't run, so needs no path
+	if flags.Follow != "" {
+		return runFollow(flags.Follow, display, formatter)
+	}
 
 	// Auto-detect Claude path if not configured
 	claudePath := cfg.ClaudePath
@@ -159,27 +487,125 @@ func run() int {
 		}
 		claudePath = detectedPath
 
-		// Save detected path to config for future use
+		// Save detected path to config for future use (skipped without a
+		// home directory; detection simply runs again next time)
 		cfg.ClaudePath = claudePath
-		if saveErr := config.SaveConfig(cfg); saveErr != nil {
-			// Non-fatal: just warn if we can't save
-			formatter.Warning("Could not save config: %v", saveErr)
+		loadedCfg.ClaudePath = claudePath
+		if !noHomeDir {
+			if saveErr := config.SaveConfig(loadedCfg); saveErr != nil {
+				// Non-fatal: just warn if we can't save
+				formatter.Warning("Could not save config: %v", saveErr)
+			}
 		}
 	}
 
 	// Validate Claude path exists
 	if err := config.ValidatePath(claudePath); err != nil {
 		formatter.ErrorWithEmoji(output.EmojiError, "%v", err)
+		var notExec *config.NotExecutableError
+		if errors.As(err, &notExec) {
+			return exitNotExecutable
+		}
 		return 1
 	}
 
+	// Validate the configured default model
+	if err := config.ValidateModelName(loadedCfg.DefaultModel); err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "%v", err)
+		return 1
+	}
+
+	// Validate CLI dialect overrides
+	if err := cli.ValidateDialectFlags(cfg.PromptFlag, cfg.RequiredFlags); err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Invalid config: %v", err)
+		return 1
+	}
+
+	// Extra environment for Claude: config env, with --env and --proxy
+	// merged in
+	env, err := cli.EnvAssignments(cfg.Env, nil)
+	if err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Invalid config: %v", err)
+		return 1
+	}
+	// The effective proxy helps diagnose connectivity in locked-down networks
+	if verbosity == output.VerbosityVerbose {
+		if proxy := cli.EffectiveProxy(os.Environ(), env); proxy != "" {
+			display.AddStartDetail("Proxy", proxy)
+		}
+	}
+
+	// Show the turn limit and progress toward it when the run is bounded by
+	// --max-turns, or else by the config default (which buildArgs injects)
+	if loadedCfg.DefaultMaxTurns < 0 {
+		formatter.ErrorWithEmoji(output.EmojiError, "Invalid config: defaultMaxTurns must not be negative, got %d", loadedCfg.DefaultMaxTurns)
+		return 1
+	}
+	display.MaxTurns = cfg.DefaultMaxTurns
+	if display.MaxTurns > 0 {
+		display.AddStartDetail("Max turns", strconv.Itoa(display.MaxTurns))
+	}
+
+	// Show the effective model: an explicit --model wins over the config default
+	if cfg.DefaultModel != "" {
+		display.AddStartDetail("Model", cfg.DefaultModel)
+	}
+
+	// Show the permission mode, catching typos Claude would otherwise reject
+	// or silently ignore
+	if mode, ok := cli.FlagValue(flags.PassthroughArgs, "--permission-mode"); ok {
+		if err := cli.ValidatePermissionMode(mode); err != nil {
+			formatter.WarningWithEmoji(output.EmojiWarning, "--permission-mode: %v", err)
+		}
+		display.ShowPermissionMode(mode)
+	}
+
+	// Tie the run to the repo state; outside a repo there is nothing to show
+	if flags.GitContext {
+		if cwd, err := os.Getwd(); err == nil {
+			if info, err := detect.GitContext(cwd); err == nil {
+				display.GitBranch, display.GitCommit = info.Branch, info.Commit
+				display.AddStartDetail("Git", info.String())
+			}
+		}
+	}
+
+	// Build the prompt from a config template
+	if flags.Template != "" {
+		prompt, err := cli.TemplatePrompt(cfg.Templates, flags.Template, flags.Vars)
+		if err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "%v", err)
+			return 1
+		}
+		flags.Prompt = prompt
+	}
+
 	// Check if we have a prompt (not required for --continue or --resume)
 	hasSessionFlag := cli.ContainsSessionFlag(flags.PassthroughArgs)
-	if flags.Prompt == "" && !hasSessionFlag {
+	if flags.EmptyPrompt && hasSessionFlag {
+		formatter.Warning("Empty prompt given with --continue/--resume; resuming without new input")
+	}
+	if flags.Prompt == "" && !hasSessionFlag && !flags.REPL && flags.Batch == "" {
+		// A script capturing stdout gets a short error, not the help text,
+		// and not even the display's trailing blank line
+		if !output.IsStdoutTTY() {
+			fmt.Fprintln(os.Stderr, "Error: no prompt provided (pass it as an argument or on stdin; see --help)")
+			trailingBlankLine = false
+			return exitNoPrompt
+		}
 		printUsage(version)
 		return 0
 	}
 
+	// Name a streaming flag this Claude CLI lacks now, rather than leave
+	// the user with a cryptic startup failure. Help output that can't be
+	// read or lists no flags (e.g. a wrapper script) skips the check.
+	if missing, err := detect.MissingFlags(claudePath, runner.RequiredArgs(cfg.RequiredFlags)); err == nil && len(missing) > 0 {
+		formatter.ErrorWithEmoji(output.EmojiError, "The Claude CLI at %s does not support %s, which claude-print needs for streaming output. "+
+			"Update it (claude update), or set requiredFlags in the config for a Claude-compatible CLI", claudePath, strings.Join(missing, ", "))
+		return 1
+	}
+
 	// Pass prompt to display for rendering
 	if flags.Prompt != "" {
 		display.SetUserPrompt(flags.Prompt)
@@ -190,99 +616,208 @@ func run() int {
 		display.ShowStart()
 	}
 
+	// Build run options - simple pass-through architecture
+	opts := newRunOptions(cfg, flags, claudePath, env)
+
 	// Enable debug logging if requested
-	if flags.DebugLog != "" {
-		if err := runner.EnableDebugLogging(flags.DebugLog); err != nil {
-			formatter.Warning("Could not enable debug logging: %v", err)
-		} else {
-			defer runner.CloseDebugLogging()
+	if enabled, err := startDebugLog(flags, debugArtifacts, opts); err != nil {
+		formatter.Warning("Could not enable debug logging: %v", err)
+	} else if enabled {
+		defer runner.CloseDebugLogging()
+	}
+
+	if flags.RawEvents {
+		return runRawEvents(opts, display, formatter, flags)
+	}
+	if flags.Quiet && flags.JSON {
+		return runAnswerJSON(opts, display, formatter, flags)
+	}
+	if flags.Batch != "" {
+		prompts, err := cli.LoadBatchPrompts(flags.Batch)
+		if err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "%v", err)
+			return 1
 		}
+		return runBatch(prompts, displaySession(display, nil), opts, display, formatter, flags)
 	}
 
-	// Build run options - simple pass-through architecture
-	opts := runner.RunOptions{
-		ClaudePath:      claudePath,
-		Prompt:          flags.Prompt,
-		PassthroughArgs: flags.PassthroughArgs,
+	// Start animating once the start banner is out; the display stops it
+	// before writing quiet output
+	display.Spinner.Start()
+
+	if flags.REPL {
+		return runREPL(opts, display, formatter, flags)
+	}
+	if len(flags.Watch) > 0 {
+		return runWatch(opts, display, formatter, flags)
 	}
 
-	// Spawn Claude CLI process
-	process, err := runner.RunClaude(opts)
+	started := time.Now()
+	outcome, err := runWithResume(displaySession(display, nil), opts, formatter, flags)
 	if err != nil {
 		formatter.ErrorWithEmoji(output.EmojiError, "Failed to start Claude: %v", err)
-		return 1
+		runOnStartError(err, formatter, flags)
+		return reportJUnit([]output.JUnitCase{junitStartFailure(flags.Prompt, err, time.Since(started), flags)}, started, 1, formatter, flags)
 	}
 
-	// Set up signal handling for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	exitCode := sessionExitCode(outcome, formatter, flags)
 
-	// Channel to communicate when event streaming is done
-	doneChan := make(chan struct{})
-
-	// Stream events from the process
-	eventChan := runner.StreamEventsFromProcess(process)
+	runOnError(outcome, exitCode, formatter, flags)
+	return reportSession(outcome, exitCode, started, formatter, flags)
+}
 
-	// Handle events in real-time (in a goroutine to allow signal handling)
-	go func() {
-		for event := range eventChan {
-			display.HandleEvent(event)
+// reportSession writes the --junit report for a single session that started
+// at started, and feeds its final answer to the --pipe-to command. Returns
+// exitCode, or 1 when the report can't be written for a session that
+// otherwise succeeded; a failed --pipe-to command is only reported.
+func reportSession(outcome sessionOutcome, exitCode int, started time.Time, formatter *output.Formatter, flags cli.Flags) int {
+	cases := []output.JUnitCase{junitCase(flags.Prompt, outcome, exitCode, time.Since(started), flags)}
+	exitCode = reportJUnit(cases, started, exitCode, formatter, flags)
+
+	// Only a successful session's answer is piped on
+	if flags.PipeTo != "" && outcome.Signal == nil && outcome.Result != nil && !outcome.failed() {
+		if err := pipeAnswer(flags.PipeTo, outcome.Result.Result); err != nil {
+			formatter.WarningWithEmoji(output.EmojiWarning, "--pipe-to command failed: %v", err)
 		}
-		close(doneChan)
-	}()
+	}
+	return exitCode
+}
 
-	// Wait for either completion or signal
-	var receivedSignal os.Signal
-	select {
-	case <-doneChan:
-		// Normal completion - event streaming finished
-		signal.Stop(sigChan)
-	case sig := <-sigChan:
-		// Received interrupt signal
-		receivedSignal = sig
-		signal.Stop(sigChan)
-
-		// Send termination signal to child process
-		if sig == syscall.SIGINT {
-			_ = process.Interrupt()
-		} else {
-			_ = process.Terminate()
+// runWithFallback runs a session and, if the requested model was overloaded
+// and a fallback model is configured, retries it once with that model.
+func runWithFallback(run sessionRunner, opts runner.RunOptions, formatter *output.Formatter, fallback string) (sessionOutcome, error) {
+	outcome, err := run(opts)
+	if err != nil {
+		return outcome, err
+	}
+
+	// Retry once with the fallback model if the requested model was overloaded
+	if outcome.Signal == nil && fallback != "" && outcome.failed() && isOverloaded(outcome) {
+		formatter.WarningWithEmoji(output.EmojiWarning, "Model overloaded; retrying with fallback model %s", fallback)
+		opts.PassthroughArgs = cli.SetFlagValue(opts.PassthroughArgs, "--model", fallback)
+		outcome, err = run(opts)
+		if err != nil {
+			return outcome, err
 		}
+		formatter.InfoWithEmoji("", "Fallback model %s was used for this session", fallback)
+	}
 
-		// Wait for event channel to drain (child process cleanup)
-		<-doneChan
+	return outcome, nil
+}
+
+// resumePrompt is sent to a session relaunched by --auto-resume.
+const resumePrompt = "Continue from where you left off."
+
+// runWithResume runs a session like runWithFallback and, with --auto-resume,
+// relaunches it with --resume each time Claude dies before its result event
+// (see sessionOutcome.crashed) once a session ID is known, up to
+// flags.AutoResume times. Each relaunch is reported.
+func runWithResume(run sessionRunner, opts runner.RunOptions, formatter *output.Formatter, flags cli.Flags) (sessionOutcome, error) {
+	outcome, err := runWithFallback(run, opts, formatter, flags.ModelFallback)
+	sessionID := outcome.SessionID
+	for attempt := 1; err == nil && attempt <= flags.AutoResume && outcome.crashed() && sessionID != ""; attempt++ {
+		formatter.WarningWithEmoji(output.EmojiWarning, "Claude stopped before finishing; resuming session %s (attempt %d of %d)",
+			sessionID, attempt, flags.AutoResume)
+		opts.PassthroughArgs = cli.ContinuationArgs(opts.PassthroughArgs, sessionID)
+		opts.Prompt = resumePrompt
+		outcome, err = runWithFallback(run, opts, formatter, flags.ModelFallback)
+		if outcome.SessionID != "" {
+			sessionID = outcome.SessionID
+		}
 	}
+	return outcome, err
+}
 
-	// Wait for process to complete
-	_ = process.Wait()
+// sessionExitCode displays any error for a finished session and returns the
+// exit code claude-print should report for it. Codes listed with
+// --success-codes are reported as 0; the error is still shown. An
+// interrupted session keeps its signal exit code (130 or 143), so a Ctrl+C
+// is never reported as success.
+func sessionExitCode(outcome sessionOutcome, formatter *output.Formatter, flags cli.Flags) int {
+	code := claudeExitCode(outcome, formatter, flags.FailThreshold)
+	if outcome.Signal != nil {
+		return code
+	}
+	if mapped := cli.MapSuccessCode(code, flags.SuccessCodes); mapped != code {
+		formatter.InfoWithEmoji("", "Exit code %d treated as success (--success-codes)", code)
+		code = mapped
+	}
+	return code
+}
 
+// claudeExitCode displays any error for a finished session and returns its
+// exit code. With failThreshold set, a session that otherwise succeeded
+// exits with exitToolErrors when its ratio of failed tool calls exceeds it.
+func claudeExitCode(outcome sessionOutcome, formatter *output.Formatter, failThreshold *float64) int {
 	// If we received a signal, return appropriate exit code
-	if receivedSignal != nil {
-		// 128 + signal number is the conventional exit code for signal termination
-		// SIGINT = 2, so exit code = 130
-		// SIGTERM = 15, so exit code = 143
-		switch receivedSignal {
-		case syscall.SIGINT:
-			return 130
-		case syscall.SIGTERM:
-			return 143
-		default:
-			return 128
+	if outcome.Signal != nil {
+		return outcome.signalExitCode()
+	}
+
+	// An aborted tool loop takes precedence over the interrupted exit status
+	if outcome.ToolLoop != "" {
+		formatter.ErrorWithEmoji(output.EmojiError, "Aborted: detected tool loop (%s)", outcome.ToolLoop)
+		return exitToolLoop
+	}
+	if outcome.CostDeclined {
+		formatter.ErrorWithEmoji(output.EmojiError, "Aborted: stopped at an estimated cost of $%.2f (--confirm-cost)", outcome.CostUSD)
+		return exitCostDeclined
+	}
+
+	// Without an exit status there is no exit code error to explain
+	if outcome.Reaped {
+		if outcome.Result != nil {
+			formatter.WarningWithEmoji(output.EmojiWarning, "Claude's exit status is unavailable (another process reaped it); using the session result")
+		} else {
+			formatter.ErrorWithEmoji(output.EmojiError, "Claude's exit status is unavailable (another process reaped it) and no result was received")
 		}
 	}
 
-	// Check for process error
-	exitCode := process.ExitCode()
-	if exitCode != 0 {
-		stderr := process.Stderr()
+	// Check for process error. A clean exit with an error result (e.g. max
+	// turns reached) still maps to the exit code implied by its subtype.
+	exitCode := outcome.ExitCode
+	if exitCode == 0 && outcome.Result != nil {
+		exitCode = output.ResultExitCode(*outcome.Result)
+	}
 
-		// Detect and display error
-		errCtx := output.DetectExitCodeError(exitCode, stderr)
+	// A broken output stream is a failure even if Claude exited cleanly
+	if outcome.StreamErr != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Reading Claude's output failed: %v", outcome.StreamErr)
+		if exitCode == 0 {
+			exitCode = 1
+		}
+	}
+	if outcome.ExitCode != 0 && !outcome.Reaped {
+		// Detect and display error; a CLI that failed before its first
+		// event (e.g. on a bad flag) is best explained by its stderr
+		errCtx := output.DetectExitCodeError(exitCode, outcome.Stderr)
+		if !outcome.Started {
+			errCtx = output.DetectStartupError(exitCode, outcome.Stderr)
+		}
 		if errCtx != nil {
 			output.DisplayError(formatter, errCtx)
 		}
 	}
 
+	// Grade a completed session by how many of its tool calls failed
+	if exitCode == 0 && failThreshold != nil && outcome.Result != nil && outcome.Result.TotalToolUse > 0 {
+		ratio := float64(outcome.Result.TotalToolErrors) / float64(outcome.Result.TotalToolUse)
+		if ratio > *failThreshold {
+			formatter.ErrorWithEmoji(output.EmojiError, "Tool errors: %d of %d calls failed (%.0f%%), above --fail-threshold %g",
+				outcome.Result.TotalToolErrors, outcome.Result.TotalToolUse, ratio*100, *failThreshold)
+			return exitToolErrors
+		}
+	}
+
 	// Return Claude CLI exit code
 	return exitCode
 }
+
+// isOverloaded reports whether a failed session was caused by the model
+// being overloaded, based on the result text and captured stderr.
+func isOverloaded(o sessionOutcome) bool {
+	if output.IsOverloadedError(o.Stderr) {
+		return true
+	}
+	return o.Result != nil && output.IsOverloadedError(o.Result.Result)
+}
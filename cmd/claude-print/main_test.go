@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// scriptedSessions returns a sessionRunner that returns outcomes in order,
+// and the options each session was run with.
+func scriptedSessions(outcomes ...sessionOutcome) (sessionRunner, *[]runner.RunOptions) {
+	var runs []runner.RunOptions
+	return func(opts runner.RunOptions) (sessionOutcome, error) {
+		runs = append(runs, opts)
+		return outcomes[len(runs)-1], nil
+	}, &runs
+}
+
+// crashedOutcome is a session that died after starting, before its result.
+func crashedOutcome(sessionID string) sessionOutcome {
+	return sessionOutcome{ExitCode: 1, Started: true, SessionID: sessionID}
+}
+
+// resultOutcome is a session that ended with a result event.
+func resultOutcome(sessionID string, isError bool, text string) sessionOutcome {
+	result := &events.ResultEvent{Subtype: "success", IsError: isError, Result: text, SessionID: sessionID}
+	exitCode := 0
+	if isError {
+		exitCode = 1
+	}
+	return sessionOutcome{ExitCode: exitCode, Started: true, Result: result, SessionID: sessionID}
+}
+
+func TestRunWithResume(t *testing.T) {
+	formatter := output.NewFormatter(false, false, &bytes.Buffer{})
+	opts := runner.RunOptions{Prompt: "fix the tests"}
+
+	t.Run("resumes a crashed session by ID", func(t *testing.T) {
+		run, runs := scriptedSessions(crashedOutcome("s1"), crashedOutcome(""), resultOutcome("s1", false, "done"))
+		outcome, err := runWithResume(run, opts, formatter, cli.Flags{AutoResume: 2})
+		if err != nil || outcome.Result == nil {
+			t.Fatalf("runWithResume() = %+v, %v; want the resumed session's result", outcome, err)
+		}
+		if len(*runs) != 3 {
+			t.Fatalf("ran %d sessions, want 3", len(*runs))
+		}
+		for _, resumed := range (*runs)[1:] {
+			if resumed.Prompt != resumePrompt || !slices.Equal(resumed.PassthroughArgs, []string{"--resume", "s1"}) {
+				t.Errorf("resumed with %q %q, want %q --resume s1", resumed.Prompt, resumed.PassthroughArgs, resumePrompt)
+			}
+		}
+	})
+
+	t.Run("gives up after the limit", func(t *testing.T) {
+		run, runs := scriptedSessions(crashedOutcome("s1"), crashedOutcome("s1"))
+		outcome, _ := runWithResume(run, opts, formatter, cli.Flags{AutoResume: 1})
+		if len(*runs) != 2 || !outcome.crashed() {
+			t.Errorf("ran %d sessions ending crashed=%v, want 2 ending crashed", len(*runs), outcome.crashed())
+		}
+	})
+
+	tests := []struct {
+		name    string
+		outcome sessionOutcome
+		flags   cli.Flags
+	}{
+		{"disabled", crashedOutcome("s1"), cli.Flags{}},
+		{"no session ID", crashedOutcome(""), cli.Flags{AutoResume: 2}},
+		{"interrupted", sessionOutcome{Started: true, SessionID: "s1", Signal: syscall.SIGINT}, cli.Flags{AutoResume: 2}},
+		{"error result", resultOutcome("s1", true, "max turns"), cli.Flags{AutoResume: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, runs := scriptedSessions(tt.outcome)
+			if _, err := runWithResume(run, opts, formatter, tt.flags); err != nil || len(*runs) != 1 {
+				t.Errorf("ran %d sessions (err %v), want no resume", len(*runs), err)
+			}
+		})
+	}
+}
+
+func TestRunWithFallback(t *testing.T) {
+	var buf bytes.Buffer
+	formatter := output.NewFormatter(false, false, &buf)
+	opts := runner.RunOptions{Prompt: "hi", PassthroughArgs: []string{"--model", "opus"}}
+	overloaded := resultOutcome("s1", true, `API Error: 529 {"type":"error","error":{"type":"overloaded_error"}}`)
+
+	run, runs := scriptedSessions(overloaded, resultOutcome("s2", false, "hello"))
+	outcome, err := runWithFallback(run, opts, formatter, "sonnet")
+	if err != nil || outcome.failed() {
+		t.Fatalf("runWithFallback() = %+v, %v; want the fallback session's success", outcome, err)
+	}
+	if len(*runs) != 2 || !slices.Equal((*runs)[1].PassthroughArgs, []string{"--model", "sonnet"}) {
+		t.Errorf("sessions ran with %v, want a retry with --model sonnet", *runs)
+	}
+	if !strings.Contains(buf.String(), "Fallback model sonnet was used") {
+		t.Errorf("expected the fallback to be reported, got:\n%s", buf.String())
+	}
+
+	// Without a fallback model, or for other failures, there is no retry
+	for _, tt := range []struct {
+		outcome  sessionOutcome
+		fallback string
+	}{
+		{overloaded, ""},
+		{resultOutcome("s1", true, "max turns reached"), "sonnet"},
+	} {
+		run, runs := scriptedSessions(tt.outcome)
+		if _, err := runWithFallback(run, opts, formatter, tt.fallback); err != nil || len(*runs) != 1 {
+			t.Errorf("ran %d sessions for %q with fallback %q, want 1", len(*runs), tt.outcome.Result.Result, tt.fallback)
+		}
+	}
+}
+
+func TestSessionExitCode_SuccessCodes(t *testing.T) {
+	flags := cli.Flags{SuccessCodes: []int{1, 130, 143}}
+	tests := []struct {
+		name    string
+		outcome sessionOutcome
+		want    int
+	}{
+		{"listed code", resultOutcome("s1", true, "max turns reached"), 0},
+		{"unlisted code", sessionOutcome{ExitCode: 2, Started: true}, 2},
+		{"interrupted", sessionOutcome{ExitCode: 1, Signal: syscall.SIGINT}, 130},
+		{"terminated", sessionOutcome{ExitCode: 1, Signal: syscall.SIGTERM}, 143},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		got := sessionExitCode(tt.outcome, output.NewFormatter(false, false, &buf), flags)
+		if got != tt.want {
+			t.Errorf("%s: sessionExitCode() = %d, want %d", tt.name, got, tt.want)
+		}
+		if remapped := strings.Contains(buf.String(), "treated as success"); remapped != (tt.want == 0) {
+			t.Errorf("%s: remapping reported %v, want %v:\n%s", tt.name, remapped, tt.want == 0, buf.String())
+		}
+	}
+}
+
+func TestIsOverloaded(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome sessionOutcome
+		want    bool
+	}{
+		{"result text", resultOutcome("s1", true, "API Error: 529 overloaded_error"), true},
+		{"stderr", sessionOutcome{ExitCode: 1, Stderr: "API Error: 529\n"}, true},
+		{"answer mentioning overload", resultOutcome("s1", true, "The queue is overloaded; add workers."), false},
+		{"no result", sessionOutcome{ExitCode: 1}, false},
+	}
+	for _, tt := range tests {
+		if got := isOverloaded(tt.outcome); got != tt.want {
+			t.Errorf("%s: isOverloaded() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fakeClaude writes a stand-in for the Claude CLI that prints lines as its
+// stream-json output, and returns its path.
+func fakeClaude(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claude")
+	script := "#!/bin/sh\ncat <<'EOF'\n" + strings.Join(lines, "\n") + "\nEOF\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// redirectFile points *f at a new file in dir for the rest of the test, and
+// returns the file's path.
+func redirectFile(t *testing.T, f **os.File, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := *f
+	*f = file
+	t.Cleanup(func() {
+		*f = orig
+		file.Close()
+	})
+	return path
+}
+
+func TestRun_NoPromptWithoutTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script as the Claude CLI")
+	}
+	home := t.TempDir()
+	claude := filepath.Join(home, "claude")
+	if err := os.WriteFile(claude, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".claude-print-config.json"), []byte(`{"claudePath":"`+claude+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	orig := os.Args
+	t.Cleanup(func() { os.Args = orig })
+	os.Args = []string{"claude-print"}
+
+	// Empty stdin, and stdout and stderr captured to files (not terminals)
+	redirectFile(t, &os.Stdin, home, "stdin")
+	stdout := redirectFile(t, &os.Stdout, home, "stdout")
+	stderr := redirectFile(t, &os.Stderr, home, "stderr")
+
+	if code := run(); code != exitNoPrompt {
+		t.Errorf("run() = %d, want %d", code, exitNoPrompt)
+	}
+	if out, _ := os.ReadFile(stdout); len(out) != 0 {
+		t.Errorf("expected nothing on stdout, not even a blank line, got %q", out)
+	}
+	if out, _ := os.ReadFile(stderr); !strings.Contains(string(out), "Error: no prompt provided") {
+		t.Errorf("expected the short error on stderr, got %q", out)
+	}
+}
+
+func TestReportSession_PipeTo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	formatter := output.NewFormatter(false, false, &bytes.Buffer{})
+	tests := []struct {
+		name    string
+		outcome sessionOutcome
+		want    string
+	}{
+		{"success", resultOutcome("s1", false, "the answer"), "the answer"},
+		{"error result", resultOutcome("s1", true, "max turns reached"), ""},
+		{"interrupted", sessionOutcome{Signal: syscall.SIGINT, Result: resultOutcome("s1", false, "partial").Result}, ""},
+	}
+	for _, tt := range tests {
+		out := filepath.Join(t.TempDir(), "piped")
+		reportSession(tt.outcome, 0, time.Now(), formatter, cli.Flags{PipeTo: "cat > " + out})
+		got, _ := os.ReadFile(out)
+		if string(got) != tt.want {
+			t.Errorf("%s: piped %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
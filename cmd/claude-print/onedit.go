@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// newEditHooks returns hooks that run the --on-edit command for each file
+// Claude writes or edits. Files outside root, the working directory Claude
+// runs in, are skipped and reported as failures, so a session can't aim the
+// command at arbitrary paths.
+func newEditHooks(command, root string) *output.EditHooks {
+	return output.NewEditHooks(func(path string) error {
+		if !insideDir(root, path) {
+			return fmt.Errorf("skipped: outside the working directory %s", root)
+		}
+		return runner.RunOnEditHook(command, path)
+	})
+}
+
+// insideDir reports whether path is dir or beneath it, after resolving
+// symlinks. A relative path is taken from dir.
+func insideDir(dir, path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
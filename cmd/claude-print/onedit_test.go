@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+func TestInsideDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "work")
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"the directory itself", dir, true},
+		{"absolute path beneath", filepath.Join(dir, "sub", "a.go"), true},
+		{"relative path", filepath.Join("sub", "a.go"), true},
+		{"relative path out", filepath.Join("..", "a.go"), false},
+		{"parent", root, false},
+		{"sibling sharing the prefix", dir + "2", false},
+		{"dotted name beneath", filepath.Join(dir, "..a.go"), true},
+	}
+	for _, tt := range tests {
+		if got := insideDir(dir, tt.path); got != tt.want {
+			t.Errorf("%s: insideDir(%q) = %v, want %v", tt.name, tt.path, got, tt.want)
+		}
+	}
+
+	// A symlink beneath the directory is judged by where it points (the
+	// hook runs once the file is written, so it exists)
+	if runtime.GOOS != "windows" {
+		link := filepath.Join(dir, "escape")
+		if err := os.Symlink(root, link); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "a.go"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if insideDir(dir, filepath.Join(link, "a.go")) {
+			t.Error("expected a symlink out of the directory to be outside it")
+		}
+	}
+}
+
+func TestNewEditHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(t.TempDir(), "log")
+	hooks := newEditHooks("echo {file} >> "+log, dir)
+	defer hooks.Close()
+
+	var buf bytes.Buffer
+	display := output.NewDisplay(output.NewFormatter(false, false, &buf), output.VerbosityNormal)
+	display.EditHooks = hooks
+	inside := filepath.Join(dir, "main.go")
+	for id, path := range map[string]string{"w1": inside, "w2": "/etc/passwd"} {
+		use := events.AssistantEvent{}
+		use.Type = "assistant"
+		use.Message.Content = []events.ContentBlock{{Type: "tool_use", ID: id, Name: "Write", Input: map[string]interface{}{"file_path": path}}}
+		display.HandleEvent(use)
+		result := events.UserEvent{}
+		result.Type = "user"
+		result.Message.Content = []events.ContentBlock{{Type: "tool_result", ToolUseID: id, ContentString: "File created"}}
+		display.HandleEvent(result)
+	}
+	end := events.ResultEvent{Subtype: "success"}
+	end.Type = "result"
+	display.HandleEvent(end)
+
+	// The command runs for the file inside the directory only
+	if got, _ := os.ReadFile(log); string(got) != inside+"\n" {
+		t.Errorf("command ran for %q, want only %s", got, inside)
+	}
+	if !strings.Contains(buf.String(), "/etc/passwd: skipped: outside the working directory "+dir) {
+		t.Errorf("expected the outside file reported as skipped, got:\n%s", buf.String())
+	}
+}
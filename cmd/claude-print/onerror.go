@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runOnError runs the --on-error command, if one is set, when the session
+// meets one of the configured failure conditions. exitCode is the code
+// claude-print reports for the session (see sessionExitCode). A failing hook
+// is reported but doesn't change the exit code.
+func runOnError(outcome sessionOutcome, exitCode int, formatter *output.Formatter, flags cli.Flags) {
+	condition := onErrorCondition(exitCode, outcome.Result, flags)
+	if condition == "" {
+		return
+	}
+	env := onErrorEnv(condition, exitCode, failureMessage(outcome, condition, exitCode), outcome.SessionID)
+	runOnErrorHook(env, formatter, flags)
+}
+
+// runOnStartError runs the --on-error command, if one is set, when Claude
+// couldn't be started, which claude-print reports with exit code 1. err is
+// the command's CLAUDE_PRINT_ERROR; there is no session ID.
+func runOnStartError(err error, formatter *output.Formatter, flags cli.Flags) {
+	condition := onErrorCondition(1, nil, flags)
+	if condition == "" {
+		return
+	}
+	runOnErrorHook(onErrorEnv(condition, 1, err.Error(), ""), formatter, flags)
+}
+
+// onErrorCondition returns the --on-error-when condition a session with the
+// given exit code and result meets, or "" if it met none or no --on-error
+// command is set.
+func onErrorCondition(exitCode int, result *events.ResultEvent, flags cli.Flags) string {
+	if flags.OnError == "" {
+		return ""
+	}
+	conditions := flags.OnErrorWhen
+	if conditions == nil {
+		conditions = cli.DefaultOnErrorWhen
+	}
+	return failureCondition(conditions, exitCode, result, flags.FailThreshold)
+}
+
+// runOnErrorHook runs the --on-error command with env, reporting a failure
+// as a warning.
+func runOnErrorHook(env []string, formatter *output.Formatter, flags cli.Flags) {
+	if err := runner.RunOnErrorHook(flags.OnError, env); err != nil {
+		formatter.WarningWithEmoji(output.EmojiWarning, "--on-error command failed: %v", err)
+	}
+}
+
+// failureCondition returns the first of conditions (see cli.OnErrorConditions)
+// that a finished session meets, or "" if it met none. exitCode is the code
+// claude-print reports; result may be nil. With failThreshold nil, any
+// failed tool call meets cli.OnErrorToolErrors.
+func failureCondition(conditions []string, exitCode int, result *events.ResultEvent, failThreshold *float64) string {
+	for _, condition := range conditions {
+		switch condition {
+		case cli.OnErrorExit:
+			if exitCode != 0 {
+				return condition
+			}
+		case cli.OnErrorResult:
+			if result != nil && result.IsError {
+				return condition
+			}
+		case cli.OnErrorToolErrors:
+			if result == nil || result.TotalToolErrors == 0 || result.TotalToolUse == 0 {
+				continue
+			}
+			threshold := 0.0
+			if failThreshold != nil {
+				threshold = *failThreshold
+			}
+			if float64(result.TotalToolErrors)/float64(result.TotalToolUse) > threshold {
+				return condition
+			}
+		}
+	}
+	return ""
+}
+
+// onErrorEnv returns the variables describing a failure to the --on-error
+// command.
+func onErrorEnv(condition string, exitCode int, message, sessionID string) []string {
+	return []string{
+		"CLAUDE_PRINT_FAILURE=" + condition,
+		"CLAUDE_PRINT_EXIT_CODE=" + strconv.Itoa(exitCode),
+		"CLAUDE_PRINT_ERROR=" + message,
+		"CLAUDE_PRINT_SESSION_ID=" + sessionID,
+	}
+}
+
+// failureMessage describes why a session failed, for the --on-error command.
+func failureMessage(outcome sessionOutcome, condition string, exitCode int) string {
+	result := outcome.Result
+	switch {
+	case condition == cli.OnErrorToolErrors:
+		return fmt.Sprintf("%d of %d tool calls failed", result.TotalToolErrors, result.TotalToolUse)
+	case outcome.Signal != nil:
+		return "interrupted by " + outcome.Signal.String()
+	case outcome.ToolLoop != "":
+		return "detected tool loop (" + outcome.ToolLoop + ")"
+	case outcome.CostDeclined:
+		return fmt.Sprintf("stopped at an estimated cost of $%.2f", outcome.CostUSD)
+	case result != nil && result.IsError && result.Result != "":
+		return result.Result
+	case result != nil && result.IsError:
+		return output.ResultLabel(*result)
+	case outcome.StreamErr != nil:
+		return outcome.StreamErr.Error()
+	}
+	if lines := strings.Split(strings.TrimSpace(outcome.Stderr), "\n"); lines[len(lines)-1] != "" {
+		return lines[len(lines)-1]
+	}
+	return fmt.Sprintf("exit code %d", exitCode)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+)
+
+func TestFailureCondition(t *testing.T) {
+	half := 0.5
+	ok := &events.ResultEvent{Subtype: "success", TotalToolUse: 4, TotalToolErrors: 1}
+	failed := &events.ResultEvent{Subtype: "error_max_turns", IsError: true}
+	all := cli.OnErrorConditions
+
+	tests := []struct {
+		name       string
+		conditions []string
+		exitCode   int
+		result     *events.ResultEvent
+		threshold  *float64
+		want       string
+	}{
+		{"clean success", cli.DefaultOnErrorWhen, 0, &events.ResultEvent{Subtype: "success"}, nil, ""},
+		{"non-zero exit", cli.DefaultOnErrorWhen, 1, nil, nil, cli.OnErrorExit},
+		{"error result", cli.DefaultOnErrorWhen, 1, failed, nil, cli.OnErrorExit},
+		{"error result remapped to 0", cli.DefaultOnErrorWhen, 0, failed, nil, cli.OnErrorResult},
+		{"exit not selected", []string{cli.OnErrorResult}, 2, nil, nil, ""},
+		{"tool errors not selected", cli.DefaultOnErrorWhen, 0, ok, nil, ""},
+		{"any tool error", all, 0, ok, nil, cli.OnErrorToolErrors},
+		{"tool errors under threshold", all, 0, ok, &half, ""},
+		{"first condition wins", []string{cli.OnErrorResult, cli.OnErrorExit}, 1, failed, nil, cli.OnErrorResult},
+	}
+	for _, tt := range tests {
+		if got := failureCondition(tt.conditions, tt.exitCode, tt.result, tt.threshold); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOnErrorEnv(t *testing.T) {
+	got := onErrorEnv(cli.OnErrorExit, 143, "interrupted by terminated", "s1")
+	want := []string{
+		"CLAUDE_PRINT_FAILURE=exit",
+		"CLAUDE_PRINT_EXIT_CODE=143",
+		"CLAUDE_PRINT_ERROR=interrupted by terminated",
+		"CLAUDE_PRINT_SESSION_ID=s1",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFailureMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		outcome   sessionOutcome
+		condition string
+		want      string
+	}{
+		{"tool errors", resultOutcome("s1", false, "done"), cli.OnErrorToolErrors, "0 of 0 tool calls failed"},
+		{"signal", sessionOutcome{Signal: syscall.SIGTERM}, cli.OnErrorExit, "interrupted by terminated"},
+		{"error result", resultOutcome("s1", true, "rate limited"), cli.OnErrorResult, "rate limited"},
+		{"last stderr line", sessionOutcome{ExitCode: 1, Stderr: "starting\nbad flag\n"}, cli.OnErrorExit, "bad flag"},
+		{"exit code only", sessionOutcome{ExitCode: 1}, cli.OnErrorExit, "exit code 1"},
+	}
+	for _, tt := range tests {
+		if got := failureMessage(tt.outcome, tt.condition, 1); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunOnError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	command := `printf '%s|%s|%s' "$CLAUDE_PRINT_FAILURE" "$CLAUDE_PRINT_EXIT_CODE" "$CLAUDE_PRINT_SESSION_ID" > ` + out
+	formatter := output.NewFormatter(false, false, &bytes.Buffer{})
+
+	// A successful session doesn't run the command
+	runOnError(resultOutcome("s1", false, "done"), 0, formatter, cli.Flags{OnError: command})
+	if _, err := os.Stat(out); err == nil {
+		t.Fatal("expected no hook run for a successful session")
+	}
+
+	// The reported exit code, not Claude's, is what the command sees
+	runOnError(resultOutcome("s1", true, "failed"), 3, formatter, cli.Flags{OnError: command})
+	if got, _ := os.ReadFile(out); string(got) != "exit|3|s1" {
+		t.Errorf("command saw %q, want exit|3|s1", got)
+	}
+
+	// A failing command is reported as a warning
+	var buf bytes.Buffer
+	runOnError(crashedOutcome("s1"), 1, output.NewFormatter(false, false, &buf), cli.Flags{OnError: "exit 4"})
+	if !strings.Contains(buf.String(), "--on-error command failed") {
+		t.Errorf("expected a warning for the failing command, got %q", buf.String())
+	}
+}
+
+func TestRunOnStartError(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	command := `printf '%s|%s|%s|%s' "$CLAUDE_PRINT_FAILURE" "$CLAUDE_PRINT_EXIT_CODE" "$CLAUDE_PRINT_ERROR" "$CLAUDE_PRINT_SESSION_ID" > ` + out
+	formatter := output.NewFormatter(false, false, &bytes.Buffer{})
+
+	// Only the exit condition can be met without a session
+	runOnStartError(errors.New("exec: claude: not found"), formatter, cli.Flags{OnError: command, OnErrorWhen: []string{cli.OnErrorResult}})
+	if _, err := os.Stat(out); err == nil {
+		t.Fatal("expected no hook run without the exit condition")
+	}
+
+	runOnStartError(errors.New("exec: claude: not found"), formatter, cli.Flags{OnError: command})
+	if got, _ := os.ReadFile(out); string(got) != "exit|1|exec: claude: not found|" {
+		t.Errorf("command saw %q, want exit|1|exec: claude: not found|", got)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// pipeAnswer runs command through the platform shell and writes answer to its
+// stdin, waiting for the command to exit. The command's own output goes to
+// claude-print's stdout and stderr.
+func pipeAnswer(command, answer string) error {
+	cmd := runner.ShellCommand(context.Background(), command)
+	cmd.Stdin = strings.NewReader(answer)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
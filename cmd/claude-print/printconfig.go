@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/detect"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// Sources reported by --print-config for each effective config value.
+const (
+	sourceDefault    = "default"
+	sourceConfigFile = "config file"
+	sourceUnset      = "unset in config file"
+)
+
+// effectiveConfig is the document printed by --print-config.
+type effectiveConfig struct {
+	ConfigFile string            `json:"configFile"`
+	Config     config.Config     `json:"config"`
+	Sources    map[string]string `json:"sources"`
+	ClaudeArgs []string          `json:"claudeArgs"` // Arguments Claude is run with, without the prompt
+}
+
+// describeConfig resolves cfg, the config loaded from path, as a run would
+// (see resolveConfig) and records where each value came from, keyed by its
+// JSON name. fileKeys are the keys present in the config file (nil when
+// there is no file).
+func describeConfig(cfg config.Config, path string, fileKeys map[string]json.RawMessage, flags cli.Flags, lookupEnv func(string) (string, bool)) effectiveConfig {
+	sources := make(map[string]string)
+	configType := reflect.TypeOf(cfg)
+	for i := 0; i < configType.NumField(); i++ {
+		key, _, _ := strings.Cut(configType.Field(i).Tag.Get("json"), ",")
+		switch _, inFile := fileKeys[key]; {
+		case fileKeys == nil:
+			sources[key] = sourceDefault
+		case inFile:
+			sources[key] = sourceConfigFile
+		default:
+			sources[key] = sourceUnset
+		}
+	}
+
+	resolved, overrides := resolveConfig(cfg, flags, lookupEnv)
+	for key, source := range overrides {
+		// env entries from flags are merged with the file's, not replacing them
+		if key == "env" && sources[key] == sourceConfigFile {
+			source = sourceConfigFile + " + " + source
+		}
+		sources[key] = source
+	}
+	if resolved.ClaudePath == "" {
+		if detected, err := detect.DetectClaudePath(); err == nil {
+			resolved.ClaudePath, sources["claudePath"] = detected, "auto-detected"
+		}
+	}
+
+	flags.Prompt = ""
+	claudeArgs := runner.ClaudeArgs(newRunOptions(resolved, flags, resolved.ClaudePath, nil))
+	return effectiveConfig{ConfigFile: path, Config: resolved, Sources: sources, ClaudeArgs: claudeArgs}
+}
+
+// runPrintConfig prints the effective config for --print-config as indented
+// JSON on stdout. Values are printed as-is, without redaction.
+func runPrintConfig(flags cli.Flags) int {
+	cfg, err := config.LoadConfig()
+	if errors.Is(err, config.ErrInsecurePermissions) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	} else if err != nil && !errors.Is(err, config.ErrNoHomeDir) {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	path, _ := config.FilePath()
+	var fileKeys map[string]json.RawMessage
+	if data, err := os.ReadFile(path); err == nil {
+		fileKeys = map[string]json.RawMessage{}
+		_ = json.Unmarshal(data, &fileKeys)
+	}
+
+	data, err := json.MarshalIndent(describeConfig(cfg, path, fileKeys, flags, os.LookupEnv), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Println(string(data))
+	return 0
+}
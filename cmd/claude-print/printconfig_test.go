@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+)
+
+func TestDescribeConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ClaudePath, cfg.DefaultModel = "/usr/bin/claude", "sonnet"
+	cfg.Env = map[string]string{"A": "1"}
+	fileKeys := map[string]json.RawMessage{"claudePath": nil, "defaultModel": nil, "env": nil}
+	flags := cli.Flags{
+		Prompt:          "hello",
+		Env:             []string{"B=2"},
+		PassthroughArgs: []string{"--permission-mode", "plan"},
+	}
+
+	got := describeConfig(cfg, "/home/me/.claude-print-config.json", fileKeys, flags, noEnv)
+	for key, want := range map[string]string{
+		"claudePath":   sourceConfigFile,
+		"defaultModel": sourceConfigFile,
+		"env":          sourceConfigFile + " + flag --env",
+		"indentWidth":  sourceUnset,
+		"loopGuard":    sourceUnset,
+	} {
+		if got.Sources[key] != want {
+			t.Errorf("Sources[%q] = %q, want %q", key, got.Sources[key], want)
+		}
+	}
+	if got.Config.Env["B"] != "2" || got.Config.IndentWidth != 2 {
+		t.Errorf("expected the resolved config, got %+v", got.Config)
+	}
+
+	// Claude's arguments include passthrough flags and the injected default
+	// model, but not the prompt flag
+	if !slices.Contains(got.ClaudeArgs, "plan") || !slices.Contains(got.ClaudeArgs, "sonnet") || slices.Contains(got.ClaudeArgs, "-p") {
+		t.Errorf("unexpected ClaudeArgs: %v", got.ClaudeArgs)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runRawEvents runs a session for --raw-events: the display is bypassed and
+// each parsed event is written to stdout as one JSON line the moment it
+// arrives. os.Stdout is unbuffered, so every line reaches the consumer with
+// a single write. The session goes through the headless display, so the
+// loop guard, cost check, audit log and edit hooks apply, and is retried
+// like any other session (see runWithResume); the events of every attempt
+// are written. Diagnostics go to formatter, which writes to stderr. The exit
+// code is computed from flags as in sessionExitCode.
+func runRawEvents(opts runner.RunOptions, display *output.Display, formatter *output.Formatter, flags cli.Flags) int {
+	encoder := json.NewEncoder(os.Stdout)
+	writeFailed := false
+	outcome, err := runWithResume(displaySession(display, func(event events.Event) {
+		if err := encoder.Encode(event); err != nil && !writeFailed {
+			// Keep draining Claude's output; report the broken stdout once
+			writeFailed = true
+			fmt.Fprintf(os.Stderr, "claude-print: writing %q event: %v\n", event.EventType(), err)
+		}
+	}), opts, formatter, flags)
+	if err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Failed to start Claude: %v", err)
+		runOnStartError(err, formatter, flags)
+		return 1
+	}
+
+	exitCode := sessionExitCode(outcome, formatter, flags)
+	if exitCode == 0 && writeFailed {
+		exitCode = 1
+	}
+	runOnError(outcome, exitCode, formatter, flags)
+	return exitCode
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runREPL runs the initial prompt (if any), then keeps reading follow-up
+// prompts from stdin, one per line, continuing the same session each turn.
+// EOF or Ctrl+C at the prompt exits cleanly; Ctrl+C during a turn interrupts
+// Claude and ends the REPL with the conventional signal exit code.
+func runREPL(opts runner.RunOptions, display *output.Display, formatter *output.Formatter, flags cli.Flags) int {
+	lines := stdinLines()
+	exitCode := 0
+
+	for {
+		if opts.Prompt != "" || cli.ContainsSessionFlag(opts.PassthroughArgs) {
+			outcome, err := runWithResume(displaySession(display, nil), opts, formatter, flags)
+			if err != nil {
+				formatter.ErrorWithEmoji(output.EmojiError, "Failed to start Claude: %v", err)
+				runOnStartError(err, formatter, flags)
+				return 1
+			}
+			exitCode = sessionExitCode(outcome, formatter, flags)
+			runOnError(outcome, exitCode, formatter, flags)
+			if outcome.Signal != nil || outcome.CostDeclined {
+				return exitCode
+			}
+
+			// Subsequent turns continue this session
+			opts.PassthroughArgs = cli.ContinuationArgs(opts.PassthroughArgs, outcome.SessionID)
+		}
+
+		prompt, ok := readPrompt(lines, formatter)
+		if !ok {
+			return exitCode
+		}
+		opts.Prompt = prompt
+	}
+}
+
+// stdinLines returns the shared line reader for stdin, started on first use,
+// so REPL prompts and --confirm-cost answers don't race for input.
+var stdinLines = sync.OnceValue(func() <-chan string { return readLines(os.Stdin) })
+
+// readLines scans r line by line on a background goroutine so prompt reads
+// can be abandoned when a signal arrives.
+func readLines(r *os.File) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return lines
+}
+
+// readPrompt shows the input marker and waits for the next non-empty line.
+// Returns false on EOF or when interrupted by SIGINT/SIGTERM.
+func readPrompt(lines <-chan string, formatter *output.Formatter) (string, bool) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	for {
+		formatter.Plain("")
+		formatter.PlainNoNewline("%s", output.UserPrefix)
+		formatter.Flush()
+
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				formatter.Plain("")
+				return "", false
+			}
+			if prompt := strings.TrimSpace(line); prompt != "" {
+				formatter.Plain("")
+				return prompt, true
+			}
+		case <-sigChan:
+			formatter.Plain("")
+			return "", false
+		}
+	}
+}
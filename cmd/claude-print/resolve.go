@@ -0,0 +1,138 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// resolveConfig layers environment variables and flags (including those a
+// --run-spec filled in) over cfg, the loaded config, and fills in the
+// built-in value of each setting whose zero value means "use the default".
+// It returns the config a run uses and, keyed by JSON name, where each
+// overridden value came from. run and --print-config both resolve the
+// config here, so --print-config shows what a run would use.
+func resolveConfig(cfg config.Config, flags cli.Flags, lookupEnv func(string) (string, bool)) (config.Config, map[string]string) {
+	sources := make(map[string]string)
+	fromFlag := func(name string) string {
+		if slices.Contains(flags.FromRunSpec, name) {
+			return "run spec " + flags.RunSpec
+		}
+		return "flag " + name
+	}
+
+	if _, ok := lookupEnv("NO_COLOR"); ok {
+		cfg.ColorEnabled, sources["colorEnabled"] = false, "env NO_COLOR"
+	}
+	if flags.NoColor {
+		cfg.ColorEnabled, sources["colorEnabled"] = false, fromFlag("--no-color")
+	}
+	if _, ok := lookupEnv("NO_EMOJI"); ok {
+		cfg.EmojiEnabled, sources["emojiEnabled"] = false, "env NO_EMOJI"
+	}
+	if flags.NoEmoji {
+		cfg.EmojiEnabled, sources["emojiEnabled"] = false, fromFlag("--no-emoji")
+	}
+
+	// --raw-events shows nothing but diagnostics
+	switch {
+	case flags.RawEvents:
+		cfg.DefaultVerbosity, sources["defaultVerbosity"] = "quiet", fromFlag("--raw-events")
+	case flags.Verbose:
+		cfg.DefaultVerbosity, sources["defaultVerbosity"] = "verbose", fromFlag("--verbose")
+	case flags.Quiet:
+		cfg.DefaultVerbosity, sources["defaultVerbosity"] = "quiet", fromFlag("--quiet")
+	}
+
+	// Claude's own flags replace the defaults claude-print would inject
+	if model, ok := cli.FlagValue(flags.PassthroughArgs, "--model"); ok {
+		cfg.DefaultModel, sources["defaultModel"] = model, fromFlag("--model")
+	}
+	if value, ok := cli.FlagValue(flags.PassthroughArgs, "--max-turns"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			n = 0 // Left for Claude to reject
+		}
+		cfg.DefaultMaxTurns, sources["defaultMaxTurns"] = n, fromFlag("--max-turns")
+	}
+
+	if flags.ShowMetadata {
+		cfg.ShowMetadata, sources["showMetadata"] = true, fromFlag("--show-metadata")
+	}
+	if flags.NoThinking {
+		cfg.ThinkingDelayMS, sources["thinkingDelayMS"] = -1, fromFlag("--no-thinking")
+	}
+	if flags.LoopGuard > 0 {
+		cfg.LoopGuard, sources["loopGuard"] = flags.LoopGuard, fromFlag("--loop-guard")
+	}
+	if flags.MaxToolParamBytes > 0 {
+		cfg.MaxToolParamBytes, sources["maxToolParamBytes"] = flags.MaxToolParamBytes, fromFlag("--max-tool-param-bytes")
+	}
+	if flags.BlocksSpillBytes > 0 {
+		cfg.BlocksSpillBytes, sources["blocksSpillBytes"] = flags.BlocksSpillBytes, fromFlag("--blocks-spill-bytes")
+	}
+	if flags.ConfirmCostUSD > 0 {
+		cfg.ConfirmCostUSD, sources["confirmCostUSD"] = flags.ConfirmCostUSD, fromFlag("--confirm-cost")
+	}
+	if flags.OnEdit != "" {
+		cfg.OnEdit, sources["onEdit"] = flags.OnEdit, fromFlag("--on-edit")
+	}
+
+	// --env and --proxy entries are merged over the config's env
+	extra := flags.Env
+	var envSources []string
+	if len(flags.Env) > 0 {
+		envSources = append(envSources, fromFlag("--env"))
+	}
+	if flags.Proxy != "" {
+		extra = append(slices.Clip(extra), cli.ProxyAssignments(flags.Proxy)...)
+		envSources = append(envSources, fromFlag("--proxy"))
+	}
+	if len(extra) > 0 {
+		env := make(map[string]string, len(cfg.Env)+len(extra))
+		for k, v := range cfg.Env {
+			env[k] = v
+		}
+		for _, kv := range extra {
+			k, v, _ := strings.Cut(kv, "=")
+			env[k] = v
+		}
+		cfg.Env, sources["env"] = env, strings.Join(envSources, " + ")
+	}
+
+	// Zero means the built-in default for these
+	if cfg.IndentWidth == 0 {
+		cfg.IndentWidth = output.DefaultIndentWidth
+	}
+	if cfg.MaxToolParamBytes == 0 {
+		cfg.MaxToolParamBytes = output.DefaultMaxToolParamBytes
+	}
+	if cfg.VerboseMatchLimit == 0 {
+		cfg.VerboseMatchLimit = output.DefaultMatchLimit
+	}
+	if cfg.ThinkingDelayMS == 0 {
+		cfg.ThinkingDelayMS = int(output.DefaultThinkingDelay.Milliseconds())
+	}
+
+	return cfg, sources
+}
+
+// newRunOptions returns the options Claude is run with for the resolved
+// config and flags.
+func newRunOptions(cfg config.Config, flags cli.Flags, claudePath string, env []string) runner.RunOptions {
+	return runner.RunOptions{
+		ClaudePath:      claudePath,
+		Prompt:          flags.Prompt,
+		PassthroughArgs: flags.PassthroughArgs,
+		Model:           cfg.DefaultModel,
+		MaxTurns:        cfg.DefaultMaxTurns,
+		PromptFlag:      cfg.PromptFlag,
+		RequiredArgs:    cfg.RequiredFlags,
+		Env:             env,
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// noEnv is a lookupEnv with no variables set.
+func noEnv(string) (string, bool) { return "", false }
+
+// parseArgs parses a claude-print command line, as run does.
+func parseArgs(t *testing.T, args ...string) cli.Flags {
+	t.Helper()
+	orig := os.Args
+	t.Cleanup(func() { os.Args = orig })
+	os.Args = append([]string{"claude-print"}, args...)
+	flags, err := cli.ParseFlags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+func TestResolveConfig_Flags(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DefaultModel, cfg.DefaultMaxTurns, cfg.LoopGuard = "sonnet", 10, 3
+	cfg.Env = map[string]string{"A": "config", "B": "config"}
+	flags := cli.Flags{
+		Verbose:         true,
+		NoThinking:      true,
+		LoopGuard:       5,
+		OnEdit:          "gofmt -w {file}",
+		Env:             []string{"B=flag"},
+		Proxy:           "http://proxy:8080",
+		PassthroughArgs: []string{"--model", "opus", "--max-turns", "4"},
+	}
+
+	got, sources := resolveConfig(cfg, flags, noEnv)
+	if got.DefaultVerbosity != "verbose" || got.DefaultModel != "opus" || got.DefaultMaxTurns != 4 ||
+		got.LoopGuard != 5 || got.OnEdit != "gofmt -w {file}" || got.ThinkingDelayMS != -1 {
+		t.Errorf("flags not applied: %+v", got)
+	}
+	wantEnv := map[string]string{"A": "config", "B": "flag", "HTTPS_PROXY": "http://proxy:8080", "HTTP_PROXY": "http://proxy:8080"}
+	if !maps.Equal(got.Env, wantEnv) {
+		t.Errorf("Env = %v, want %v", got.Env, wantEnv)
+	}
+	if cfg.Env["B"] != "config" {
+		t.Error("resolveConfig modified the loaded config's env")
+	}
+	wantSources := map[string]string{
+		"defaultVerbosity": "flag --verbose",
+		"defaultModel":     "flag --model",
+		"defaultMaxTurns":  "flag --max-turns",
+		"loopGuard":        "flag --loop-guard",
+		"onEdit":           "flag --on-edit",
+		"thinkingDelayMS":  "flag --no-thinking",
+		"env":              "flag --env + flag --proxy",
+	}
+	if !maps.Equal(sources, wantSources) {
+		t.Errorf("sources = %v, want %v", sources, wantSources)
+	}
+}
+
+func TestResolveConfig_EnvAndRunSpec(t *testing.T) {
+	lookupEnv := func(name string) (string, bool) { return "", name == "NO_COLOR" }
+	flags := cli.Flags{
+		Quiet:           true,
+		NoEmoji:         true,
+		RunSpec:         "spec.json",
+		FromRunSpec:     []string{"--quiet", "--model"},
+		PassthroughArgs: []string{"--model", "haiku"},
+	}
+
+	got, sources := resolveConfig(config.DefaultConfig(), flags, lookupEnv)
+	if got.ColorEnabled || got.EmojiEnabled || got.DefaultVerbosity != "quiet" || got.DefaultModel != "haiku" {
+		t.Errorf("overrides not applied: %+v", got)
+	}
+	wantSources := map[string]string{
+		"colorEnabled":     "env NO_COLOR",
+		"emojiEnabled":     "flag --no-emoji",
+		"defaultVerbosity": "run spec spec.json",
+		"defaultModel":     "run spec spec.json",
+	}
+	if !maps.Equal(sources, wantSources) {
+		t.Errorf("sources = %v, want %v", sources, wantSources)
+	}
+}
+
+func TestResolveConfig_Defaults(t *testing.T) {
+	got, _ := resolveConfig(config.DefaultConfig(), cli.Flags{}, noEnv)
+	if got.IndentWidth != output.DefaultIndentWidth || got.MaxToolParamBytes != output.DefaultMaxToolParamBytes ||
+		got.VerboseMatchLimit != output.DefaultMatchLimit || got.ThinkingDelayMS != 1000 {
+		t.Errorf("expected built-in defaults filled in, got %+v", got)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.IndentWidth, cfg.ThinkingDelayMS = 4, -1
+	if got, _ := resolveConfig(cfg, cli.Flags{}, noEnv); got.IndentWidth != 4 || got.ThinkingDelayMS != -1 {
+		t.Errorf("expected configured values kept, got indentWidth %d, thinkingDelayMS %d", got.IndentWidth, got.ThinkingDelayMS)
+	}
+
+	// --raw-events shows nothing but diagnostics, whatever else is asked for
+	if got, _ := resolveConfig(cfg, cli.Flags{RawEvents: true, Verbose: true}, noEnv); got.DefaultVerbosity != "quiet" {
+		t.Errorf("DefaultVerbosity = %q with --raw-events, want quiet", got.DefaultVerbosity)
+	}
+}
+
+func TestNewRunOptions_RunSpec(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.json")
+	data := `{"prompt": "from the spec", "model": "haiku", "maxTurns": 3, "verbosity": "verbose"}`
+	if err := os.WriteFile(spec, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The spec fills in the run; a flag on the command line overrides it
+	flags := parseArgs(t, "--run-spec", spec, "--model", "opus")
+	cfg, sources := resolveConfig(config.DefaultConfig(), flags, noEnv)
+	if cfg.DefaultVerbosity != "verbose" || sources["defaultVerbosity"] != "run spec "+spec {
+		t.Errorf("defaultVerbosity %q from %q, want verbose from the run spec", cfg.DefaultVerbosity, sources["defaultVerbosity"])
+	}
+	opts := newRunOptions(cfg, flags, "claude", nil)
+	args := strings.Join(runner.ClaudeArgs(opts), " ")
+	if opts.Prompt != "from the spec" || !strings.Contains(args, "--model opus") || strings.Contains(args, "haiku") ||
+		!strings.Contains(args, "--max-turns 3") {
+		t.Errorf("ran %q with %q, want the spec's prompt and max turns with the flag's model", opts.Prompt, args)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// sessionOutcome captures how a single Claude CLI run ended.
+type sessionOutcome struct {
+	ExitCode int                 // Claude CLI exit code (-1 if unknown)
+	Signal   os.Signal           // Signal that interrupted the run, if any
+	Stderr   string              // Captured stderr from the Claude CLI
+	Result   *events.ResultEvent // Final result event, if one was received
+	Started  bool                // At least one event was received from Claude
+	ToolLoop string              // Detected tool loop that aborted the run, if any
+	// CostDeclined is set when the user stopped the run at a --confirm-cost
+	// prompt; CostUSD is the estimated cost at that point.
+	CostDeclined bool
+	CostUSD      float64
+	// StreamErr is the error that cut reading Claude's output short, if the
+	// stream didn't end with a clean EOF.
+	StreamErr error
+	// Reaped is set when Claude's exit status was lost to another process
+	// reaping it (see runner.ClaudeProcess.Reaped). ExitCode is then taken
+	// from the result event: 0 if one arrived, otherwise 1.
+	Reaped bool
+	// SessionID is the session's ID from its init or result event, if one
+	// arrived.
+	SessionID string
+}
+
+// signalExitCode returns the conventional exit code for a signal-terminated run.
+func (o sessionOutcome) signalExitCode() int {
+	// 128 + signal number is the conventional exit code for signal termination
+	// SIGINT = 2, so exit code = 130
+	// SIGTERM = 15, so exit code = 143
+	switch o.Signal {
+	case syscall.SIGINT:
+		return 130
+	case syscall.SIGTERM:
+		return 143
+	default:
+		return 128
+	}
+}
+
+// crashed reports whether Claude stopped before its result event without
+// being interrupted or stopped by claude-print (a crash or an outside kill).
+func (o sessionOutcome) crashed() bool {
+	return o.Started && o.Result == nil && o.Signal == nil && o.ToolLoop == "" && !o.CostDeclined
+}
+
+// failed reports whether the run ended in error, either via a non-zero exit
+// code or an error result event.
+func (o sessionOutcome) failed() bool {
+	return o.ExitCode != 0 || (o.Result != nil && o.Result.IsError)
+}
+
+// sessionRunner runs one Claude session with opts, like runSession.
+type sessionRunner func(opts runner.RunOptions) (sessionOutcome, error)
+
+// displaySession returns a sessionRunner that runs sessions through display
+// (see runSession).
+func displaySession(display *output.Display, observe func(events.Event)) sessionRunner {
+	return func(opts runner.RunOptions) (sessionOutcome, error) {
+		return runSession(opts, display, observe)
+	}
+}
+
+// runSession spawns the Claude CLI with opts, streams its events through
+// display, and waits for the process to exit. observe, if non-nil, is
+// passed each event after display. SIGINT/SIGTERM received while running
+// are forwarded to the child and recorded in the outcome.
+func runSession(opts runner.RunOptions, display *output.Display, observe func(events.Event)) (sessionOutcome, error) {
+	display.ResetToolLoop()
+	outcome, err := streamSession(opts, func(event events.Event, outcome *sessionOutcome) bool {
+		handleEventSafely(display, event)
+		if observe != nil {
+			observe(event)
+		}
+
+		// Interrupt Claude once when the loop guard trips
+		if loop, ok := display.ToolLoop(); ok && outcome.ToolLoop == "" {
+			outcome.ToolLoop = loop
+			return true
+		}
+
+		// Interrupt Claude once when the user declines to keep spending
+		if display.CostDeclined() && !outcome.CostDeclined {
+			outcome.CostDeclined = true
+			outcome.CostUSD = display.EstimatedCostUSD()
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return outcome, err
+	}
+	display.RecordSession(opts.Prompt, outcome.ExitCode, outcome.Result)
+	display.ClearToolStatus()
+	display.FinishEditHooks()
+	display.FlushAuditLog()
+	display.FinishBlocks(outcome.SessionID)
+	return outcome, nil
+}
+
+// streamSession spawns the Claude CLI with opts, passes each event to handle,
+// and waits for the process to exit. Claude is interrupted whenever handle
+// returns true. SIGINT/SIGTERM received while running are forwarded to the
+// child and recorded in the outcome.
+func streamSession(opts runner.RunOptions, handle func(event events.Event, outcome *sessionOutcome) bool) (sessionOutcome, error) {
+	// Spawn Claude CLI process
+	process, err := runner.RunClaude(opts)
+	if err != nil {
+		return sessionOutcome{}, err
+	}
+
+	// Set up signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// Channel to communicate when event streaming is done
+	doneChan := make(chan struct{})
+
+	// Stream events from the process
+	eventChan, streamErrChan := runner.StreamEventsFromProcess(process)
+
+	// Handle events in real-time (in a goroutine to allow signal handling)
+	var outcome sessionOutcome
+	go func() {
+		for event := range eventChan {
+			outcome.Started = true
+			switch e := event.(type) {
+			case events.SystemEvent:
+				if e.Kind() == "init" && e.SessionID != "" {
+					outcome.SessionID = e.SessionID
+				}
+			case events.ResultEvent:
+				outcome.Result = &e
+				if e.SessionID != "" {
+					outcome.SessionID = e.SessionID
+				}
+			}
+			if handle(event, &outcome) {
+				_ = process.Interrupt()
+			}
+		}
+		close(doneChan)
+	}()
+
+	// Wait for either completion or signal
+	select {
+	case <-doneChan:
+		// Normal completion - event streaming finished
+		signal.Stop(sigChan)
+	case sig := <-sigChan:
+		// Received interrupt signal
+		outcome.Signal = sig
+		signal.Stop(sigChan)
+
+		// Send termination signal to child process
+		if sig == syscall.SIGINT {
+			_ = process.Interrupt()
+		} else {
+			_ = process.Terminate()
+		}
+
+		// Wait for event channel to drain (child process cleanup)
+		<-doneChan
+	}
+
+	// A read error leaves Claude's output undrained, so stop it rather than
+	// wait on a process that may be blocked writing to the pipe
+	if outcome.StreamErr = <-streamErrChan; outcome.StreamErr != nil {
+		_ = process.Terminate()
+	}
+
+	// Wait for process to complete
+	_ = process.Wait()
+
+	outcome.ExitCode = process.ExitCode()
+	outcome.Stderr = process.Stderr()
+	// Without an exit status, the result event is the best evidence of how
+	// the run went; its error subtypes still map to their exit codes
+	if process.Reaped() {
+		outcome.Reaped = true
+		outcome.ExitCode = 1
+		if outcome.Result != nil {
+			outcome.ExitCode = 0
+		}
+	}
+	return outcome, nil
+}
+
+// handleEventSafely passes event to display, recovering from a panic so one
+// malformed event cannot abort the run. On panic the offending event type and
+// the most recent raw event lines are written to stderr.
+func handleEventSafely(display *output.Display, event events.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "\nclaude-print: recovered from panic handling %q event: %v\n", event.EventType(), r)
+			fmt.Fprintln(os.Stderr, "Recent events (oldest first):")
+			for _, line := range runner.RecentEvents() {
+				fmt.Fprintf(os.Stderr, "  %s\n", truncateLine(line, 500))
+			}
+		}
+	}()
+	display.HandleEvent(event)
+}
+
+// truncateLine shortens line to at most max bytes for diagnostics output,
+// cutting on a UTF-8 boundary.
+func truncateLine(line string, max int) string {
+	if len(line) <= max {
+		return line
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "..."
+}
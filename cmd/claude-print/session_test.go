@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		line string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 3, "abc..."},
+		{"aéb", 2, "a..."}, // é is two bytes; it isn't split
+		{"日本語", 4, "日..."},
+	}
+	for _, tt := range tests {
+		got := truncateLine(tt.line, tt.max)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("truncateLine(%q, %d) = %q, want %q", tt.line, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestRunSession_BashFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script as the Claude CLI")
+	}
+	claude := fakeClaude(t,
+		`{"type":"system","subtype":"init","session_id":"s1"}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"make test"}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"b1","content":"make: *** [test] Error 2"}]},`+
+			`"tool_use_result":{"stdout":"","stderr":"make: *** [test] Error 2","interrupted":false,"exitCode":2}}`,
+		`{"type":"result","subtype":"success","session_id":"s1","result":"The tests fail."}`)
+
+	// The failed command is shown even with tool output hidden, but the
+	// session, which ended normally, still succeeds
+	var buf bytes.Buffer
+	formatter := output.NewFormatter(false, false, &buf)
+	display := output.NewDisplay(formatter, output.VerbosityNormal)
+	flags := parseArgs(t, "--no-tool-output", "run the tests")
+	applyDisplayFlags(display, flags)
+	outcome, err := runSession(runner.RunOptions{ClaudePath: claude, Prompt: flags.Prompt}, display, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), output.TreeBranch+"exit 2: make: *** [test] Error 2") {
+		t.Errorf("expected the command's exit status shown, got:\n%s", buf.String())
+	}
+	if code := sessionExitCode(outcome, formatter, flags); code != 0 {
+		t.Errorf("sessionExitCode() = %d, want 0", code)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runStripToAnswer prints only the assistant text recorded in a JSONL debug
+// log or --raw-events capture (--strip-to-answer), without running Claude.
+// Turns are separated by output.AnswerTurnSeparator. path "-" reads stdin.
+// Returns 1 if the file can't be read or holds no assistant text.
+func runStripToAnswer(path string) int {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var answer output.AnswerExtractor
+	eventChan, errChan := runner.StreamLogEvents(reader)
+	for event := range eventChan {
+		answer.Add(event)
+	}
+	if err := <-errChan; err != nil {
+		fmt.Fprintf(os.Stderr, "Error: reading %s failed: %v\n", path, err)
+		return 1
+	}
+	if answer.Turns() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no assistant text found in %s\n", path)
+		return 1
+	}
+	fmt.Println(answer.Answer())
+	return 0
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+func TestRunStripToAnswer_DebugLogHeader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "stream.jsonl")
+	if err := runner.EnableDebugLogFile(path, "test", runner.RunOptions{ClaudePath: "claude", Prompt: "hi"}); err != nil {
+		t.Fatalf("EnableDebugLogFile: %v", err)
+	}
+	runner.CloseDebugLogging()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("# PARSE ERROR: invalid character 'o' in literal null\n")
+	file.WriteString(`{"type":"assistant","message":{"content":[{"type":"text","text":"The answer"}]}}` + "\n")
+	file.Close()
+
+	var warnings strings.Builder
+	log.SetOutput(&warnings)
+	defer log.SetOutput(os.Stderr)
+	stdout := redirectFile(t, &os.Stdout, dir, "stdout")
+
+	if code := runStripToAnswer(path); code != 0 {
+		t.Fatalf("runStripToAnswer() = %d, want 0", code)
+	}
+	if out, _ := os.ReadFile(stdout); string(out) != "The answer\n" {
+		t.Errorf("stdout = %q, want %q", out, "The answer\n")
+	}
+	if warnings.Len() != 0 {
+		t.Errorf("expected no warnings for the log's comment lines, got:\n%s", warnings.String())
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// commit and buildDate may be set at build time, e.g.
+//
+//	go build -ldflags "-X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// When unset they are filled from the Go build info where available.
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// versionInfo is the build metadata printed by --version --json.
+type versionInfo struct {
+	Version string `json:"version"`
+	Go      string `json:"go"`
+	Commit  string `json:"commit,omitempty"`
+	Built   string `json:"built,omitempty"`
+}
+
+// buildVersionInfo collects version metadata, preferring ldflags values and
+// falling back to the VCS settings recorded by the Go toolchain.
+func buildVersionInfo() versionInfo {
+	info := versionInfo{
+		Version: version,
+		Go:      runtime.Version(),
+		Commit:  commit,
+		Built:   buildDate,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		modified := false
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.Built == "" {
+					info.Built = s.Value
+				}
+			case "vcs.modified":
+				modified = s.Value == "true"
+			}
+		}
+		if modified && commit == "" && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	return info
+}
+
+// printVersionJSON writes the build metadata as a single JSON object.
+func printVersionJSON() {
+	data, err := json.Marshal(buildVersionInfo())
+	if err != nil {
+		fmt.Printf("{\"version\":%q}\n", version)
+		return
+	}
+	fmt.Println(string(data))
+}
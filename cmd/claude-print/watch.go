@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/peakflames/claude-print/internal/cli"
+	"github.com/peakflames/claude-print/internal/output"
+	"github.com/peakflames/claude-print/internal/runner"
+)
+
+// runWatch runs the prompt, then runs it again, continuing the same session,
+// each time files matching the --watch globs change. Each re-run is preceded
+// by a separator naming the changed files. Ctrl+C while waiting ends the
+// watch and returns 0; Ctrl+C during a run interrupts Claude and ends it with
+// the conventional signal exit code. Only changes made while waiting count,
+// so Claude's own edits (and --on-edit reformats) during a run don't queue
+// another run.
+func runWatch(opts runner.RunOptions, display *output.Display, formatter *output.Formatter, flags cli.Flags) int {
+	watcher, err := runner.NewFileWatcher(flags.Watch)
+	if err != nil {
+		formatter.ErrorWithEmoji(output.EmojiError, "Cannot watch %v: %v", flags.Watch, err)
+		return 1
+	}
+
+	for {
+		outcome, err := runWithResume(displaySession(display, nil), opts, formatter, flags)
+		if err != nil {
+			formatter.ErrorWithEmoji(output.EmojiError, "Failed to start Claude: %v", err)
+			runOnStartError(err, formatter, flags)
+			return 1
+		}
+		exitCode := sessionExitCode(outcome, formatter, flags)
+		runOnError(outcome, exitCode, formatter, flags)
+		if outcome.Signal != nil || outcome.CostDeclined {
+			return exitCode
+		}
+
+		// Later runs continue this session
+		opts.PassthroughArgs = cli.ContinuationArgs(opts.PassthroughArgs, outcome.SessionID)
+
+		changed, ok := waitForChange(watcher, formatter)
+		if !ok {
+			return 0
+		}
+		display.ShowWatchSeparator(changed)
+		display.ShowStart()
+		display.Spinner.Start()
+	}
+}
+
+// waitForChange waits for the next batch of changed paths. Returns false when
+// interrupted by SIGINT/SIGTERM.
+func waitForChange(watcher *runner.FileWatcher, formatter *output.Formatter) ([]string, bool) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-sigChan:
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	formatter.InfoWithEmoji("", "Watching for changes (Ctrl+C to exit)...")
+	formatter.Flush()
+	changed := watcher.WaitForChange(stop)
+	if changed == nil {
+		formatter.Plain("")
+		return nil, false
+	}
+	return changed, true
+}
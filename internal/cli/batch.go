@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadBatchPrompts reads a --batch file: one prompt per line. Blank lines and
+// lines starting with "#" are skipped. It returns an error if the file holds
+// no prompts.
+func LoadBatchPrompts(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch file: %w", err)
+	}
+
+	var prompts []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompts = append(prompts, line)
+	}
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("batch file %s contains no prompts", path)
+	}
+	return prompts, nil
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBatchPrompts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.txt")
+	content := "# nightly checks\nSummarize README.md\n\n  List TODOs  \r\n# done\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prompts, err := LoadBatchPrompts(path)
+	if err != nil {
+		t.Fatalf("LoadBatchPrompts() error = %v", err)
+	}
+	want := []string{"Summarize README.md", "List TODOs"}
+	if strings.Join(prompts, "|") != strings.Join(want, "|") {
+		t.Errorf("prompts = %q, want %q", prompts, want)
+	}
+}
+
+func TestLoadBatchPrompts_Empty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompts.txt")
+	if err := os.WriteFile(path, []byte("# nothing yet\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBatchPrompts(path); err == nil || !strings.Contains(err.Error(), "no prompts") {
+		t.Errorf("expected a no-prompts error, got %v", err)
+	}
+	if _, err := LoadBatchPrompts(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateEnvAssignment checks that s has the form KEY=VALUE with a valid
+// KEY. VALUE may be empty.
+func ValidateEnvAssignment(s string) error {
+	key, _, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected KEY=VALUE")
+	}
+	return ValidateEnvName(key)
+}
+
+// ValidateEnvName checks that name is usable as an environment variable
+// name: non-empty, without '=' or NUL.
+func ValidateEnvName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty variable name")
+	}
+	if strings.ContainsAny(name, "=\x00") {
+		return fmt.Errorf("variable name %q contains '=' or NUL", name)
+	}
+	return nil
+}
+
+// EnvAssignments combines the config env map (sorted by name) with --env
+// assignments into "KEY=VALUE" entries, flags last so they take precedence.
+// It returns an error if a config name is invalid.
+func EnvAssignments(configEnv map[string]string, flagEnv []string) ([]string, error) {
+	names := make([]string, 0, len(configEnv))
+	for name := range configEnv {
+		if err := ValidateEnvName(name); err != nil {
+			return nil, fmt.Errorf("invalid env entry: %w", err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	env := make([]string, 0, len(names)+len(flagEnv))
+	for _, name := range names {
+		env = append(env, name+"="+configEnv[name])
+	}
+	return append(env, flagEnv...), nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseExitCodes parses a comma-separated list of exit codes, each from 1
+// to 255, as given to --success-codes.
+func ParseExitCodes(list string) ([]int, error) {
+	var codes []int
+	for _, field := range strings.Split(list, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || code < 1 || code > 255 {
+			return nil, fmt.Errorf("exit code %q must be an integer from 1 to 255", strings.TrimSpace(field))
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// MapSuccessCode returns 0 when code is one of successCodes and code
+// otherwise.
+func MapSuccessCode(code int, successCodes []int) int {
+	if slices.Contains(successCodes, code) {
+		return 0
+	}
+	return code
+}
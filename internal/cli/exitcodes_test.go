@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseExitCodes(t *testing.T) {
+	codes, err := ParseExitCodes("1, 5,130")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{1, 5, 130}; !slices.Equal(codes, want) {
+		t.Errorf("got %v, want %v", codes, want)
+	}
+
+	for _, list := range []string{"", "0", "256", "1,x", "1,,2"} {
+		if _, err := ParseExitCodes(list); err == nil {
+			t.Errorf("ParseExitCodes(%q): expected an error", list)
+		}
+	}
+}
+
+func TestMapSuccessCode(t *testing.T) {
+	tests := []struct {
+		code, want int
+	}{
+		{0, 0},
+		{1, 0},
+		{5, 0},
+		{2, 2},
+		{130, 130},
+	}
+	for _, tt := range tests {
+		if got := MapSuccessCode(tt.code, []int{1, 5}); got != tt.want {
+			t.Errorf("MapSuccessCode(%d) = %d, want %d", tt.code, got, tt.want)
+		}
+	}
+	if got := MapSuccessCode(1, nil); got != 1 {
+		t.Errorf("with no success codes, got %d, want 1", got)
+	}
+}
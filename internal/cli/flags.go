@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -16,26 +19,208 @@ var protectedFlags = map[string]string{
 	"--include-partial-messages": "claude-print requires partial messages",
 }
 
+// optionalValueFlags are the valueFlags that have always been ignored when
+// given last without a value; any other valueFlag is then an error.
+var optionalValueFlags = map[string]bool{"--config": true, "--debug-log": true}
+
+// valueFlags are proxy flags that take a value, accepted in both
+// "--flag value" and "--flag=value" forms. Each setter stores the value on
+// Flags, returning an error if the value is invalid.
+var valueFlags = map[string]func(f *Flags, value string) error{
+	"--config":          func(f *Flags, v string) error { f.ConfigPath = v; return nil },
+	"--debug-log":       func(f *Flags, v string) error { f.DebugLog = v; return nil },
+	"--debug-dir":       func(f *Flags, v string) error { f.DebugDir = v; return nil },
+	"--model-fallback":  func(f *Flags, v string) error { f.ModelFallback = v; return nil },
+	"--json-prefix":     func(f *Flags, v string) error { f.JSONPrefix = v; return nil },
+	"--run-spec":        func(f *Flags, v string) error { f.RunSpec = v; return nil },
+	"--pipe-to":         func(f *Flags, v string) error { f.PipeTo = v; return nil },
+	"--record":          func(f *Flags, v string) error { f.Record = v; return nil },
+	"--audit-log":       func(f *Flags, v string) error { f.AuditLog = v; return nil },
+	"--batch":           func(f *Flags, v string) error { f.Batch = v; return nil },
+	"--follow":          func(f *Flags, v string) error { f.Follow = v; return nil },
+	"--strip-to-answer": func(f *Flags, v string) error { f.StripToAnswer = v; return nil },
+	"--batch-report":    func(f *Flags, v string) error { f.BatchReport = v; return nil },
+	"--on-error":        func(f *Flags, v string) error { f.OnError = v; return nil },
+	"--on-edit":         func(f *Flags, v string) error { f.OnEdit = v; return nil },
+	"--junit":           func(f *Flags, v string) error { f.JUnit = v; return nil },
+	"--template":        func(f *Flags, v string) error { f.Template = v; return nil },
+	"--debug-log-filter": func(f *Flags, v string) error {
+		f.DebugLogFilter = strings.Split(v, ",")
+		return nil
+	},
+	"--proxy": func(f *Flags, v string) error {
+		if err := ValidateProxyURL(v); err != nil {
+			return fmt.Errorf("invalid --proxy %q: %w", v, err)
+		}
+		f.Proxy = v
+		return nil
+	},
+	"--watch": func(f *Flags, v string) error {
+		if _, err := filepath.Match(v, ""); err != nil {
+			return fmt.Errorf("invalid --watch %q: %w", v, err)
+		}
+		if strings.Contains(v, "**") {
+			return fmt.Errorf("invalid --watch %q: ** is not supported; patterns match one directory level", v)
+		}
+		f.Watch = append(f.Watch, v)
+		return nil
+	},
+	"--env": func(f *Flags, v string) error {
+		if err := ValidateEnvAssignment(v); err != nil {
+			return fmt.Errorf("invalid --env %q: %w", v, err)
+		}
+		f.Env = append(f.Env, v)
+		return nil
+	},
+	"--var": func(f *Flags, v string) error {
+		if err := ValidateTemplateVar(v); err != nil {
+			return fmt.Errorf("invalid --var %q: %w", v, err)
+		}
+		f.Vars = append(f.Vars, v)
+		return nil
+	},
+	"--resume-at": func(f *Flags, v string) error {
+		return fmt.Errorf("--resume-at is not supported: the Claude CLI cannot resume a session at a message index; " +
+			"use --resume <session-id> (verbose mode lists each turn's message ID and the session ID)")
+	},
+	"--loop-guard": func(f *Flags, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid --loop-guard %q: must be a positive integer", v)
+		}
+		f.LoopGuard = n
+		return nil
+	},
+	"--auto-resume": func(f *Flags, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid --auto-resume %q: must be a positive integer", v)
+		}
+		f.AutoResume = n
+		return nil
+	},
+	"--confirm-cost": func(f *Flags, v string) error {
+		usd, err := strconv.ParseFloat(v, 64)
+		if err != nil || usd <= 0 {
+			return fmt.Errorf("invalid --confirm-cost %q: must be a positive dollar amount", v)
+		}
+		f.ConfirmCostUSD = usd
+		return nil
+	},
+	"--fail-threshold": func(f *Flags, v string) error {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return fmt.Errorf("invalid --fail-threshold %q: must be a ratio from 0 to 1", v)
+		}
+		f.FailThreshold = &ratio
+		return nil
+	},
+	"--success-codes": func(f *Flags, v string) error {
+		codes, err := ParseExitCodes(v)
+		if err != nil {
+			return fmt.Errorf("invalid --success-codes %q: %w", v, err)
+		}
+		f.SuccessCodes = codes
+		return nil
+	},
+	"--on-error-when": func(f *Flags, v string) error {
+		conditions, err := ParseOnErrorWhen(v)
+		if err != nil {
+			return fmt.Errorf("invalid --on-error-when %q: %w", v, err)
+		}
+		f.OnErrorWhen = conditions
+		return nil
+	},
+	"--max-tool-param-bytes": func(f *Flags, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid --max-tool-param-bytes %q: must be a positive integer", v)
+		}
+		f.MaxToolParamBytes = n
+		return nil
+	},
+	"--blocks-spill-bytes": func(f *Flags, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid --blocks-spill-bytes %q: must be a positive integer", v)
+		}
+		f.BlocksSpillBytes = n
+		return nil
+	},
+}
+
 // Flags holds the parsed command-line options.
 type Flags struct {
 	// Proxy-specific flags
-	Version    bool
-	Verbose    bool
-	Quiet      bool
-	NoColor    bool
-	NoEmoji    bool
-	StreamJSON bool // --stream-json: display→stderr, JSON events→stdout
-	ConfigPath string
-	DebugLog   string // --debug-log <dir> (log raw JSON to directory)
-	ShowHelp   bool
+	Version           bool
+	JSON              bool // --json: with --version, print build metadata as JSON; with --quiet, print the answer and stats as one JSON object
+	Verbose           bool
+	Quiet             bool
+	NoColor           bool
+	NoEmoji           bool
+	StreamJSON        bool   // --stream-json: display→stderr, JSON events→stdout
+	FileSummary       bool   // --file-summary: list files read/modified at session end
+	NoToolOutput      bool   // --no-tool-output: hide tool results, keep tool calls and errors
+	NoThinking        bool   // --no-thinking: don't show the "thinking..." placeholder during idle gaps
+	Labels            bool   // --labels: prefix assistant text and tool calls with speaker labels
+	StripANSI         bool   // --strip-ansi: remove ANSI escape sequences from display output
+	Wrap              bool   // --wrap: break streamed text at the terminal width
+	RenderTables      bool   // --render-tables: draw markdown tables as bordered terminal tables
+	GitContext        bool   // --git-context: show the working directory's git branch and commit
+	HidePrompt        bool   // --hide-prompt: show a placeholder instead of the prompt in the banner and debug log header
+	RelativeTime      bool   // --relative-time: append the time since the session started to each tool result line
+	RawBashOutput     bool   // --raw-bash-output: show Bash results in full with their colors in verbose mode
+	RawBashUnsafe     bool   // --raw-bash-output-unsafe: with --raw-bash-output, keep every escape sequence, not just colors
+	NoTrailingNewline bool   // --no-trailing-newline: don't end the display with a blank line
+	ShowMetadata      bool   // --show-metadata: one-line session summary (model, tools, MCP servers) in normal mode
+	REPL              bool   // --repl: read follow-up prompts from stdin and continue the session
+	StreamJSONOut     bool   // --stream-json-out: every parsed event as an enveloped JSON line on stdout
+	RawEvents         bool   // --raw-events: only parsed events as JSON lines on stdout, no display
+	BlocksJSON        bool   // --blocks-json: each session's ordered content blocks as a JSON line on stdout
+	JSONPrefix        string // --json-prefix <p>: prefix for --stream-json-out envelope field names
+	ConfigPath        string
+	DebugLog          string   // --debug-log <dir> (log raw JSON to directory)
+	DebugLogFilter    []string // --debug-log-filter <t1,t2>: only log lines of these event types
+	DebugDir          string   // --debug-dir <dir>: collect the run's debug artifacts in a new timestamped directory under dir
+	Env               []string // --env KEY=VALUE (repeatable): extra environment for the Claude process
+	Proxy             string   // --proxy <url>: set HTTPS_PROXY and HTTP_PROXY for the Claude process
+	ModelFallback     string   // --model-fallback <model> (retry once with this model on overload)
+	RunSpec           string   // --run-spec <file>: load prompt and settings from a JSON run-spec file
+	FromRunSpec       []string // Flags whose value came from the run spec rather than the command line, e.g. "--model"
+	PipeTo            string   // --pipe-to <command>: feed the final answer to command on stdin
+	Record            string   // --record <path>: record display output with timing as an asciinema v2 cast
+	AuditLog          string   // --audit-log <path>: append one JSON line per tool call for auditing
+	Batch             string   // --batch <file>: run each prompt in file (one per line) as its own session
+	BatchReport       string   // --batch-report <path>: write the batch cost report as JSON, or CSV for .csv
+	JUnit             string   // --junit <path>: write a JUnit XML report with one test case per session
+	Follow            string   // --follow <file>: render a growing JSONL debug log live instead of running Claude
+	StripToAnswer     string   // --strip-to-answer <file>: print only the assistant text of a recorded session ("-" for stdin)
+	Watch             []string // --watch <glob> (repeatable): re-run the prompt, continuing the session, when matching files change
+	Template          string   // --template <name>: build the prompt from the named config template
+	Vars              []string // --var NAME=VALUE (repeatable): values for the --template placeholders
+	MaxToolParamBytes int      // --max-tool-param-bytes <n>: truncate stored/displayed tool parameter values above n bytes
+	BlocksSpillBytes  int      // --blocks-spill-bytes <n>: move --blocks-json blocks to a temp file above n bytes
+	LoopGuard         int      // --loop-guard <n>: abort when the same tool call repeats more than n times in a row
+	AutoResume        int      // --auto-resume <n>: resume a session up to n times when Claude dies before its result
+	ConfirmCostUSD    float64  // --confirm-cost <usd>: ask before continuing each time the estimated cost passes another multiple of usd
+	FailThreshold     *float64 // --fail-threshold <ratio>: exit 5 when a completed session's tool error ratio exceeds ratio (nil: off)
+	SuccessCodes      []int    // --success-codes <list>: session exit codes reported as 0
+	OnError           string   // --on-error <command>: run when a session fails (see OnErrorWhen)
+	OnEdit            string   // --on-edit <command>: run on each file written or edited, with {file} replaced by its path
+	OnErrorWhen       []string // --on-error-when <list>: failure conditions for OnError (nil: DefaultOnErrorWhen)
+	ShowHelp          bool
+	Doctor            bool // --doctor / --validate-config: check config and environment, then exit
+	PrintConfig       bool // --print-config: print the effective config with value sources as JSON, then exit
 
 	// Positional and passthrough
-	Prompt          string   // First positional argument (the prompt for Claude) or stdin
+	Prompt          string   // Positional prompt for Claude (see ParseFlags), or stdin
+	EmptyPrompt     bool     // The positional prompt was empty or whitespace-only
 	PassthroughArgs []string // All other args passed to Claude unchanged
 }
 
 // ParseFlags parses command-line arguments and returns the parsed Flags.
-// Returns an error if a protected flag is used.
+// The prompt is normally the first positional argument that isn't the value
+// of the Claude flag before it. Returns an error if a protected flag is used.
 func ParseFlags() (Flags, error) {
 	f := Flags{}
 	args := os.Args[1:]
@@ -43,6 +228,8 @@ func ParseFlags() (Flags, error) {
 	// Track which args to pass through
 	var passthrough []string
 	skipNext := false
+	promptSeen := false   // The prompt was given after --
+	var positionals []int // Where positional arguments fell among passthrough args
 
 	for i := 0; i < len(args); i++ {
 		if skipNext {
@@ -52,22 +239,71 @@ func ParseFlags() (Flags, error) {
 
 		arg := args[i]
 
+		// "--" ends option parsing: everything after it is prompt text, so
+		// a prompt may mention flags such as -p without tripping the checks
+		if arg == "--" {
+			if rest := args[i+1:]; len(rest) > 0 {
+				promptSeen = true
+				if prompt := strings.Join(rest, " "); strings.TrimSpace(prompt) == "" {
+					f.EmptyPrompt = true
+				} else {
+					f.Prompt = prompt
+				}
+			}
+			break
+		}
+
+		// A token that only starts with a dash (e.g. a quoted "-p means
+		// print") is a positional argument, not a flag; see looksLikeFlag
+		if !looksLikeFlag(arg) && strings.HasPrefix(arg, "-") {
+			positionals = append(positionals, len(passthrough))
+			passthrough = append(passthrough, arg)
+			continue
+		}
+
 		// Check for protected flags (with or without values)
 		baseFlagName := extractFlagName(arg)
 		if reason, blocked := isProtectedFlag(baseFlagName); blocked {
 			return Flags{}, fmt.Errorf("cannot use %s: %s", baseFlagName, reason)
 		}
 
+		// Handle value-taking proxy flags in "--flag value" and "--flag=value" forms
+		if set, ok := valueFlags[arg]; ok {
+			if i+1 < len(args) {
+				if err := set(&f, args[i+1]); err != nil {
+					return Flags{}, err
+				}
+				skipNext = true
+			} else if !optionalValueFlags[arg] {
+				return Flags{}, fmt.Errorf("%s requires a value", arg)
+			}
+			continue
+		}
+		if name, value, found := strings.Cut(arg, "="); found {
+			if set, ok := valueFlags[name]; ok {
+				if err := set(&f, value); err != nil {
+					return Flags{}, err
+				}
+				continue
+			}
+		}
+
 		// Handle proxy-specific flags
 		switch arg {
 		case "-v", "--version":
 			f.Version = true
+		case "--json":
+			f.JSON = true
 		case "-h", "--help":
 			f.ShowHelp = true
+		case "--doctor", "--validate-config":
+			f.Doctor = true
+		case "--print-config":
+			f.PrintConfig = true
 		case "--verbose":
-			// Verbose is consumed by proxy AND passed through to Claude
+			// Display verbosity only: Claude's own --verbose, which stream-json
+			// output needs, comes from the required args in buildArgs
 			f.Verbose = true
-			passthrough = append(passthrough, arg)
 		case "--quiet":
 			f.Quiet = true
 		case "--no-color":
@@ -76,23 +312,44 @@ func ParseFlags() (Flags, error) {
 			f.NoEmoji = true
 		case "--stream-json":
 			f.StreamJSON = true
-		case "--config":
-			if i+1 < len(args) {
-				f.ConfigPath = args[i+1]
-				skipNext = true
-			}
-		case "--debug-log":
-			if i+1 < len(args) {
-				f.DebugLog = args[i+1]
-				skipNext = true
-			}
+		case "--file-summary":
+			f.FileSummary = true
+		case "--no-tool-output":
+			f.NoToolOutput = true
+		case "--no-thinking":
+			f.NoThinking = true
+		case "--show-metadata":
+			f.ShowMetadata = true
+		case "--strip-ansi":
+			f.StripANSI = true
+		case "--wrap":
+			f.Wrap = true
+		case "--render-tables":
+			f.RenderTables = true
+		case "--git-context":
+			f.GitContext = true
+		case "--hide-prompt":
+			f.HidePrompt = true
+		case "--relative-time":
+			f.RelativeTime = true
+		case "--raw-bash-output":
+			f.RawBashOutput = true
+		case "--raw-bash-output-unsafe":
+			f.RawBashUnsafe = true
+		case "--no-trailing-newline":
+			f.NoTrailingNewline = true
+		case "--labels":
+			f.Labels = true
+		case "--repl":
+			f.REPL = true
+		case "--stream-json-out":
+			f.StreamJSONOut = true
+		case "--raw-events":
+			f.RawEvents = true
+		case "--blocks-json":
+			f.BlocksJSON = true
 		default:
-			// Handle --config=value and --debug-log=value forms
-			if strings.HasPrefix(arg, "--config=") {
-				f.ConfigPath = strings.TrimPrefix(arg, "--config=")
-			} else if strings.HasPrefix(arg, "--debug-log=") {
-				f.DebugLog = strings.TrimPrefix(arg, "--debug-log=")
-			} else if strings.HasPrefix(arg, "-") {
+			if strings.HasPrefix(arg, "-") {
 				// Any other flag is passed through to Claude
 				passthrough = append(passthrough, arg)
 
@@ -101,20 +358,135 @@ func ParseFlags() (Flags, error) {
 				// This handles --continue (no value), --resume <id> (has value), etc.
 				// For simplicity, we pass both and let Claude parse them
 				// Flags with = already contain their value
-			} else if f.Prompt == "" {
-				// First non-flag arg is the prompt
-				f.Prompt = arg
 			} else {
-				// Additional positional args are passed through
+				// Positional args are passed through, except the prompt
+				// picked out below
+				positionals = append(positionals, len(passthrough))
 				passthrough = append(passthrough, arg)
 			}
 		}
 	}
 
+	// A run spec is read first, since its prompt decides whether one is
+	// needed from the command line
+	var spec RunSpec
+	if f.RunSpec != "" {
+		var err error
+		if spec, err = LoadRunSpec(f.RunSpec); err != nil {
+			return Flags{}, err
+		}
+	}
+
+	// The prompt is the first positional arg that isn't the value of a
+	// passthrough flag written without "=" just before it (e.g. sonnet in
+	// --model sonnet). Failing that it is the first positional arg, unless
+	// the prompt comes from after --, --template or the run spec. A blank
+	// prompt is recorded (so callers can warn) but treated as no prompt.
+	promptAt := -1
+	for _, i := range positionals {
+		if i == 0 || !looksLikeFlag(passthrough[i-1]) || strings.Contains(passthrough[i-1], "=") {
+			promptAt = i
+			break
+		}
+	}
+	if promptAt < 0 && len(positionals) > 0 && !promptSeen && f.Template == "" && spec.Prompt == "" {
+		promptAt = positionals[0]
+	}
+	if promptAt >= 0 {
+		if promptSeen {
+			return Flags{}, fmt.Errorf("cannot give a prompt both before and after --")
+		}
+		if prompt := passthrough[promptAt]; strings.TrimSpace(prompt) == "" {
+			f.EmptyPrompt = true
+		} else {
+			f.Prompt = prompt
+		}
+		passthrough = slices.Delete(passthrough, promptAt, promptAt+1)
+	}
 	f.PassthroughArgs = passthrough
 
+	if f.StreamJSON && f.StreamJSONOut {
+		return Flags{}, fmt.Errorf("cannot combine --stream-json and --stream-json-out: both write to stdout")
+	}
+	if f.RawEvents && (f.StreamJSON || f.StreamJSONOut) {
+		return Flags{}, fmt.Errorf("cannot combine --raw-events with --stream-json or --stream-json-out: all write to stdout")
+	}
+	if f.BlocksSpillBytes > 0 && !f.BlocksJSON {
+		return Flags{}, fmt.Errorf("--blocks-spill-bytes requires --blocks-json")
+	}
+	if f.BlocksJSON && (f.StreamJSON || f.StreamJSONOut || f.RawEvents) {
+		return Flags{}, fmt.Errorf("cannot combine --blocks-json with --stream-json, --stream-json-out or --raw-events: all write to stdout")
+	}
+	if f.RawBashUnsafe && !f.RawBashOutput {
+		return Flags{}, fmt.Errorf("--raw-bash-output-unsafe requires --raw-bash-output")
+	}
+	if len(f.Vars) > 0 && f.Template == "" {
+		return Flags{}, fmt.Errorf("--var requires --template")
+	}
+	if f.Template != "" && (f.Prompt != "" || f.EmptyPrompt) {
+		return Flags{}, fmt.Errorf("cannot combine --template with a prompt: the template is the prompt")
+	}
+	if f.Template != "" && f.Batch != "" {
+		return Flags{}, fmt.Errorf("cannot combine --template and --batch")
+	}
+	if f.RawEvents && f.REPL {
+		return Flags{}, fmt.Errorf("cannot combine --raw-events and --repl")
+	}
+
+	// Fill in anything not set on the command line from the run spec
+	if f.RunSpec != "" {
+		applyRunSpec(&f, spec)
+	}
+
+	// --json needs --quiet, which a run spec may set
+	if f.JSON && !f.Version && !f.Quiet {
+		return Flags{}, fmt.Errorf("--json requires --version or --quiet")
+	}
+	if f.JSON && f.Quiet && (f.StreamJSON || f.StreamJSONOut || f.RawEvents || f.BlocksJSON) {
+		return Flags{}, fmt.Errorf("cannot combine --quiet --json with --stream-json, --stream-json-out, --raw-events or --blocks-json: all write to stdout")
+	}
+	if f.JSON && f.Quiet && (f.REPL || f.Batch != "" || len(f.Watch) > 0 || f.Follow != "") {
+		return Flags{}, fmt.Errorf("cannot combine --quiet --json with --repl, --batch, --watch or --follow")
+	}
+	if f.Batch != "" && (f.REPL || f.RawEvents) {
+		return Flags{}, fmt.Errorf("cannot combine --batch with --repl or --raw-events")
+	}
+	if len(f.Watch) > 0 && (f.REPL || f.Batch != "" || f.Follow != "" || f.RawEvents) {
+		return Flags{}, fmt.Errorf("cannot combine --watch with --repl, --batch, --follow or --raw-events")
+	}
+	if f.Follow != "" && (f.Prompt != "" || f.REPL || f.Batch != "" || f.RawEvents) {
+		return Flags{}, fmt.Errorf("cannot combine --follow with a prompt, --repl, --batch or --raw-events: it only replays a debug log")
+	}
+	if f.PipeTo != "" && (f.StreamJSON || f.StreamJSONOut || f.RawEvents) {
+		return Flags{}, fmt.Errorf("cannot combine --pipe-to with --stream-json, --stream-json-out or --raw-events: stdout already carries the events")
+	}
+	if f.PipeTo != "" && (f.BlocksJSON || (f.Quiet && f.JSON)) {
+		return Flags{}, fmt.Errorf("cannot combine --pipe-to with --quiet --json or --blocks-json: stdout only carries JSON")
+	}
+	if f.JUnit != "" && (f.REPL || len(f.Watch) > 0 || f.Follow != "" || f.RawEvents) {
+		return Flags{}, fmt.Errorf("cannot combine --junit with --repl, --watch, --follow or --raw-events")
+	}
+	if f.StripToAnswer != "" && (f.Prompt != "" || f.REPL || f.Batch != "" || len(f.Watch) > 0 || f.Follow != "") {
+		return Flags{}, fmt.Errorf("cannot combine --strip-to-answer with a prompt, --repl, --batch, --watch or --follow")
+	}
+	if f.Batch != "" && f.Prompt != "" {
+		return Flags{}, fmt.Errorf("cannot combine --batch with a prompt: the prompts come from the batch file")
+	}
+	if f.OnErrorWhen != nil && f.OnError == "" {
+		return Flags{}, fmt.Errorf("--on-error-when requires --on-error")
+	}
+	if f.BatchReport != "" && f.Batch == "" {
+		return Flags{}, fmt.Errorf("--batch-report requires --batch")
+	}
+	if f.DebugDir != "" && f.DebugLog != "" {
+		return Flags{}, fmt.Errorf("cannot combine --debug-dir and --debug-log: --debug-dir writes the debug log to its own directory")
+	}
+
 	// If no prompt was given as a positional argument, check for piped stdin.
-	if f.Prompt == "" {
+	// In REPL, batch, follow and strip-to-answer modes stdin is not a prompt, and --version,
+	// --help and --doctor never need a prompt (so they can't block on a pipe).
+	// An explicitly blank prompt and --template also skip stdin.
+	if f.Prompt == "" && !f.EmptyPrompt && f.Template == "" && !f.REPL && f.Batch == "" && f.Follow == "" && f.StripToAnswer == "" && !f.Version && !f.ShowHelp && !f.Doctor && !f.PrintConfig {
 		stat, err := os.Stdin.Stat()
 		if err == nil && (stat.Mode()&os.ModeCharDevice) == 0 {
 			data, err := io.ReadAll(os.Stdin)
@@ -128,6 +500,31 @@ func ParseFlags() (Flags, error) {
 	return f, nil
 }
 
+// ValidateDialectFlags checks prompt-flag and required-flag overrides for
+// Claude-compatible CLIs. Overrides must look like flags and must not reuse a
+// protected flag for another purpose: only -p/--print may deliver the prompt,
+// and the required flags must not include them.
+func ValidateDialectFlags(promptFlag string, requiredFlags []string) error {
+	if promptFlag != "" {
+		if !strings.HasPrefix(promptFlag, "-") {
+			return fmt.Errorf("promptFlag %q must start with '-'", promptFlag)
+		}
+		if _, protected := protectedFlags[promptFlag]; protected && promptFlag != "-p" && promptFlag != "--print" {
+			return fmt.Errorf("promptFlag %q collides with protected flag %s", promptFlag, promptFlag)
+		}
+	}
+	for _, flag := range requiredFlags {
+		if !strings.HasPrefix(flag, "-") {
+			return fmt.Errorf("requiredFlags entry %q must start with '-'", flag)
+		}
+		name := extractFlagName(flag)
+		if name == "-p" || name == "--print" || (promptFlag != "" && name == promptFlag) {
+			return fmt.Errorf("requiredFlags entry %q collides with the prompt flag; set promptFlag instead", flag)
+		}
+	}
+	return nil
+}
+
 // extractFlagName extracts the flag name from an argument, handling --flag=value forms.
 func extractFlagName(arg string) string {
 	if idx := strings.Index(arg, "="); idx != -1 {
@@ -136,6 +533,13 @@ func extractFlagName(arg string) string {
 	return arg
 }
 
+// looksLikeFlag reports whether arg is a flag: it starts with a dash and its
+// name (before any "=value") has no whitespace. Shells split unquoted words,
+// so a dash-led token containing spaces was quoted as prose.
+func looksLikeFlag(arg string) bool {
+	return strings.HasPrefix(arg, "-") && !strings.ContainsAny(extractFlagName(arg), " \t\n")
+}
+
 // isProtectedFlag checks if a flag is protected and returns the reason if so.
 func isProtectedFlag(flag string) (string, bool) {
 	reason, blocked := protectedFlags[flag]
@@ -152,3 +556,78 @@ func ContainsSessionFlag(args []string) bool {
 	}
 	return false
 }
+
+// SetFlagValue returns a copy of args with flag set to value. An existing
+// "--flag value" or "--flag=value" occurrence is replaced in place; otherwise
+// "--flag value" is appended.
+func SetFlagValue(args []string, flag, value string) []string {
+	out := make([]string, 0, len(args)+2)
+	replaced := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == flag:
+			out = append(out, flag, value)
+			replaced = true
+			if i+1 < len(args) {
+				i++ // Skip the old value
+			}
+		case strings.HasPrefix(arg, flag+"="):
+			out = append(out, flag+"="+value)
+			replaced = true
+		default:
+			out = append(out, arg)
+		}
+	}
+	if !replaced {
+		out = append(out, flag, value)
+	}
+	return out
+}
+
+// ContinuationArgs returns passthrough args for a follow-up turn in the same
+// session. Any existing --continue/--resume flags are dropped; the session is
+// then resumed by ID when known, or with --continue otherwise.
+func ContinuationArgs(args []string, sessionID string) []string {
+	out := make([]string, 0, len(args)+2)
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--continue" || strings.HasPrefix(arg, "--resume="):
+			continue
+		case arg == "--resume":
+			i++ // Skip the session ID value
+			continue
+		default:
+			out = append(out, arg)
+		}
+	}
+	if sessionID != "" {
+		return append(out, "--resume", sessionID)
+	}
+	return append(out, "--continue")
+}
+
+// HasFlag reports whether args contain flag in either "--flag" or
+// "--flag=value" form.
+func HasFlag(args []string, flag string) bool {
+	_, ok := FlagValue(args, flag)
+	return ok
+}
+
+// FlagValue returns the value given for flag in args, handling both the
+// "--flag value" and "--flag=value" forms. The boolean reports whether the
+// flag was present at all; a bare trailing "--flag" yields an empty value.
+func FlagValue(args []string, flag string) (string, bool) {
+	for i, arg := range args {
+		if arg == flag {
+			if i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", true
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"="), true
+		}
+	}
+	return "", false
+}
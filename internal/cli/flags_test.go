@@ -2,6 +2,8 @@ package cli
 
 import (
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -108,6 +110,91 @@ func TestParseFlags_ProtectedFlags(t *testing.T) {
 	}
 }
 
+func TestParseFlags_PromptResemblingProtectedFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantPrompt  string
+		passthrough []string
+	}{
+		{"quoted prose starting with -p", []string{"-p means print, right?"}, "-p means print, right?", nil},
+		{"quoted prose starting with --output-format", []string{"--output-format json, how?"}, "--output-format json, how?", nil},
+		{"words after --", []string{"--verbose", "--", "explain", "the", "-p", "flag"}, "explain the -p flag", nil},
+		{"flags before --", []string{"--continue", "--", "--output-format"}, "--output-format", []string{"--continue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveAndSetArgs(t, append([]string{"claude-print"}, tt.args...))
+			flags, err := ParseFlags()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if flags.Prompt != tt.wantPrompt {
+				t.Errorf("Prompt = %q, want %q", flags.Prompt, tt.wantPrompt)
+			}
+			if !slices.Equal(flags.PassthroughArgs, tt.passthrough) {
+				t.Errorf("PassthroughArgs = %q, want %q", flags.PassthroughArgs, tt.passthrough)
+			}
+		})
+	}
+}
+
+func TestParseFlags_ProtectedFlagWithSpacedValue(t *testing.T) {
+	// The name before "=" decides: this is still the protected --print flag
+	saveAndSetArgs(t, []string{"claude-print", "--print=the answer"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected an error for --print=value")
+	}
+}
+
+func TestParseFlags_PromptBeforeAndAfterDoubleDash(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "first", "--", "second"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected an error for a prompt on both sides of --")
+	}
+}
+
+func TestParseFlags_SuccessCodes(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--success-codes", "1,5", "lint"})
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(flags.SuccessCodes, []int{1, 5}) {
+		t.Errorf("SuccessCodes = %v, want [1 5]", flags.SuccessCodes)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--success-codes=0", "lint"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected an error for exit code 0")
+	}
+}
+
+func TestParseFlags_OnError(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--on-error", "notify.sh", "--on-error-when", "result, tool-errors", "task"})
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.OnError != "notify.sh" {
+		t.Errorf("OnError = %q, want notify.sh", flags.OnError)
+	}
+	if want := []string{OnErrorResult, OnErrorToolErrors}; !slices.Equal(flags.OnErrorWhen, want) {
+		t.Errorf("OnErrorWhen = %q, want %q", flags.OnErrorWhen, want)
+	}
+
+	for _, args := range [][]string{
+		{"--on-error", "notify.sh", "--on-error-when", "crash", "task"},
+		{"--on-error-when", "exit", "task"},
+	} {
+		saveAndSetArgs(t, append([]string{"claude-print"}, args...))
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("expected %q to be rejected", args)
+		}
+	}
+}
+
 func TestParseFlags_PassthroughArgs(t *testing.T) {
 	saveAndSetArgs(t, []string{"claude-print", "my prompt", "--continue", "--max-turns", "5"})
 
@@ -123,3 +210,551 @@ func TestParseFlags_PassthroughArgs(t *testing.T) {
 		t.Errorf("expected passthrough args, got none")
 	}
 }
+
+func TestParseFlags_ModelFallback(t *testing.T) {
+	tests := [][]string{
+		{"claude-print", "--model-fallback", "haiku", "my prompt"},
+		{"claude-print", "--model-fallback=haiku", "my prompt"},
+	}
+
+	for _, args := range tests {
+		saveAndSetArgs(t, args)
+		flags, err := ParseFlags()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if flags.ModelFallback != "haiku" {
+			t.Errorf("%v: expected ModelFallback %q, got %q", args, "haiku", flags.ModelFallback)
+		}
+		if len(flags.PassthroughArgs) != 0 {
+			t.Errorf("%v: expected --model-fallback to be consumed, got passthrough %v", args, flags.PassthroughArgs)
+		}
+	}
+}
+
+func TestSetFlagValue(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"append", []string{"--continue"}, []string{"--continue", "--model", "haiku"}},
+		{"replace space form", []string{"--model", "opus", "--continue"}, []string{"--model", "haiku", "--continue"}},
+		{"replace equals form", []string{"--model=opus"}, []string{"--model=haiku"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SetFlagValue(tt.args, "--model", "haiku")
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("SetFlagValue(%v) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContinuationArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		sessionID string
+		want      []string
+	}{
+		{"continue without id", []string{"--max-turns", "5"}, "", []string{"--max-turns", "5", "--continue"}},
+		{"resume by id", []string{"--continue"}, "abc", []string{"--resume", "abc"}},
+		{"replaces prior resume", []string{"--resume", "old", "--verbose"}, "new", []string{"--verbose", "--resume", "new"}},
+		{"replaces resume equals form", []string{"--resume=old"}, "", []string{"--continue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ContinuationArgs(tt.args, tt.sessionID)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("ContinuationArgs(%v, %q) = %v, want %v", tt.args, tt.sessionID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFlags_PipeTo(t *testing.T) {
+	tests := [][]string{
+		{"claude-print", "--pipe-to", "jq .", "my prompt"},
+		{"claude-print", "--pipe-to=jq .", "my prompt"},
+	}
+
+	for _, args := range tests {
+		saveAndSetArgs(t, args)
+		flags, err := ParseFlags()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if flags.PipeTo != "jq ." {
+			t.Errorf("%v: expected PipeTo %q, got %q", args, "jq .", flags.PipeTo)
+		}
+		if flags.Prompt != "my prompt" {
+			t.Errorf("%v: expected prompt %q, got %q", args, "my prompt", flags.Prompt)
+		}
+	}
+}
+
+func TestParseFlags_MaxToolParamBytes(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--max-tool-param-bytes=4096", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.MaxToolParamBytes != 4096 {
+		t.Errorf("expected MaxToolParamBytes 4096, got %d", flags.MaxToolParamBytes)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--max-tool-param-bytes", "lots", "my prompt"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected error for non-numeric --max-tool-param-bytes")
+	}
+}
+
+func TestValidateDialectFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompt   string
+		required []string
+		wantErr  bool
+	}{
+		{"defaults", "", nil, false},
+		{"custom prompt flag", "--prompt", []string{"--stream", "--format=jsonl"}, false},
+		{"print alias", "--print", nil, false},
+		{"not a flag", "prompt", nil, true},
+		{"protected prompt flag", "--output-format", nil, true},
+		{"required includes -p", "", []string{"-p"}, true},
+		{"required includes custom prompt flag", "--prompt", []string{"--prompt=x"}, true},
+		{"required not a flag", "", []string{"stream"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDialectFlags(tt.prompt, tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDialectFlags(%q, %v) error = %v, wantErr %v", tt.prompt, tt.required, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseFlags_VersionJSON(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--version", "--json"})
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flags.Version || !flags.JSON {
+		t.Errorf("expected Version and JSON, got %+v", flags)
+	}
+	if len(flags.PassthroughArgs) != 0 {
+		t.Errorf("expected --json to be consumed, got passthrough %v", flags.PassthroughArgs)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--json", "my prompt"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected error for --json without --version")
+	}
+}
+
+func TestParseFlags_PromptAfterFlagValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantPrompt  string
+		passthrough []string
+	}{
+		{"flag value then prompt", []string{"--permission-mode", "plan", "do it"}, "do it", []string{"--permission-mode", "plan"}},
+		{"prompt after a flag without a value", []string{"--continue", "do it"}, "do it", []string{"--continue"}},
+		{"equals form", []string{"--model=sonnet", "do it"}, "do it", []string{"--model=sonnet"}},
+		{"prompt first", []string{"do it", "--model", "sonnet"}, "do it", []string{"--model", "sonnet"}},
+		{"flag value then --", []string{"--model", "sonnet", "--", "do", "it"}, "do it", []string{"--model", "sonnet"}},
+	}
+	for _, tt := range tests {
+		saveAndSetArgs(t, append([]string{"claude-print"}, tt.args...))
+		flags, err := ParseFlags()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if flags.Prompt != tt.wantPrompt || !slices.Equal(flags.PassthroughArgs, tt.passthrough) {
+			t.Errorf("%s: got prompt %q, passthrough %q; want %q, %q", tt.name, flags.Prompt, flags.PassthroughArgs, tt.wantPrompt, tt.passthrough)
+		}
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "do it", "--", "and more"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected an error for a prompt both before and after --")
+	}
+}
+
+func TestParseFlags_EmptyPromptWithContinue(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "  ", "--continue", "extra"})
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flags.EmptyPrompt {
+		t.Error("expected EmptyPrompt to be set for a blank positional prompt")
+	}
+	if flags.Prompt != "" {
+		t.Errorf("expected blank prompt to be treated as empty, got %q", flags.Prompt)
+	}
+	want := []string{"--continue", "extra"}
+	if strings.Join(flags.PassthroughArgs, " ") != strings.Join(want, " ") {
+		t.Errorf("PassthroughArgs = %v, want %v", flags.PassthroughArgs, want)
+	}
+}
+
+func TestParseFlags_ResumeAtRejected(t *testing.T) {
+	for _, args := range [][]string{
+		{"claude-print", "--resume-at", "3", "--resume", "abc"},
+		{"claude-print", "--resume-at=3"},
+	} {
+		saveAndSetArgs(t, args)
+		_, err := ParseFlags()
+		if err == nil || !strings.Contains(err.Error(), "--resume-at is not supported") {
+			t.Errorf("%v: expected unsupported error, got %v", args, err)
+		}
+	}
+}
+
+func TestParseFlags_Env(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--env", "A=1", "--env=B=x=y", "--env", "EMPTY=", "hi"})
+	f, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if want := []string{"A=1", "B=x=y", "EMPTY="}; strings.Join(f.Env, " ") != strings.Join(want, " ") {
+		t.Errorf("Env = %v, want %v", f.Env, want)
+	}
+
+	for _, bad := range []string{"NOEQUALS", "=value"} {
+		saveAndSetArgs(t, []string{"claude-print", "--env", bad, "hi"})
+		if _, err := ParseFlags(); err == nil || !strings.Contains(err.Error(), "invalid --env") {
+			t.Errorf("--env %q: expected invalid --env error, got %v", bad, err)
+		}
+	}
+}
+
+func TestParseFlags_Template(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--template", "review", "--var", "file=x.go", "--var=concern=", "--model", "sonnet"})
+	f, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if f.Template != "review" || strings.Join(f.Vars, " ") != "file=x.go concern=" || f.Prompt != "" ||
+		strings.Join(f.PassthroughArgs, " ") != "--model sonnet" {
+		t.Errorf("unexpected flags: %+v", f)
+	}
+
+	for _, args := range [][]string{
+		{"--var", "file=x.go", "hi"},
+		{"--template", "review", "hi"},
+		{"--template", "review", "--batch", "prompts.txt"},
+		{"--template", "review", "--var", "bad-name=1"},
+		{"--template", "review", "--var", "NOEQUALS"},
+	} {
+		saveAndSetArgs(t, append([]string{"claude-print"}, args...))
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+}
+
+func TestEnvAssignments_FlagsOverrideConfig(t *testing.T) {
+	got, err := EnvAssignments(map[string]string{"Z": "1", "A": "2"}, []string{"Z=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"A=2", "Z=1", "Z=3"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("EnvAssignments = %v, want %v", got, want)
+	}
+	if _, err := EnvAssignments(map[string]string{"": "x"}, nil); err == nil {
+		t.Error("expected error for empty config env name")
+	}
+}
+
+func TestParseFlags_ConfirmCost(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--confirm-cost", "2.50", "hi"})
+	f, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if f.ConfirmCostUSD != 2.5 {
+		t.Errorf("ConfirmCostUSD = %v, want 2.5", f.ConfirmCostUSD)
+	}
+
+	for _, bad := range []string{"0", "-1", "lots"} {
+		saveAndSetArgs(t, []string{"claude-print", "--confirm-cost=" + bad, "hi"})
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("--confirm-cost=%s: expected error", bad)
+		}
+	}
+}
+
+func TestParseFlags_PrintConfig(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--print-config", "--verbose"})
+	f, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("ParseFlags: %v", err)
+	}
+	if !f.PrintConfig || !f.Verbose {
+		t.Errorf("expected PrintConfig and Verbose, got %+v", f)
+	}
+	if f.Prompt != "" {
+		t.Errorf("expected no prompt, got %q", f.Prompt)
+	}
+}
+
+func TestParseFlags_VerboseNotPassedThrough(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--verbose", "--max-turns", "2", "my prompt"})
+
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("ParseFlags() error = %v", err)
+	}
+	if !flags.Verbose {
+		t.Error("expected Verbose to be set")
+	}
+	if HasFlag(flags.PassthroughArgs, "--verbose") {
+		t.Errorf("--verbose should only set display verbosity, got passthrough %v", flags.PassthroughArgs)
+	}
+}
+
+func TestParseFlags_RawEventsConflicts(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--raw-events", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || !flags.RawEvents {
+		t.Fatalf("ParseFlags() = %+v, %v", flags, err)
+	}
+
+	for _, other := range []string{"--stream-json", "--stream-json-out", "--repl"} {
+		saveAndSetArgs(t, []string{"claude-print", "--raw-events", other, "my prompt"})
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("expected --raw-events with %s to be rejected", other)
+		}
+	}
+}
+
+func TestParseFlags_RawBashOutput(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--raw-bash-output", "--raw-bash-output-unsafe", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || !flags.RawBashOutput || !flags.RawBashUnsafe {
+		t.Fatalf("ParseFlags() = %+v, %v", flags, err)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--raw-bash-output-unsafe", "my prompt"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("expected --raw-bash-output-unsafe without --raw-bash-output to be rejected")
+	}
+}
+
+func TestParseFlags_BlocksJSONConflicts(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--blocks-json", "--repl", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || !flags.BlocksJSON {
+		t.Fatalf("ParseFlags() = %+v, %v", flags, err)
+	}
+
+	for _, other := range []string{"--stream-json", "--stream-json-out", "--raw-events"} {
+		saveAndSetArgs(t, []string{"claude-print", "--blocks-json", other, "my prompt"})
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("expected --blocks-json with %s to be rejected", other)
+		}
+	}
+}
+
+func TestParseFlags_BatchConflicts(t *testing.T) {
+	tests := [][]string{
+		{"claude-print", "--batch", "prompts.txt", "my prompt"},
+		{"claude-print", "--batch", "prompts.txt", "--repl"},
+		{"claude-print", "--batch-report", "report.csv", "my prompt"},
+	}
+	for _, args := range tests {
+		saveAndSetArgs(t, args)
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(%q) should fail", args[1:])
+		}
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--batch", "prompts.txt", "--batch-report=report.csv"})
+	flags, err := ParseFlags()
+	if err != nil || flags.Batch != "prompts.txt" || flags.BatchReport != "report.csv" {
+		t.Errorf("ParseFlags() = %+v, %v", flags, err)
+	}
+}
+
+func TestParseFlags_AutoResume(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--auto-resume", "2", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || flags.AutoResume != 2 || flags.Prompt != "my prompt" {
+		t.Errorf("ParseFlags() = %d, %q, %v", flags.AutoResume, flags.Prompt, err)
+	}
+
+	for _, v := range []string{"0", "-1", "two"} {
+		saveAndSetArgs(t, []string{"claude-print", "--auto-resume=" + v, "my prompt"})
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(--auto-resume=%s) should fail", v)
+		}
+	}
+}
+
+func TestParseFlags_StripToAnswer(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--strip-to-answer", "session.jsonl"})
+	flags, err := ParseFlags()
+	if err != nil || flags.StripToAnswer != "session.jsonl" {
+		t.Errorf("ParseFlags() = %q, %v", flags.StripToAnswer, err)
+	}
+
+	for _, args := range [][]string{
+		{"claude-print", "--strip-to-answer", "session.jsonl", "my prompt"},
+		{"claude-print", "--strip-to-answer=session.jsonl", "--follow", "other.jsonl"},
+	} {
+		saveAndSetArgs(t, args)
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(%q) should fail", args[1:])
+		}
+	}
+}
+
+func TestParseFlags_DebugDir(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--debug-dir=debug", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || flags.DebugDir != "debug" || flags.Prompt != "my prompt" {
+		t.Errorf("ParseFlags() = %q, %q, %v", flags.DebugDir, flags.Prompt, err)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--debug-dir", "debug", "--debug-log", "logs", "my prompt"})
+	if _, err := ParseFlags(); err == nil {
+		t.Error("ParseFlags() should reject --debug-dir with --debug-log")
+	}
+}
+
+func TestParseFlags_Watch(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--watch", "*.go", "--watch=docs/*.md", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || !slices.Equal(flags.Watch, []string{"*.go", "docs/*.md"}) {
+		t.Errorf("ParseFlags() = %v, %v", flags.Watch, err)
+	}
+
+	tests := [][]string{
+		{"claude-print", "--watch", "[unclosed", "my prompt"},
+		{"claude-print", "--watch", "src/**/*.go", "my prompt"},
+		{"claude-print", "--watch", "*.go", "--repl"},
+		{"claude-print", "--watch", "*.go", "--batch", "prompts.txt"},
+		{"claude-print", "--watch", "*.go", "--follow", "debug.jsonl"},
+		{"claude-print", "--watch", "*.go", "--raw-events", "my prompt"},
+	}
+	for _, args := range tests {
+		saveAndSetArgs(t, args)
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(%q) should fail", args[1:])
+		}
+	}
+}
+
+func TestParseFlags_Follow(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--follow", "debug.jsonl"})
+	flags, err := ParseFlags()
+	if err != nil || flags.Follow != "debug.jsonl" {
+		t.Errorf("ParseFlags() = %q, %v", flags.Follow, err)
+	}
+
+	tests := [][]string{
+		{"claude-print", "--follow", "debug.jsonl", "my prompt"},
+		{"claude-print", "--follow", "debug.jsonl", "--repl"},
+		{"claude-print", "--follow", "debug.jsonl", "--batch", "prompts.txt"},
+		{"claude-print", "--follow=debug.jsonl", "--raw-events"},
+	}
+	for _, args := range tests {
+		saveAndSetArgs(t, args)
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(%q) should fail", args[1:])
+		}
+	}
+}
+
+func TestParseFlags_MissingValue(t *testing.T) {
+	for _, flag := range []string{"--resume-at", "--confirm-cost", "--pipe-to", "--audit-log"} {
+		args := []string{"claude-print", "--print-config", flag}
+		saveAndSetArgs(t, args)
+		_, err := ParseFlags()
+		if err == nil || err.Error() != flag+" requires a value" {
+			t.Errorf("ParseFlags(%q) = %v, want a missing value error", args[1:], err)
+		}
+	}
+
+	// --config and --debug-log keep ignoring a missing value
+	saveAndSetArgs(t, []string{"claude-print", "--print-config", "--config"})
+	if _, err := ParseFlags(); err != nil {
+		t.Errorf("ParseFlags() with a trailing --config = %v, want nil", err)
+	}
+}
+
+func TestParseFlags_PipeToStreamModes(t *testing.T) {
+	for _, mode := range [][]string{{"--stream-json"}, {"--stream-json-out"}, {"--raw-events"}, {"--blocks-json"}, {"--quiet", "--json"}} {
+		args := append(append([]string{"claude-print", "--pipe-to", "pbcopy"}, mode...), "my prompt")
+		saveAndSetArgs(t, args)
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(%q) should fail", args[1:])
+		}
+	}
+}
+
+func TestParseFlags_QuietJSON(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--quiet", "--json", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || !flags.Quiet || !flags.JSON {
+		t.Errorf("ParseFlags() = %+v, %v", flags, err)
+	}
+
+	tests := [][]string{
+		{"claude-print", "--json", "my prompt"},
+		{"claude-print", "--quiet", "--json", "--stream-json", "my prompt"},
+		{"claude-print", "--quiet", "--json", "--repl"},
+	}
+	for _, args := range tests {
+		saveAndSetArgs(t, args)
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("ParseFlags(%q) should fail", args[1:])
+		}
+	}
+}
+
+func TestParseFlags_JUnit(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--junit", "report.xml", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || flags.JUnit != "report.xml" {
+		t.Errorf("ParseFlags() = %q, %v", flags.JUnit, err)
+	}
+
+	for _, other := range []string{"--repl", "--raw-events"} {
+		saveAndSetArgs(t, []string{"claude-print", "--junit=report.xml", other, "my prompt"})
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("expected --junit with %s to be rejected", other)
+		}
+	}
+}
+
+func TestParseFlags_FailThreshold(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || flags.FailThreshold != nil {
+		t.Fatalf("expected no threshold by default, got %v, %v", flags.FailThreshold, err)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--fail-threshold", "0", "my prompt"})
+	flags, err = ParseFlags()
+	if err != nil || flags.FailThreshold == nil || *flags.FailThreshold != 0 {
+		t.Fatalf("expected a zero threshold, got %v, %v", flags.FailThreshold, err)
+	}
+
+	for _, v := range []string{"-0.1", "1.5", "half"} {
+		saveAndSetArgs(t, []string{"claude-print", "--fail-threshold=" + v, "my prompt"})
+		if _, err := ParseFlags(); err == nil {
+			t.Errorf("expected --fail-threshold=%s to be rejected", v)
+		}
+	}
+}
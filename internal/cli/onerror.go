@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Failure conditions that trigger the --on-error command.
+const (
+	OnErrorExit       = "exit"        // claude-print exits non-zero
+	OnErrorResult     = "result"      // the result event is marked as an error
+	OnErrorToolErrors = "tool-errors" // tool calls failed (more than --fail-threshold of them, if set)
+)
+
+// OnErrorConditions lists the conditions --on-error-when accepts.
+var OnErrorConditions = []string{OnErrorExit, OnErrorResult, OnErrorToolErrors}
+
+// DefaultOnErrorWhen is used when --on-error-when isn't given.
+var DefaultOnErrorWhen = []string{OnErrorExit, OnErrorResult}
+
+// ParseOnErrorWhen parses a comma-separated list of failure conditions.
+func ParseOnErrorWhen(list string) ([]string, error) {
+	var conditions []string
+	for _, field := range strings.Split(list, ",") {
+		condition := strings.TrimSpace(field)
+		if !slices.Contains(OnErrorConditions, condition) {
+			return nil, fmt.Errorf("unknown condition %q (known: %s)", condition, strings.Join(OnErrorConditions, ", "))
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
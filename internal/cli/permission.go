@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PermissionModes are the values the Claude CLI accepts for
+// --permission-mode.
+var PermissionModes = []string{"default", "acceptEdits", "plan", "bypassPermissions", "dontAsk"}
+
+// ValidatePermissionMode checks mode against PermissionModes. The Claude CLI
+// may add modes, so callers should warn rather than fail. A mode that only
+// differs in case names the intended one.
+func ValidatePermissionMode(mode string) error {
+	if slices.Contains(PermissionModes, mode) {
+		return nil
+	}
+	for _, known := range PermissionModes {
+		if strings.EqualFold(mode, known) {
+			return fmt.Errorf("unknown permission mode %q (did you mean %s?)", mode, known)
+		}
+	}
+	return fmt.Errorf("unknown permission mode %q (known: %s)", mode, strings.Join(PermissionModes, ", "))
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePermissionMode(t *testing.T) {
+	for _, mode := range PermissionModes {
+		if err := ValidatePermissionMode(mode); err != nil {
+			t.Errorf("ValidatePermissionMode(%q) = %v, want nil", mode, err)
+		}
+	}
+
+	tests := []struct {
+		mode, want string
+	}{
+		{"acceptedits", "did you mean acceptEdits?"},
+		{"PLAN", "did you mean plan?"},
+		{"yolo", "known: default, acceptEdits"},
+		{"", "unknown permission mode"},
+	}
+	for _, tt := range tests {
+		err := ValidatePermissionMode(tt.mode)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ValidatePermissionMode(%q) = %v, want error containing %q", tt.mode, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// proxyEnvNames are the variables Claude reads its proxy from, in order of
+// precedence.
+var proxyEnvNames = []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"}
+
+// ValidateProxyURL checks that s is an absolute http, https or socks5 URL
+// with a host, e.g. "http://proxy.corp:3128".
+func ValidateProxyURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("scheme must be http, https or socks5, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// ProxyAssignments returns the "KEY=VALUE" entries that route Claude's
+// traffic through proxy.
+func ProxyAssignments(proxy string) []string {
+	return []string{"HTTPS_PROXY=" + proxy, "HTTP_PROXY=" + proxy}
+}
+
+// EffectiveProxy returns the proxy Claude will use given the inherited
+// environ and the extra assignments applied over it (later entries win),
+// or "" if none is set. A password in the URL is masked for display.
+func EffectiveProxy(environ, extra []string) string {
+	values := make(map[string]string)
+	for _, kv := range append(append([]string(nil), environ...), extra...) {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			values[name] = value
+		}
+	}
+	for _, name := range proxyEnvNames {
+		if value := values[name]; value != "" {
+			if u, err := url.Parse(value); err == nil {
+				return u.Redacted()
+			}
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateProxyURL(t *testing.T) {
+	valid := []string{"http://proxy.corp:3128", "https://user:pw@proxy.corp", "socks5://127.0.0.1:1080"}
+	for _, s := range valid {
+		if err := ValidateProxyURL(s); err != nil {
+			t.Errorf("ValidateProxyURL(%q) = %v, want nil", s, err)
+		}
+	}
+	invalid := []string{"", "proxy.corp:3128", "ftp://proxy.corp", "http://", "http://[::1"}
+	for _, s := range invalid {
+		if err := ValidateProxyURL(s); err == nil {
+			t.Errorf("ValidateProxyURL(%q) = nil, want error", s)
+		}
+	}
+}
+
+func TestEffectiveProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		extra   []string
+		want    string
+	}{
+		{"none", []string{"PATH=/bin"}, nil, ""},
+		{"inherited", []string{"https_proxy=http://a:1"}, nil, "http://a:1"},
+		{"https preferred", []string{"HTTP_PROXY=http://a:1", "HTTPS_PROXY=http://b:2"}, nil, "http://b:2"},
+		{"extra wins", []string{"HTTPS_PROXY=http://a:1"}, ProxyAssignments("http://c:3"), "http://c:3"},
+		{"password masked", nil, ProxyAssignments("http://user:secret@c:3"), "http://user:xxxxx@c:3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EffectiveProxy(tt.environ, tt.extra); got != tt.want {
+				t.Errorf("EffectiveProxy = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFlags_Proxy(t *testing.T) {
+	saveAndSetArgs(t, []string{"claude-print", "--proxy", "http://proxy.corp:3128", "my prompt"})
+	flags, err := ParseFlags()
+	if err != nil || flags.Proxy != "http://proxy.corp:3128" {
+		t.Errorf("ParseFlags() = %q, %v", flags.Proxy, err)
+	}
+
+	saveAndSetArgs(t, []string{"claude-print", "--proxy=proxy.corp", "my prompt"})
+	if _, err := ParseFlags(); err == nil || !strings.Contains(err.Error(), "--proxy") {
+		t.Errorf("expected an invalid --proxy error, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// RunSpec describes a complete run in a JSON file (--run-spec), so a
+// reproducible invocation can be kept under version control instead of
+// assembled on the command line. Command-line flags override spec fields.
+type RunSpec struct {
+	Prompt         string   `json:"prompt"`
+	Model          string   `json:"model"`
+	AllowedTools   []string `json:"allowedTools"`
+	PermissionMode string   `json:"permissionMode"`
+	MaxTurns       int      `json:"maxTurns"`
+	Verbosity      string   `json:"verbosity"` // "quiet", "normal", or "verbose"
+	DebugLog       string   `json:"debugLog"`
+	NoColor        bool     `json:"noColor"`
+	NoEmoji        bool     `json:"noEmoji"`
+	Args           []string `json:"args"` // Extra args passed through to Claude unchanged
+}
+
+// LoadRunSpec reads and validates a run-spec file. Unknown fields are
+// reported as errors so typos don't silently change a run.
+func LoadRunSpec(path string) (RunSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return RunSpec{}, fmt.Errorf("failed to read run spec: %w", err)
+	}
+
+	var spec RunSpec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return RunSpec{}, fmt.Errorf("failed to parse run spec %s: %w", path, err)
+	}
+
+	if err := spec.validate(); err != nil {
+		return RunSpec{}, fmt.Errorf("invalid run spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// validate checks field values that can be checked without running Claude.
+func (s RunSpec) validate() error {
+	switch s.Verbosity {
+	case "", "quiet", "normal", "verbose":
+	default:
+		return fmt.Errorf("verbosity must be quiet, normal, or verbose, got %q", s.Verbosity)
+	}
+	if s.MaxTurns < 0 {
+		return fmt.Errorf("maxTurns must not be negative, got %d", s.MaxTurns)
+	}
+	for _, arg := range s.Args {
+		if reason, blocked := isProtectedFlag(extractFlagName(arg)); blocked {
+			return fmt.Errorf("cannot use %s in args: %s", extractFlagName(arg), reason)
+		}
+	}
+	return nil
+}
+
+// applyRunSpec fills in f from spec wherever the command line didn't
+// already set a value, recording each flag it sets in f.FromRunSpec. Claude
+// settings become passthrough args.
+func applyRunSpec(f *Flags, spec RunSpec) {
+	if f.Prompt == "" {
+		f.Prompt = spec.Prompt
+	}
+	if f.DebugLog == "" {
+		f.DebugLog = spec.DebugLog
+	}
+	if !f.NoColor && spec.NoColor {
+		f.NoColor = true
+		f.FromRunSpec = append(f.FromRunSpec, "--no-color")
+	}
+	if !f.NoEmoji && spec.NoEmoji {
+		f.NoEmoji = true
+		f.FromRunSpec = append(f.FromRunSpec, "--no-emoji")
+	}
+
+	// Verbosity applies only if neither --verbose nor --quiet was given
+	if !f.Verbose && !f.Quiet {
+		switch spec.Verbosity {
+		case "verbose":
+			f.Verbose = true
+			f.FromRunSpec = append(f.FromRunSpec, "--verbose")
+		case "quiet":
+			f.Quiet = true
+			f.FromRunSpec = append(f.FromRunSpec, "--quiet")
+		}
+	}
+
+	// Claude flags from the spec, unless already on the command line
+	addFlag := func(flag, value string) {
+		if value != "" && !HasFlag(f.PassthroughArgs, flag) {
+			f.PassthroughArgs = append(f.PassthroughArgs, flag, value)
+			f.FromRunSpec = append(f.FromRunSpec, flag)
+		}
+	}
+	addFlag("--model", spec.Model)
+	addFlag("--permission-mode", spec.PermissionMode)
+	if len(spec.AllowedTools) > 0 {
+		addFlag("--allowedTools", strings.Join(spec.AllowedTools, ","))
+	}
+	if spec.MaxTurns > 0 {
+		addFlag("--max-turns", strconv.Itoa(spec.MaxTurns))
+	}
+	f.PassthroughArgs = append(f.PassthroughArgs, spec.Args...)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSpec writes content to a temp run-spec file and returns its path.
+func writeSpec(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+	return path
+}
+
+func TestParseFlags_RunSpec(t *testing.T) {
+	path := writeSpec(t, `{
+		"prompt": "from spec",
+		"model": "sonnet",
+		"allowedTools": ["Read", "Grep"],
+		"maxTurns": 3,
+		"verbosity": "quiet"
+	}`)
+	saveAndSetArgs(t, []string{"claude-print", "--run-spec", path})
+
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.Prompt != "from spec" {
+		t.Errorf("expected Prompt from spec, got %q", flags.Prompt)
+	}
+	if !flags.Quiet {
+		t.Error("expected Quiet from spec verbosity")
+	}
+	got := strings.Join(flags.PassthroughArgs, " ")
+	want := "--model sonnet --allowedTools Read,Grep --max-turns 3"
+	if got != want {
+		t.Errorf("expected passthrough %q, got %q", want, got)
+	}
+	if got, want := strings.Join(flags.FromRunSpec, " "), "--quiet --model --allowedTools --max-turns"; got != want {
+		t.Errorf("expected FromRunSpec %q, got %q", want, got)
+	}
+}
+
+func TestParseFlags_RunSpecFlagsOverride(t *testing.T) {
+	path := writeSpec(t, `{"prompt": "from spec", "model": "sonnet", "verbosity": "quiet"}`)
+	saveAndSetArgs(t, []string{"claude-print", "--run-spec=" + path, "from cli", "--verbose", "--model", "opus"})
+
+	flags, err := ParseFlags()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.Prompt != "from cli" {
+		t.Errorf("expected command-line prompt to win, got %q", flags.Prompt)
+	}
+	if flags.Quiet || !flags.Verbose {
+		t.Errorf("expected --verbose to override spec verbosity, got verbose=%v quiet=%v", flags.Verbose, flags.Quiet)
+	}
+	if model, _ := FlagValue(flags.PassthroughArgs, "--model"); model != "opus" {
+		t.Errorf("expected --model opus to win, got %q (args %v)", model, flags.PassthroughArgs)
+	}
+	if len(flags.FromRunSpec) != 0 {
+		t.Errorf("expected nothing taken from the spec, got %v", flags.FromRunSpec)
+	}
+}
+
+func TestLoadRunSpec_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"unknown field", `{"prompt": "x", "promt": "typo"}`},
+		{"bad verbosity", `{"verbosity": "loud"}`},
+		{"protected arg", `{"args": ["--output-format=json"]}`},
+		{"malformed", `{"prompt": `},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadRunSpec(writeSpec(t, tt.content)); err == nil {
+				t.Errorf("expected error for %s", tt.name)
+			}
+		})
+	}
+}
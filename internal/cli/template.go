@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// templateVarPattern matches a {name} placeholder in a prompt template.
+// Names are identifiers, so braces in code snippets aren't mistaken for
+// placeholders.
+var templateVarPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ValidateTemplateVar checks that s has the form NAME=VALUE with NAME a
+// placeholder name (letters, digits and underscores). VALUE may be empty.
+func ValidateTemplateVar(s string) error {
+	name, _, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected NAME=VALUE")
+	}
+	if !templateVarPattern.MatchString("{" + name + "}") {
+		return fmt.Errorf("variable name %q must be letters, digits and underscores", name)
+	}
+	return nil
+}
+
+// ExpandTemplate replaces each {name} placeholder in tmpl with the value of
+// the matching NAME=VALUE assignment in vars; a later assignment of the
+// same name wins. It returns an error naming any placeholder left without a
+// value, or any variable the template doesn't use, since both are usually
+// typos.
+func ExpandTemplate(tmpl string, vars []string) (string, error) {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		name, value, _ := strings.Cut(v, "=")
+		values[name] = value
+	}
+
+	used := make(map[string]bool)
+	var missing []string
+	prompt := templateVarPattern.ReplaceAllStringFunc(tmpl, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := values[name]
+		if !ok && !used[name] {
+			missing = append(missing, placeholder)
+		}
+		used[name] = true
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no --var given for %s", strings.Join(missing, ", "))
+	}
+
+	var unused []string
+	for name := range values {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		return "", fmt.Errorf("template has no placeholder for --var %s", strings.Join(unused, ", "))
+	}
+	return prompt, nil
+}
+
+// TemplatePrompt expands the config template called name with vars (see
+// ExpandTemplate). It returns an error listing the configured templates if
+// there is none by that name.
+func TemplatePrompt(templates map[string]string, name string, vars []string) (string, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		if len(templates) == 0 {
+			return "", fmt.Errorf("unknown template %q: no templates are defined in the config file", name)
+		}
+		names := make([]string, 0, len(templates))
+		for n := range templates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("unknown template %q (known: %s)", name, strings.Join(names, ", "))
+	}
+	prompt, err := ExpandTemplate(tmpl, vars)
+	if err != nil {
+		return "", fmt.Errorf("template %q: %w", name, err)
+	}
+	return prompt, nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		vars    []string
+		want    string
+		wantErr string
+	}{
+		{"fills placeholders", "Review the file {file} for {concern}", []string{"file=x.go", "concern=security"}, "Review the file x.go for security", ""},
+		{"repeated placeholder", "{f} and {f} again", []string{"f=a"}, "a and a again", ""},
+		{"later var wins", "{f}", []string{"f=a", "f=b=c"}, "b=c", ""},
+		{"empty value", "[{f}]", []string{"f="}, "[]", ""},
+		{"code braces left alone", "Fix func() {} in {file}", []string{"file=x.go"}, "Fix func() {} in x.go", ""},
+		{"unresolved placeholders", "{a} {b} {a}", nil, "", "no --var given for {a}, {b}"},
+		{"unused var", "{a}", []string{"a=1", "typo=2"}, "", "no placeholder for --var typo"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandTemplate(tt.tmpl, tt.vars)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s: ExpandTemplate() = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestTemplatePrompt(t *testing.T) {
+	templates := map[string]string{"review": "Review {file}", "explain": "Explain {file}"}
+	if got, err := TemplatePrompt(templates, "review", []string{"file=x.go"}); err != nil || got != "Review x.go" {
+		t.Errorf("TemplatePrompt() = %q, %v", got, err)
+	}
+	if _, err := TemplatePrompt(templates, "revew", nil); err == nil || !strings.Contains(err.Error(), "known: explain, review") {
+		t.Errorf("expected an unknown template error listing the templates, got %v", err)
+	}
+	if _, err := TemplatePrompt(nil, "review", nil); err == nil || !strings.Contains(err.Error(), "no templates") {
+		t.Errorf("expected a no templates error, got %v", err)
+	}
+	if _, err := TemplatePrompt(templates, "review", nil); err == nil || !strings.Contains(err.Error(), `template "review"`) {
+		t.Errorf("expected the template name in the error, got %v", err)
+	}
+}
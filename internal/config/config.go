@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
 )
 
 const configFileName = ".claude-print-config.json"
@@ -16,6 +20,81 @@ type Config struct {
 	DefaultVerbosity string `json:"defaultVerbosity"`
 	ColorEnabled     bool   `json:"colorEnabled"`
 	EmojiEnabled     bool   `json:"emojiEnabled"`
+	// StreamFlushMS coalesces streamed text and flushes it every N milliseconds.
+	// Zero (the default) writes each delta immediately.
+	StreamFlushMS int `json:"streamFlushMS,omitempty"`
+	// WarnCostUSD and WarnDurationMS highlight the summary's cost and total
+	// duration when a run exceeds them. Zero (the default) disables each.
+	WarnCostUSD    float64 `json:"warnCostUSD,omitempty"`
+	WarnDurationMS int64   `json:"warnDurationMS,omitempty"`
+	// VerboseMatchLimit caps the Grep/Glob matches listed in verbose mode.
+	// Zero uses the built-in default.
+	VerboseMatchLimit int `json:"verboseMatchLimit,omitempty"`
+	// IndentWidth is the number of spaces per nesting level in detail lines
+	// (tool parameters, verbose tool output, metadata, statistics). Zero
+	// uses the built-in default (2).
+	IndentWidth int `json:"indentWidth,omitempty"`
+	// MaxToolParamBytes caps each stored/displayed tool parameter value.
+	// Zero uses the built-in default (64 KiB).
+	MaxToolParamBytes int `json:"maxToolParamBytes,omitempty"`
+	// BlocksSpillBytes moves the blocks recorded for --blocks-json to a temp
+	// file once they exceed this many bytes. Zero (the default) keeps them
+	// in memory.
+	BlocksSpillBytes int `json:"blocksSpillBytes,omitempty"`
+	// LoopGuard aborts a session when the same tool call repeats more than
+	// this many times in a row. Zero (the default) disables the guard.
+	LoopGuard int `json:"loopGuard,omitempty"`
+	// ConfirmCostUSD asks whether to continue each time a run's estimated
+	// cost passes another multiple of it (interactive terminals only).
+	// Zero (the default) disables the prompt.
+	ConfirmCostUSD float64 `json:"confirmCostUSD,omitempty"`
+	// DangerousPatterns are regular expressions for Bash commands to flag
+	// with a warning. When set they replace the built-in list.
+	DangerousPatterns []string `json:"dangerousPatterns,omitempty"`
+	// PromptFlag and RequiredFlags adapt claude-print to Claude-compatible
+	// CLIs with a different dialect. Empty values keep the Claude CLI
+	// defaults ("-p" and the stream-json flags).
+	PromptFlag    string   `json:"promptFlag,omitempty"`
+	RequiredFlags []string `json:"requiredFlags,omitempty"`
+	// SummaryTemplate formats the completion line. See SummaryPlaceholders;
+	// empty uses the built-in format.
+	SummaryTemplate string `json:"summaryTemplate,omitempty"`
+	// SummaryFields selects and orders the fields of the completion line
+	// (see SummaryFieldNames); empty uses DefaultSummaryFields. It cannot be
+	// combined with SummaryTemplate.
+	SummaryFields []string `json:"summaryFields,omitempty"`
+	// ShowCachedTokens shows how many of the summary's input tokens were
+	// cache reads, e.g. "1200 in (900 cached)".
+	ShowCachedTokens bool `json:"showCachedTokens,omitempty"`
+	// DefaultModel is passed as --model when the user doesn't pass one.
+	DefaultModel string `json:"defaultModel,omitempty"`
+	// DefaultMaxTurns is passed as --max-turns when the user doesn't pass
+	// one. Zero (the default) leaves runs unbounded.
+	DefaultMaxTurns int `json:"defaultMaxTurns,omitempty"`
+	// AssistantLabel and ToolLabel customize the speaker labels shown with
+	// --labels. "{tool}" in ToolLabel is replaced by the tool name.
+	AssistantLabel string `json:"assistantLabel,omitempty"`
+	ToolLabel      string `json:"toolLabel,omitempty"`
+	// ShowMetadata shows a one-line session summary in normal mode.
+	ShowMetadata bool `json:"showMetadata,omitempty"`
+	// QuietSpinner shows a spinner on stderr while quiet mode is waiting.
+	QuietSpinner bool `json:"quietSpinner,omitempty"`
+	// ThinkingDelayMS is how long the event stream must be idle in normal
+	// mode before a "thinking..." placeholder appears. Zero uses the
+	// built-in default (1000); a negative value disables the placeholder.
+	ThinkingDelayMS int `json:"thinkingDelayMS,omitempty"`
+	// OnEdit is a command run on each file Claude writes or edits, with
+	// "{file}" replaced by its path. --on-edit overrides it.
+	OnEdit string `json:"onEdit,omitempty"`
+	// Env sets extra environment variables for the Claude process. --env
+	// flags override entries with the same name.
+	Env map[string]string `json:"env,omitempty"`
+	// Templates are named prompt templates for --template. "{name}"
+	// placeholders are filled from --var name=value.
+	Templates map[string]string `json:"templates,omitempty"`
+	// EmojiSet replaces the glyph shown for each role in EmojiRoles. Roles
+	// left out keep the built-in emoji; an empty value shows none.
+	EmojiSet map[string]string `json:"emojiSet,omitempty"`
 }
 
 // DefaultConfig returns a Config with sensible default values.
@@ -28,18 +107,39 @@ func DefaultConfig() Config {
 	}
 }
 
+// ErrNoHomeDir is returned when the user's home directory cannot be
+// determined (e.g. $HOME is unset in a container). LoadConfig still returns
+// DefaultConfig() alongside it, so callers can treat it as a warning.
+var ErrNoHomeDir = errors.New("home directory unavailable")
+
+// ErrInsecurePermissions is returned when the config file is writable by its
+// group or by everyone. Since claudePath names the program that gets run,
+// anyone able to edit the file could substitute their own. LoadConfig still
+// returns the loaded config alongside it, so callers can treat it as a
+// warning.
+var ErrInsecurePermissions = errors.New("config file is writable by other users")
+
 // getConfigPath returns the full path to the config file in the user's home directory.
 func getConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+	if err != nil || homeDir == "" {
+		return "", fmt.Errorf("%w: %v", ErrNoHomeDir, err)
 	}
 	return filepath.Join(homeDir, configFileName), nil
 }
 
+// FilePath returns the full path to the config file.
+func FilePath() (string, error) {
+	return getConfigPath()
+}
+
 // LoadConfig reads the config from ~/.claude-print-config.json.
 // If the file doesn't exist, it returns a default config.
 // If the file exists but contains invalid JSON, it returns an error.
+// If the home directory is unavailable, it returns a default config and an
+// error wrapping ErrNoHomeDir. If the file is group- or world-writable (Unix
+// only), it returns the loaded config and an error wrapping
+// ErrInsecurePermissions.
 func LoadConfig() (Config, error) {
 	configPath, err := getConfigPath()
 	if err != nil {
@@ -59,10 +159,35 @@ func LoadConfig() (Config, error) {
 		return DefaultConfig(), fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
 
+	if err := ValidateSummaryTemplate(cfg.SummaryTemplate); err != nil {
+		return DefaultConfig(), fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+
+	if err := ValidateSummaryFields(cfg.SummaryFields); err != nil {
+		return DefaultConfig(), fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	if len(cfg.SummaryFields) > 0 && cfg.SummaryTemplate != "" {
+		return DefaultConfig(), fmt.Errorf("invalid config file %s: summaryFields and summaryTemplate cannot both be set", configPath)
+	}
+
+	if err := ValidateEmojiSet(cfg.EmojiSet); err != nil {
+		return DefaultConfig(), fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+
+	if err := ValidateIndentWidth(cfg.IndentWidth); err != nil {
+		return DefaultConfig(), fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+
+	if info, err := os.Stat(configPath); err == nil && isWritableByOthers(info) {
+		return cfg, fmt.Errorf("%w: %s has mode %04o; run chmod 600 %s",
+			ErrInsecurePermissions, configPath, info.Mode().Perm(), configPath)
+	}
+
 	return cfg, nil
 }
 
 // SaveConfig writes the config to ~/.claude-print-config.json.
+// It returns an error wrapping ErrNoHomeDir if there is no home directory.
 func SaveConfig(cfg Config) error {
 	configPath, err := getConfigPath()
 	if err != nil {
@@ -81,8 +206,129 @@ func SaveConfig(cfg Config) error {
 	return nil
 }
 
+// ValidateModelName checks that a configured model name is plausible: an
+// alias like "sonnet" or a full name like "claude-sonnet-4-20250514".
+// An empty name is valid and means "no default".
+func ValidateModelName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if !modelNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid defaultModel %q in ~/%s: expected a model alias or name such as \"sonnet\" or \"claude-sonnet-4-20250514\"", name, configFileName)
+	}
+	return nil
+}
+
+// modelNamePattern matches model aliases and names: letters, digits, and
+// . _ - : [ ] / @ separators, starting with a letter.
+var modelNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._:\[\]/@-]*$`)
+
+// SummaryPlaceholders are the placeholders accepted in summaryTemplate.
+var SummaryPlaceholders = []string{"status", "turns", "cost", "total_duration", "api_duration", "in", "out"}
+
+// PlaceholderPattern matches a {name} placeholder in a summary template; the
+// first submatch is the name. Validation and rendering both use it, so they
+// agree on what a placeholder is.
+var PlaceholderPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// ValidateSummaryTemplate checks that a summary template only uses known
+// placeholders. An empty template is valid and selects the default format.
+func ValidateSummaryTemplate(tmpl string) error {
+	for _, m := range PlaceholderPattern.FindAllStringSubmatch(tmpl, -1) {
+		if !slices.Contains(SummaryPlaceholders, m[1]) {
+			return fmt.Errorf("unknown placeholder %s in summaryTemplate (known: {%s})", m[0], strings.Join(SummaryPlaceholders, "}, {"))
+		}
+	}
+	return nil
+}
+
+// SummaryFieldNames are the fields accepted in summaryFields.
+var SummaryFieldNames = []string{"turns", "duration", "total_duration", "api_duration", "tokens", "cache", "tools", "cost"}
+
+// DefaultSummaryFields are the completion line fields shown when
+// summaryFields is unset.
+var DefaultSummaryFields = []string{"turns", "duration", "tokens", "cost"}
+
+// ValidateSummaryFields checks that summaryFields only names known fields,
+// each at most once. An empty list is valid and selects the default fields.
+func ValidateSummaryFields(fields []string) error {
+	for i, field := range fields {
+		if !slices.Contains(SummaryFieldNames, field) {
+			return fmt.Errorf("unknown field %q in summaryFields (known: %s)", field, strings.Join(SummaryFieldNames, ", "))
+		}
+		if slices.Contains(fields[:i], field) {
+			return fmt.Errorf("field %q appears more than once in summaryFields", field)
+		}
+	}
+	return nil
+}
+
+// MaxIndentWidth is the largest indentWidth accepted.
+const MaxIndentWidth = 8
+
+// ValidateIndentWidth checks that an indentWidth is between 0 (the default)
+// and MaxIndentWidth.
+func ValidateIndentWidth(width int) error {
+	if width < 0 || width > MaxIndentWidth {
+		return fmt.Errorf("indentWidth %d must be between 1 and %d (0 uses the default)", width, MaxIndentWidth)
+	}
+	return nil
+}
+
+// EmojiRoles are the keys accepted in an emojiSet.
+var EmojiRoles = []string{"error", "warning", "success", "tool", "info"}
+
+// ValidateEmojiSet checks that an emoji set only uses known roles and that
+// each glyph is a single grapheme, so that it keeps lines aligned where it
+// replaces the one-column tool bullet.
+func ValidateEmojiSet(set map[string]string) error {
+	for role, glyph := range set {
+		if !slices.Contains(EmojiRoles, role) {
+			return fmt.Errorf("unknown role %q in emojiSet (known: %s)", role, strings.Join(EmojiRoles, ", "))
+		}
+		if glyph != "" && graphemeCount(glyph) != 1 {
+			return fmt.Errorf("emojiSet %s must be a single character, got %q", role, glyph)
+		}
+	}
+	return nil
+}
+
+// graphemeCount approximates the number of user-perceived characters in s.
+// Combining marks, variation selectors and skin-tone modifiers attach to the
+// preceding character, as does anything joined to it with a zero-width joiner.
+func graphemeCount(s string) int {
+	count := 0
+	joined := false
+	for _, r := range s {
+		switch {
+		case r == '\u200d':
+			joined = true
+			continue
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Variation_Selector),
+			r >= 0x1F3FB && r <= 0x1F3FF:
+		case joined && count > 0:
+		default:
+			count++
+		}
+		joined = false
+	}
+	return count
+}
+
+// NotExecutableError is returned by ValidatePath when the Claude CLI file
+// exists but cannot be executed.
+type NotExecutableError struct {
+	Path string
+}
+
+func (e *NotExecutableError) Error() string {
+	return fmt.Sprintf("Claude CLI at %s is not executable (chmod +x?)", e.Path)
+}
+
 // ValidatePath checks if the given path points to a valid executable file.
-// It returns an error if the path doesn't exist or is a directory.
+// It returns an error if the path doesn't exist or is a directory, and a
+// *NotExecutableError if the file lacks execute permission (Unix) or an
+// executable extension (Windows).
 func ValidatePath(path string) error {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -96,5 +342,9 @@ func ValidatePath(path string) error {
 		return fmt.Errorf("Claude CLI not found at %s. Please update ~/%s or delete it to auto-detect", path, configFileName)
 	}
 
+	if !isExecutable(path, info) {
+		return &NotExecutableError{Path: path}
+	}
+
 	return nil
 }
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestValidatePath(t *testing.T) {
+	dir := t.TempDir()
+
+	name := "claude"
+	if runtime.GOOS == "windows" {
+		name = "claude.exe"
+	}
+	exe := filepath.Join(dir, name)
+	if err := os.WriteFile(exe, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidatePath(exe); err != nil {
+		t.Errorf("expected executable file to validate, got %v", err)
+	}
+
+	if err := ValidatePath(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	if err := ValidatePath(dir); err == nil {
+		t.Error("expected error for directory")
+	}
+}
+
+func TestValidatePath_NotExecutable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude.txt")
+	if err := os.WriteFile(path, []byte("not a program"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ValidatePath(path)
+	var notExec *NotExecutableError
+	if !errors.As(err, &notExec) {
+		t.Fatalf("expected *NotExecutableError, got %v", err)
+	}
+	if notExec.Path != path {
+		t.Errorf("Path = %q, want %q", notExec.Path, path)
+	}
+	if !strings.Contains(err.Error(), "not executable") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestValidateSummaryTemplate(t *testing.T) {
+	valid := []string{"", "{status}: {turns} turns, {cost}", "{in}/{out} in {total_duration} ({api_duration})"}
+	for _, tmpl := range valid {
+		if err := ValidateSummaryTemplate(tmpl); err != nil {
+			t.Errorf("ValidateSummaryTemplate(%q) = %v, want nil", tmpl, err)
+		}
+	}
+
+	err := ValidateSummaryTemplate("{turns} turns, {tokens} tokens")
+	if err == nil || !strings.Contains(err.Error(), "{tokens}") {
+		t.Errorf("expected error naming {tokens}, got %v", err)
+	}
+}
+
+func TestValidateSummaryFields(t *testing.T) {
+	for _, fields := range [][]string{nil, {"cost"}, {"cost", "turns", "cache", "tools"}, SummaryFieldNames} {
+		if err := ValidateSummaryFields(fields); err != nil {
+			t.Errorf("ValidateSummaryFields(%v) = %v, want nil", fields, err)
+		}
+	}
+	if err := ValidateSummaryFields([]string{"cost", "tokenz"}); err == nil || !strings.Contains(err.Error(), `"tokenz"`) {
+		t.Errorf("expected error naming tokenz, got %v", err)
+	}
+	if err := ValidateSummaryFields([]string{"cost", "cost"}); err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Errorf("expected a duplicate field error, got %v", err)
+	}
+}
+
+func TestValidateEmojiSet(t *testing.T) {
+	valid := map[string]string{"error": "✗", "warning": "⚠️", "success": "👍🏽", "tool": "👩‍💻"}
+	if err := ValidateEmojiSet(map[string]string{"tool": "", "info": "i"}); err != nil {
+		t.Errorf("an empty glyph and the info role should be valid, got %v", err)
+	}
+	if err := ValidateEmojiSet(valid); err != nil {
+		t.Errorf("ValidateEmojiSet(%v) = %v, want nil", valid, err)
+	}
+
+	err := ValidateEmojiSet(map[string]string{"debug": "*"})
+	if err == nil || !strings.Contains(err.Error(), `"debug"`) {
+		t.Errorf("expected error naming the unknown role, got %v", err)
+	}
+
+	err = ValidateEmojiSet(map[string]string{"tool": "->"})
+	if err == nil || !strings.Contains(err.Error(), "single character") {
+		t.Errorf("expected single character error, got %v", err)
+	}
+}
+
+func TestValidateIndentWidth(t *testing.T) {
+	for _, width := range []int{0, 1, 4, MaxIndentWidth} {
+		if err := ValidateIndentWidth(width); err != nil {
+			t.Errorf("ValidateIndentWidth(%d) = %v, want nil", width, err)
+		}
+	}
+	for _, width := range []int{-1, MaxIndentWidth + 1} {
+		if err := ValidateIndentWidth(width); err == nil {
+			t.Errorf("ValidateIndentWidth(%d) should fail", width)
+		}
+	}
+}
+
+func TestLoadConfig_NoHomeDir(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	t.Setenv("home", "")
+
+	cfg, err := LoadConfig()
+	if !errors.Is(err, ErrNoHomeDir) {
+		t.Fatalf("expected ErrNoHomeDir, got %v", err)
+	}
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("expected default config, got %+v", cfg)
+	}
+
+	if err := SaveConfig(cfg); !errors.Is(err, ErrNoHomeDir) {
+		t.Errorf("expected SaveConfig to fail with ErrNoHomeDir, got %v", err)
+	}
+}
+
+func TestLoadConfig_WorldWritable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not checked on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, configFileName)
+	if err := os.WriteFile(path, []byte(`{"claudePath": "/usr/bin/claude"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadConfig(); err != nil {
+		t.Fatalf("expected a 0600 config to load cleanly, got %v", err)
+	}
+
+	// Chmod explicitly: WriteFile's mode is filtered by the umask
+	if err := os.Chmod(path, 0666); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig()
+	if !errors.Is(err, ErrInsecurePermissions) {
+		t.Fatalf("expected ErrInsecurePermissions, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "chmod 600") {
+		t.Errorf("expected a chmod 600 hint, got %v", err)
+	}
+	if cfg.ClaudePath != "/usr/bin/claude" {
+		t.Errorf("expected the loaded config alongside the warning, got %+v", cfg)
+	}
+}
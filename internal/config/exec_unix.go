@@ -0,0 +1,10 @@
+//go:build !windows
+
+package config
+
+import "os"
+
+// isExecutable reports whether any execute permission bit is set.
+func isExecutable(path string, info os.FileInfo) bool {
+	return info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isExecutable reports whether path has an extension Windows can execute.
+func isExecutable(path string, info os.FileInfo) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".exe", ".cmd", ".bat", ".com":
+		return true
+	}
+	return false
+}
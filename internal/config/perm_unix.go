@@ -0,0 +1,10 @@
+//go:build !windows
+
+package config
+
+import "os"
+
+// isWritableByOthers reports whether the group or world write bit is set.
+func isWritableByOthers(info os.FileInfo) bool {
+	return info.Mode().Perm()&0022 != 0
+}
@@ -0,0 +1,11 @@
+//go:build windows
+
+package config
+
+import "os"
+
+// isWritableByOthers always reports false: Windows access is governed by
+// ACLs, which Unix mode bits don't reflect.
+func isWritableByOthers(info os.FileInfo) bool {
+	return false
+}
@@ -42,3 +42,56 @@ func DetectClaudePath() (string, error) {
 
 	return path, nil
 }
+
+// MinClaudeVersion is the oldest Claude CLI version known to support the
+// stream-json output with partial messages that claude-print depends on.
+const MinClaudeVersion = "1.0.0"
+
+// ClaudeVersion runs '<claudePath> --version' and returns the version number
+// from its output (e.g. "1.0.33" from "1.0.33 (Claude Code)").
+func ClaudeVersion(claudePath string) (string, error) {
+	output, err := exec.Command(claudePath, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s --version: %w", claudePath, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty version output from %s", claudePath)
+	}
+	return strings.TrimPrefix(fields[0], "v"), nil
+}
+
+// CompareVersions compares two dotted version strings numerically.
+// Returns -1 if a < b, 0 if equal, and 1 if a > b. Missing or non-numeric
+// components are treated as 0.
+func CompareVersions(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		na, nb := versionPart(pa, i), versionPart(pb, i)
+		if na < nb {
+			return -1
+		}
+		if na > nb {
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionPart returns the numeric value of the i-th version component,
+// ignoring any non-digit suffix (e.g. "3-beta" -> 3).
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n := 0
+	for _, r := range parts[i] {
+		if r < '0' || r > '9' {
+			break
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
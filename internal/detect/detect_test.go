@@ -0,0 +1,24 @@
+package detect
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.33", "1.0.0", 1},
+		{"0.9.9", "1.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2", "1.99.99", 1},
+		{"1.0", "1.0.0", 0},
+		{"1.2.3-beta", "1.2.3", 0},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
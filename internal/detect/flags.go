@@ -0,0 +1,81 @@
+package detect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// helpTimeout bounds how long MissingFlags waits for '<claudePath> --help'.
+const helpTimeout = 10 * time.Second
+
+// helpResult is the cached outcome of running '<claudePath> --help'.
+type helpResult struct {
+	text string
+	err  error
+}
+
+// helpCache holds each Claude CLI's help output for the life of the
+// process, so batch, watch and REPL runs check their flags only once.
+var (
+	helpMu    sync.Mutex
+	helpCache = map[string]helpResult{}
+)
+
+// errNoFlagsInHelp is returned when the help output doesn't look like a
+// flag listing, e.g. from a wrapper script that ignores --help.
+var errNoFlagsInHelp = errors.New("help output lists no flags")
+
+// claudeHelp returns the output of '<claudePath> --help', running it only
+// the first time for each path.
+func claudeHelp(claudePath string) (string, error) {
+	helpMu.Lock()
+	defer helpMu.Unlock()
+	if cached, ok := helpCache[claudePath]; ok {
+		return cached.text, cached.err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), helpTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, claudePath, "--help").Output()
+	result := helpResult{text: string(output)}
+	switch {
+	case err != nil:
+		result.err = fmt.Errorf("failed to run %s --help: %w", claudePath, err)
+	case !strings.Contains(result.text, "--"):
+		result.err = errNoFlagsInHelp
+	}
+	helpCache[claudePath] = result
+	return result.text, result.err
+}
+
+// MissingFlags checks the flags in args against the Claude CLI's --help
+// output and returns those it doesn't list. A "--flag=value" arg also needs
+// its value to appear in the help (e.g. "stream-json" among the
+// --output-format choices). Args that don't start with '-' are skipped.
+// Returns an error, and no verdict, if the help can't be run or lists no
+// flags at all.
+func MissingFlags(claudePath string, args []string) ([]string, error) {
+	help, err := claudeHelp(claudePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, _ := strings.Cut(arg, "=")
+		listed := regexp.MustCompile(`(^|[\s,])` + regexp.QuoteMeta(name) + `($|[\s,=<\[])`).MatchString(help)
+		if !listed || !strings.Contains(help, value) {
+			missing = append(missing, arg)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,58 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// fakeClaude writes a shell script that prints help as its --help output.
+func fakeClaude(t *testing.T, help string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the Claude CLI")
+	}
+	path := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\ncat <<'EOF'\n"+help+"\nEOF\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMissingFlags(t *testing.T) {
+	required := []string{"--include-partial-messages", "--verbose", "--output-format=stream-json"}
+
+	current := fakeClaude(t, `Options:
+  -p, --print                      Print response and exit
+  --verbose                        Override verbose mode setting
+  --output-format <format>         Output format (choices: "text", "json", "stream-json")
+  --include-partial-messages       Include partial message chunks`)
+	if missing, err := MissingFlags(current, required); err != nil || len(missing) != 0 {
+		t.Errorf("MissingFlags(current) = %v, %v; want none", missing, err)
+	}
+
+	old := fakeClaude(t, `Options:
+  -p, --print                      Print response and exit
+  --verbose-output                 Not the same flag
+  --output-format <format>         Output format (choices: "text", "json")`)
+	missing, err := MissingFlags(old, required)
+	if want := required; err != nil || !slices.Equal(missing, want) {
+		t.Errorf("MissingFlags(old) = %v, %v; want %v", missing, err, want)
+	}
+
+	// The help is cached: a changed script keeps its first answer
+	if err := os.WriteFile(old, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if again, err := MissingFlags(old, required); err != nil || !slices.Equal(again, missing) {
+		t.Errorf("expected the cached help to be reused, got %v, %v", again, err)
+	}
+
+	// Output that isn't a flag listing gives no verdict
+	wrapper := fakeClaude(t, `{"type":"result","subtype":"success"}`)
+	if _, err := MissingFlags(wrapper, required); err == nil {
+		t.Error("expected an error for help output without flags")
+	}
+}
@@ -0,0 +1,46 @@
+package detect
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitInfo identifies the state of a git working tree.
+type GitInfo struct {
+	Branch string // Current branch, or "(detached)" for a detached HEAD
+	Commit string // Abbreviated HEAD commit hash
+}
+
+// String formats the info as "branch @ commit".
+func (g GitInfo) String() string {
+	return g.Branch + " @ " + g.Commit
+}
+
+// GitContext returns the branch and short commit of the git repository
+// containing dir. It returns an error if git is unavailable, dir is not in a
+// repository, or the repository has no commits yet.
+func GitContext(dir string) (GitInfo, error) {
+	commit, err := gitOutput(dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return GitInfo{}, err
+	}
+	branch, err := gitOutput(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return GitInfo{}, err
+	}
+	if branch == "HEAD" {
+		branch = "(detached)"
+	}
+	return GitInfo{Branch: branch, Commit: commit}, nil
+}
+
+// gitOutput runs git with args in dir and returns its trimmed stdout.
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,35 @@
+package detect
+
+import (
+	"os/exec"
+	"regexp"
+	"testing"
+)
+
+func TestGitContext(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+
+	if _, err := GitContext(dir); err == nil {
+		t.Error("expected an error outside a git repository")
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "feature"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Skipf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	info, err := GitContext(dir)
+	if err != nil {
+		t.Fatalf("GitContext: %v", err)
+	}
+	if info.Branch != "feature" || !regexp.MustCompile(`^[0-9a-f]{7,}$`).MatchString(info.Commit) {
+		t.Errorf("unexpected git info %+v", info)
+	}
+}
@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Event is an interface that all event types implement.
@@ -68,15 +69,13 @@ func ParseEvent(jsonStr string) (Event, error) {
 		return nil, fmt.Errorf("missing 'type' field in JSON")
 	}
 
+	// System events, including "system.<kind>" types added by newer CLIs
+	if isSystemEventType(base.Type) {
+		return parseSystemEvent(jsonStr)
+	}
+
 	// Parse into the appropriate struct based on type
 	switch base.Type {
-	case "system.init", "hook_started", "hook_response":
-		var event SystemEvent
-		if err := json.Unmarshal([]byte(jsonStr), &event); err != nil {
-			return nil, fmt.Errorf("failed to parse system event: %w", err)
-		}
-		return event, nil
-
 	case "stream_event":
 		var event StreamEvent
 		if err := json.Unmarshal([]byte(jsonStr), &event); err != nil {
@@ -125,6 +124,28 @@ func ParseEvent(jsonStr string) (Event, error) {
 	}
 }
 
+// isSystemEventType reports whether an event type is parsed as a SystemEvent.
+func isSystemEventType(eventType string) bool {
+	switch eventType {
+	case "system", "hook_started", "hook_response":
+		return true
+	}
+	return strings.HasPrefix(eventType, "system.")
+}
+
+// parseSystemEvent parses a SystemEvent, keeping all raw top-level fields in
+// Fields for events without a dedicated renderer.
+func parseSystemEvent(jsonStr string) (Event, error) {
+	var event SystemEvent
+	if err := json.Unmarshal([]byte(jsonStr), &event); err != nil {
+		return nil, fmt.Errorf("failed to parse system event: %w", err)
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &event.Fields); err != nil {
+		return nil, fmt.Errorf("failed to parse system event: %w", err)
+	}
+	return event, nil
+}
+
 // GetStreamEventType returns the nested event type for a StreamEvent.
 // For example, a StreamEvent may contain a "message_start", "content_block_delta", etc.
 func GetStreamEventType(event StreamEvent) string {
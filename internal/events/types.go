@@ -1,6 +1,9 @@
 package events
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strings"
+)
 
 // BaseEvent represents the base structure for all Claude streaming events.
 // All events have a Type field that identifies the event type.
@@ -8,19 +11,46 @@ type BaseEvent struct {
 	Type string `json:"type"`
 }
 
-// SystemEvent represents system-level events like system.init, hook_started, hook_response.
+// SystemEvent represents system-level events like system.init, hook_started,
+// hook_response, and "system" events with a subtype (e.g. compact_boundary).
 type SystemEvent struct {
 	BaseEvent
-	SessionID      string            `json:"session_id,omitempty"`
-	Tools          []ToolInfo        `json:"tools,omitempty"`
-	McpServers     []MCPServerInfo   `json:"mcp_servers,omitempty"`
-	Model          string            `json:"model,omitempty"`
-	Cwd            string            `json:"cwd,omitempty"`
-	HookName       string            `json:"hook_name,omitempty"`
-	HookType       string            `json:"hook_type,omitempty"`
-	TriggeringTool string            `json:"triggering_tool,omitempty"`
-	Response       string            `json:"response,omitempty"`
-	Metadata       map[string]string `json:"metadata,omitempty"`
+	Subtype         string                 `json:"subtype,omitempty"`
+	CompactMetadata *CompactMetadata       `json:"compact_metadata,omitempty"`
+	SessionID       string                 `json:"session_id,omitempty"`
+	Tools           []ToolInfo             `json:"tools,omitempty"`
+	McpServers      []MCPServerInfo        `json:"mcp_servers,omitempty"`
+	Model           string                 `json:"model,omitempty"`
+	Cwd             string                 `json:"cwd,omitempty"`
+	HookName        string                 `json:"hook_name,omitempty"`
+	HookType        string                 `json:"hook_type,omitempty"`
+	TriggeringTool  string                 `json:"triggering_tool,omitempty"`
+	Response        string                 `json:"response,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+
+	// Fields holds every top-level field of the raw event, so system events
+	// without a dedicated renderer can still be shown.
+	Fields map[string]interface{} `json:"-"`
+}
+
+// CompactMetadata describes a context-compaction boundary.
+type CompactMetadata struct {
+	Trigger   string `json:"trigger,omitempty"`    // "auto" or "manual"
+	PreTokens int    `json:"pre_tokens,omitempty"` // Context size before compaction
+}
+
+// Kind returns the normalized kind of a system event: the subtype of a
+// "system" event (e.g. "init", "compact_boundary"), the suffix of a
+// "system.<kind>" type (e.g. "system.init" is "init"), or the type itself
+// for the other forms.
+func (e SystemEvent) Kind() string {
+	if e.Type == "system" {
+		return e.Subtype
+	}
+	if kind, ok := strings.CutPrefix(e.Type, "system."); ok {
+		return kind
+	}
+	return e.Type
 }
 
 // ToolInfo represents information about an available tool.
@@ -29,6 +59,18 @@ type ToolInfo struct {
 	Description string `json:"description,omitempty"`
 }
 
+// UnmarshalJSON accepts a tool as either a bare name (as the Claude CLI's
+// init event lists them) or an object with name and description.
+func (t *ToolInfo) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = ToolInfo{Name: name}
+		return nil
+	}
+	type toolInfo ToolInfo // no UnmarshalJSON method, so no recursion
+	return json.Unmarshal(data, (*toolInfo)(t))
+}
+
 // MCPServerInfo represents information about an MCP server.
 type MCPServerInfo struct {
 	Name   string `json:"name"`
@@ -40,6 +82,16 @@ type MCPServerInfo struct {
 type StreamEvent struct {
 	BaseEvent
 	Event MessageEvent `json:"event,omitempty"`
+	Correlation
+}
+
+// Correlation holds the fields that tie an event into the conversation.
+// ParentToolUseID is set on events produced by a sub-agent: it is the ID
+// of the Task tool_use that started the agent. It is empty for the main
+// conversation.
+type Correlation struct {
+	UUID            string `json:"uuid,omitempty"`
+	ParentToolUseID string `json:"parent_tool_use_id,omitempty"`
 }
 
 // MessageEvent represents events within a stream (message_start, content_block_delta, etc.).
@@ -196,7 +248,13 @@ type ToolUseResult struct {
 	Type   string      `json:"type,omitempty"`
 	File   *FileResult `json:"file,omitempty"`
 	Status string      `json:"status,omitempty"` // For Task agent results: "completed"
-	// Future: add GlobResult, GrepResult, BashResult as discovered
+	// Bash results: the command's separate output streams and, when the
+	// CLI reports it, its exit code (nil if not reported)
+	Stdout      string `json:"stdout,omitempty"`
+	Stderr      string `json:"stderr,omitempty"`
+	Interrupted bool   `json:"interrupted,omitempty"`
+	ExitCode    *int   `json:"exitCode,omitempty"`
+	// Future: add GlobResult, GrepResult as discovered
 }
 
 // FileResult contains metadata for Read tool results
@@ -213,6 +271,7 @@ type UserEvent struct {
 	BaseEvent
 	Message       UserMessageContentBlocks `json:"message"`
 	ToolUseResult *ToolUseResult           `json:"tool_use_result,omitempty"`
+	Correlation
 }
 
 // UserMessageContentBlocks handles the user message structure with content blocks
@@ -225,6 +284,7 @@ type UserMessageContentBlocks struct {
 type AssistantEvent struct {
 	BaseEvent
 	Message Message `json:"message"`
+	Correlation
 }
 
 // userEventRaw is used for initial unmarshaling before handling polymorphic fields
@@ -232,6 +292,7 @@ type userEventRaw struct {
 	BaseEvent
 	Message          json.RawMessage `json:"message"`
 	ToolUseResultRaw json.RawMessage `json:"tool_use_result,omitempty"`
+	Correlation
 }
 
 // UnmarshalJSON handles polymorphic tool_use_result field (string or object)
@@ -242,6 +303,7 @@ func (u *UserEvent) UnmarshalJSON(data []byte) error {
 	}
 
 	u.Type = raw.Type
+	u.Correlation = raw.Correlation
 
 	// Parse message (standard structure)
 	if len(raw.Message) > 0 {
@@ -294,6 +356,11 @@ func (cb *ContentBlock) parseContent() error {
 	// Try array of content blocks (Task agent results)
 	var blocks []ContentBlock
 	if err := json.Unmarshal(cb.Content, &blocks); err == nil {
+		// Nested tool_result blocks (a sub-agent's own tools) need the same
+		// treatment, to any depth
+		for i := range blocks {
+			_ = blocks[i].parseContent()
+		}
 		cb.ContentBlocks = blocks
 		// Also set ContentString to first text block for convenience
 		for _, block := range blocks {
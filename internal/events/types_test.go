@@ -121,3 +121,104 @@ func TestUserEventUnmarshal_ContentArray(t *testing.T) {
 		t.Errorf("ContentString should be set to first text block: %s", block.ContentString)
 	}
 }
+
+func TestParseEvent_SystemSubtypes(t *testing.T) {
+	tests := []struct {
+		json string
+		kind string
+	}{
+		{`{"type":"system","subtype":"init","model":"sonnet"}`, "init"},
+		{`{"type":"system.init","model":"sonnet"}`, "init"},
+		{`{"type":"hook_started","hook_name":"fmt"}`, "hook_started"},
+		{`{"type":"system.notice","message":"hello"}`, "notice"},
+		{`{"type":"system","subtype":"status","metadata":{"count":3}}`, "status"},
+		{`{"type":"system","subtype":"compact_boundary","compact_metadata":{"trigger":"auto","pre_tokens":150000}}`, "compact_boundary"},
+	}
+
+	for _, tt := range tests {
+		event, err := ParseEvent(tt.json)
+		if err != nil {
+			t.Fatalf("ParseEvent(%s): %v", tt.json, err)
+		}
+		sys, ok := event.(SystemEvent)
+		if !ok {
+			t.Fatalf("ParseEvent(%s): expected SystemEvent, got %T", tt.json, event)
+		}
+		if sys.Kind() != tt.kind {
+			t.Errorf("Kind() = %q, want %q", sys.Kind(), tt.kind)
+		}
+		if tt.kind == "compact_boundary" && (sys.CompactMetadata == nil || sys.CompactMetadata.PreTokens != 150000) {
+			t.Errorf("expected compact metadata to be parsed, got %+v", sys.CompactMetadata)
+		}
+	}
+}
+
+func TestParseEvent_InitToolNames(t *testing.T) {
+	event, err := ParseEvent(`{"type":"system","subtype":"init","tools":["Read",{"name":"Bash","description":"Run commands"}]}`)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	sys := event.(SystemEvent)
+	if len(sys.Tools) != 2 || sys.Tools[0].Name != "Read" || sys.Tools[1].Name != "Bash" || sys.Tools[1].Description != "Run commands" {
+		t.Errorf("unexpected tools: %+v", sys.Tools)
+	}
+	if sys.Fields["subtype"] != "init" {
+		t.Errorf("expected raw fields to be kept, got %v", sys.Fields)
+	}
+}
+
+func TestParseEvent_Correlation(t *testing.T) {
+	event, err := ParseEvent(`{"type":"user","uuid":"u2","parent_tool_use_id":"task1","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"r1","content":"ok"}]}}`)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if user := event.(UserEvent); user.UUID != "u2" || user.ParentToolUseID != "task1" {
+		t.Errorf("unexpected user correlation: %+v", user.Correlation)
+	}
+
+	event, err = ParseEvent(`{"type":"assistant","uuid":"u1","parent_tool_use_id":"task1","message":{"role":"assistant","content":[]}}`)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if assistant := event.(AssistantEvent); assistant.UUID != "u1" || assistant.ParentToolUseID != "task1" {
+		t.Errorf("unexpected assistant correlation: %+v", assistant.Correlation)
+	}
+
+	event, err = ParseEvent(`{"type":"stream_event","parent_tool_use_id":"task1","event":{"type":"message_stop"}}`)
+	if err != nil {
+		t.Fatalf("ParseEvent: %v", err)
+	}
+	if stream := event.(StreamEvent); stream.ParentToolUseID != "task1" {
+		t.Errorf("unexpected stream correlation: %+v", stream.Correlation)
+	}
+}
+
+func TestUserEventUnmarshal_BashResult(t *testing.T) {
+	jsonData := `{
+		"type": "user",
+		"message": {
+			"role": "user",
+			"content": [{
+				"type": "tool_result",
+				"tool_use_id": "toolu_bash",
+				"content": "make: *** [build] Error 2"
+			}]
+		},
+		"tool_use_result": {"stdout": "", "stderr": "make: *** [build] Error 2", "interrupted": false, "exitCode": 2}
+	}`
+
+	var event UserEvent
+	if err := json.Unmarshal([]byte(jsonData), &event); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	result := event.ToolUseResult
+	if result == nil || result.IsStringValue {
+		t.Fatalf("expected a structured ToolUseResult, got %+v", result)
+	}
+	if result.ExitCode == nil || *result.ExitCode != 2 {
+		t.Errorf("ExitCode = %v, want 2", result.ExitCode)
+	}
+	if result.Stderr != "make: *** [build] Error 2" || result.Stdout != "" {
+		t.Errorf("unexpected streams: stdout %q, stderr %q", result.Stdout, result.Stderr)
+	}
+}
@@ -0,0 +1,147 @@
+package output
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ansiState tracks where an ANSIStripWriter is within an escape sequence.
+type ansiState int
+
+const (
+	ansiText   ansiState = iota // ordinary text
+	ansiEscape                  // after ESC
+	ansiCSI                     // inside ESC [ ... final byte
+	ansiOSC                     // inside ESC ] ... BEL or ESC \
+	ansiOSCEsc                  // ESC seen inside an OSC (possible ST)
+)
+
+// next returns the state after byte b. A byte read in ansiText is ordinary
+// text unless it is the ESC starting a sequence; a sequence ends when the
+// state returns to ansiText.
+func (st ansiState) next(b byte) ansiState {
+	switch st {
+	case ansiText:
+		if b == 0x1b {
+			return ansiEscape
+		}
+	case ansiEscape:
+		switch b {
+		case '[':
+			return ansiCSI
+		case ']':
+			return ansiOSC
+		}
+		// Any other byte ends a two-byte escape such as ESC 7
+	case ansiCSI:
+		if b < 0x40 || b > 0x7e {
+			return ansiCSI
+		}
+	case ansiOSC:
+		switch b {
+		case 0x07:
+			return ansiText
+		case 0x1b:
+			return ansiOSCEsc
+		}
+		return ansiOSC
+	case ansiOSCEsc:
+		if b != '\\' {
+			return ansiOSC
+		}
+	}
+	return ansiText
+}
+
+// ANSIStripWriter removes ANSI escape sequences (CSI such as colors and
+// cursor movement, OSC such as titles and hyperlinks, and two-byte escapes)
+// before writing to the underlying writer. Sequences split across writes are
+// handled, so it can sit anywhere in a writer chain. Use it for sinks that
+// cannot render color even when the terminal view is colored.
+type ANSIStripWriter struct {
+	w     io.Writer
+	state ansiState
+}
+
+// NewANSIStripWriter returns a writer that strips ANSI sequences and writes
+// the remaining text to w.
+func NewANSIStripWriter(w io.Writer) *ANSIStripWriter {
+	return &ANSIStripWriter{w: w}
+}
+
+// Write strips escape sequences from p and writes the remaining bytes. It
+// reports len(p) on success so callers see the whole input as consumed.
+func (s *ANSIStripWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		if s.state == ansiText && b != 0x1b {
+			out = append(out, b)
+		}
+		s.state = s.state.next(b)
+	}
+	if len(out) > 0 {
+		if _, err := s.w.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// StripANSI returns s with all ANSI escape sequences removed.
+func StripANSI(s string) string {
+	var b strings.Builder
+	NewANSIStripWriter(&b).Write([]byte(s))
+	return b.String()
+}
+
+// SanitizeANSI returns s with only SGR sequences (ESC [ ... m, which set
+// colors and text attributes) kept. Every other escape sequence, such as
+// cursor movement, screen clears and OSC titles, is removed, as are control
+// characters other than tab and newline, so the text cannot move the cursor
+// or rewrite the terminal. An unterminated sequence at the end is dropped.
+func SanitizeANSI(s string) string {
+	var b strings.Builder
+	state, start := ansiText, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if state == ansiText && c != 0x1b {
+			if c >= 0x20 && c != 0x7f || c == '\t' || c == '\n' {
+				b.WriteByte(c)
+			}
+			// Other control characters (backspace, carriage return, bell) are dropped
+			continue
+		}
+		if state == ansiText {
+			start = i
+		}
+		if state = state.next(c); state == ansiText {
+			if seq := s[start : i+1]; len(seq) > 2 && seq[1] == '[' && seq[len(seq)-1] == 'm' {
+				b.WriteString(seq)
+			}
+		}
+	}
+	return b.String()
+}
+
+// sanitizeToolText returns tool output s ready to print: each invalid UTF-8
+// byte and each control character other than tab and newline (including
+// NUL and ESC) is replaced with Unprintable, so binary output can neither
+// garble the terminal nor vanish silently. A carriage return ending a line
+// (CRLF output) is dropped instead.
+func sanitizeToolText(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == utf8.RuneError && !strings.HasPrefix(s[i:], "\ufffd"):
+			b.WriteString(Unprintable)
+		case r == '\r' && (i+1 == len(s) || s[i+1] == '\n'):
+			// CRLF line break: the newline is enough
+		case r < 0x20 && r != '\t' && r != '\n', r >= 0x7f && r < 0xa0:
+			b.WriteString(Unprintable)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
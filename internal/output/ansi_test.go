@@ -0,0 +1,58 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestANSIStripWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"plain", []string{"hello"}, "hello"},
+		{"colors", []string{colorGreen + "ok" + colorReset + " done"}, "ok done"},
+		{"cursor movement", []string{"\x1b[2K\x1b[1Aline"}, "line"},
+		{"osc hyperlink", []string{"\x1b]8;;https://example.com\x07link\x1b]8;;\x1b\\"}, "link"},
+		{"two-byte escape", []string{"\x1b7saved\x1b8"}, "saved"},
+		{"split across writes", []string{"a\x1b", "[3", "1mb\x1b[0", "m"}, "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewANSIStripWriter(&buf)
+			for _, chunk := range tt.chunks {
+				n, err := w.Write([]byte(chunk))
+				if err != nil || n != len(chunk) {
+					t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+				}
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"colors kept", colorGreen + "ok" + colorReset, colorGreen + "ok" + colorReset},
+		{"attributes kept", "\x1b[1;4mbold\x1b[0m", "\x1b[1;4mbold\x1b[0m"},
+		{"cursor movement removed", "\x1b[2J\x1b[H\x1b[1Aline", "line"},
+		{"osc removed", "\x1b]0;title\x07text\x1b]8;;x\x1b\\", "text"},
+		{"two-byte escape removed", "\x1bcreset", "reset"},
+		{"control characters removed", "a\rb\x08c\x07\td\ne", "abc\td\ne"},
+		{"unterminated sequence dropped", "text\x1b[31", "text"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeANSI(tt.in); got != tt.want {
+			t.Errorf("%s: SanitizeANSI(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
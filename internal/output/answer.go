@@ -0,0 +1,133 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// AnswerJSON is the single object written to stdout by --quiet --json: the
+// final answer with the session's accounting. Error is null for a session
+// that succeeded.
+type AnswerJSON struct {
+	Answer  string  `json:"answer"`
+	Turns   int     `json:"turns"`
+	CostUSD float64 `json:"cost_usd"`
+	Error   *string `json:"error"`
+}
+
+// NewAnswerJSON builds the answer object for a finished session. result is
+// nil if no result event arrived; errMsg is empty when the session
+// succeeded. An error result's text is its error message, not an answer.
+func NewAnswerJSON(result *events.ResultEvent, errMsg string) AnswerJSON {
+	var a AnswerJSON
+	if result != nil {
+		a.Turns = result.NumTurns
+		a.CostUSD = result.TotalCostUSD
+		if !result.IsError {
+			a.Answer = result.Result
+		}
+	}
+	if errMsg != "" {
+		a.Error = &errMsg
+	}
+	return a
+}
+
+// WriteAnswerJSON writes a to w as one JSON line.
+func WriteAnswerJSON(w io.Writer, a AnswerJSON) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// AnswerTurnSeparator goes between the text of consecutive assistant turns
+// in an answer rebuilt by AnswerExtractor.
+const AnswerTurnSeparator = "\n\n---\n\n"
+
+// AnswerExtractor rebuilds the assistant's prose from a recorded session
+// (--strip-to-answer): the text of each assistant turn, leaving out tool
+// calls, tool results and sub-agent output. Text blocks of one message
+// stay in the same turn. Complete assistant messages are preferred; a log
+// without them (e.g. filtered with --debug-log-filter) falls back to the
+// streamed text, then to the result events' text.
+type AnswerExtractor struct {
+	turns         []string // Text of each assistant message
+	messageID     string   // Message the last entry of turns belongs to
+	streamed      []string // Streamed text of each message
+	streamedStart bool     // A message started; the next delta opens a new entry
+	results       []string // Text of each successful result
+}
+
+// Add records the assistant text carried by event.
+func (a *AnswerExtractor) Add(event events.Event) {
+	switch e := event.(type) {
+	case events.AssistantEvent:
+		if e.ParentToolUseID != "" {
+			return
+		}
+		for _, block := range e.Message.Content {
+			text := strings.TrimSpace(block.Text)
+			if block.Type != "text" || text == "" {
+				continue
+			}
+			if n := len(a.turns); n > 0 && e.Message.ID != "" && e.Message.ID == a.messageID {
+				a.turns[n-1] += "\n\n" + text
+			} else {
+				a.turns = append(a.turns, text)
+			}
+			a.messageID = e.Message.ID
+		}
+	case events.StreamEvent:
+		if e.ParentToolUseID != "" {
+			return
+		}
+		switch {
+		case e.Event.Type == "message_start":
+			a.streamedStart = true
+		case e.Event.Type == "content_block_delta" && e.Event.Delta != nil && e.Event.Delta.Text != "":
+			if a.streamedStart || len(a.streamed) == 0 {
+				a.streamed = append(a.streamed, "")
+				a.streamedStart = false
+			}
+			a.streamed[len(a.streamed)-1] += e.Event.Delta.Text
+		}
+	case events.ResultEvent:
+		if !e.IsError && strings.TrimSpace(e.Result) != "" {
+			a.results = append(a.results, strings.TrimSpace(e.Result))
+		}
+	}
+}
+
+// answerTurns returns the best available text per turn (see AnswerExtractor).
+func (a *AnswerExtractor) answerTurns() []string {
+	if len(a.turns) > 0 {
+		return a.turns
+	}
+	var streamed []string
+	for _, text := range a.streamed {
+		if text = strings.TrimSpace(text); text != "" {
+			streamed = append(streamed, text)
+		}
+	}
+	if len(streamed) > 0 {
+		return streamed
+	}
+	return a.results
+}
+
+// Turns returns the number of turns with text recorded so far.
+func (a *AnswerExtractor) Turns() int {
+	return len(a.answerTurns())
+}
+
+// Answer returns the recorded turns joined by AnswerTurnSeparator.
+func (a *AnswerExtractor) Answer() string {
+	return strings.Join(a.answerTurns(), AnswerTurnSeparator)
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestWriteAnswerJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *events.ResultEvent
+		errMsg string
+		want   string
+	}{
+		{
+			name:   "success",
+			result: &events.ResultEvent{Result: "4\n", NumTurns: 1, TotalCostUSD: 0.0123},
+			want:   `{"answer":"4\n","turns":1,"cost_usd":0.0123,"error":null}` + "\n",
+		},
+		{
+			name:   "error result",
+			result: &events.ResultEvent{Subtype: "error_max_turns", IsError: true, Result: "Reached max turns", NumTurns: 3, TotalCostUSD: 0.5},
+			errMsg: "Reached max turns",
+			want:   `{"answer":"","turns":3,"cost_usd":0.5,"error":"Reached max turns"}` + "\n",
+		},
+		{
+			name:   "no result",
+			errMsg: "interrupted by interrupt",
+			want:   `{"answer":"","turns":0,"cost_usd":0,"error":"interrupted by interrupt"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := WriteAnswerJSON(&buf, NewAnswerJSON(tt.result, tt.errMsg)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s:\nwant: %s\ngot:  %s", tt.name, tt.want, buf.String())
+		}
+	}
+}
+
+func TestAnswerExtractor(t *testing.T) {
+	var a AnswerExtractor
+	for _, line := range []string{
+		`{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me look."}}}`,
+		`{"type":"assistant","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"Let me look."}]}}`,
+		`{"type":"assistant","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"r1","name":"Read","input":{"file_path":"main.go"}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"r1","content":"package main"}]}}`,
+		`{"type":"assistant","parent_tool_use_id":"task1","message":{"id":"s1","role":"assistant","content":[{"type":"text","text":"sub-agent notes"}]}}`,
+		`{"type":"assistant","message":{"id":"m2","role":"assistant","content":[{"type":"text","text":"It is a main package.\n"}]}}`,
+		`{"type":"assistant","message":{"id":"m2","role":"assistant","content":[{"type":"text","text":"Nothing else."}]}}`,
+		`{"type":"result","subtype":"success","result":"Nothing else."}`,
+	} {
+		event, err := events.ParseEvent(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.Add(event)
+	}
+
+	want := "Let me look." + AnswerTurnSeparator + "It is a main package.\n\nNothing else."
+	if a.Turns() != 2 || a.Answer() != want {
+		t.Errorf("Answer() = %q (%d turns), want %q", a.Answer(), a.Turns(), want)
+	}
+}
+
+func TestAnswerExtractor_Fallbacks(t *testing.T) {
+	add := func(a *AnswerExtractor, lines ...string) {
+		for _, line := range lines {
+			event, err := events.ParseEvent(line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.Add(event)
+		}
+	}
+
+	// Without assistant messages, streamed text is used, one turn per message
+	var streamed AnswerExtractor
+	add(&streamed,
+		`{"type":"stream_event","event":{"type":"message_start"}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" now."}}}`,
+		`{"type":"stream_event","event":{"type":"message_start"}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Done."}}}`,
+		`{"type":"result","subtype":"success","result":"Done."}`,
+	)
+	if want := "Checking now." + AnswerTurnSeparator + "Done."; streamed.Answer() != want {
+		t.Errorf("streamed Answer() = %q, want %q", streamed.Answer(), want)
+	}
+
+	// With only result events, their text is used
+	var results AnswerExtractor
+	add(&results,
+		`{"type":"result","subtype":"success","result":"First."}`,
+		`{"type":"result","subtype":"error_max_turns","is_error":true,"result":"Reached max turns"}`,
+	)
+	if results.Turns() != 1 || results.Answer() != "First." {
+		t.Errorf("results Answer() = %q", results.Answer())
+	}
+}
@@ -0,0 +1,158 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/redact"
+)
+
+// Audit log statuses.
+const (
+	AuditOK       = "ok"
+	AuditError    = "error"
+	AuditDenied   = "denied"
+	AuditNoResult = "no_result" // The session ended before the result arrived
+)
+
+// auditKeyParams are the tool parameters that identify what a call acted on.
+// Tools with none of them (e.g. MCP tools) are logged with all their scalar
+// parameters instead.
+var auditKeyParams = []string{"file_path", "notebook_path", "path", "pattern", "command", "url", "query", "description", "subagent_type"}
+
+// auditCall is a tool call awaiting its result for the audit log.
+type auditCall struct {
+	time   time.Time
+	tool   string
+	params map[string]interface{}
+}
+
+// auditRecord is one line of the audit log.
+type auditRecord struct {
+	Time   string                 `json:"time"`
+	ID     string                 `json:"id"`
+	Tool   string                 `json:"tool"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Status string                 `json:"status"`
+}
+
+// trackAuditLog records tool calls and writes one AuditLog line for each
+// once its outcome is known. Calls still pending when the session's result
+// arrives are logged as AuditNoResult. No-op when AuditLog is nil.
+func (d *Display) trackAuditLog(event events.Event) {
+	if d.AuditLog == nil {
+		return
+	}
+	switch e := event.(type) {
+	case events.AssistantEvent:
+		for _, block := range e.Message.Content {
+			if block.Type != "tool_use" {
+				continue
+			}
+			if d.State.AuditCalls == nil {
+				d.State.AuditCalls = make(map[string]*auditCall)
+			}
+			d.State.AuditCalls[block.ID] = &auditCall{
+				time:   time.Now(),
+				tool:   block.Name,
+				params: auditParams(block.Input),
+			}
+		}
+	case events.UserEvent:
+		for _, block := range e.Message.Content {
+			if block.Type != "tool_result" {
+				continue
+			}
+			status := AuditOK
+			if d.isToolDenied(block.ContentString) {
+				status = AuditDenied
+			} else if block.IsError {
+				status = AuditError
+			}
+			d.writeAuditRecord(block.ToolUseID, status)
+		}
+	case events.ResultEvent:
+		d.FlushAuditLog()
+	}
+}
+
+// FlushAuditLog logs every call still awaiting its result as AuditNoResult,
+// e.g. when the session is interrupted.
+func (d *Display) FlushAuditLog() {
+	ids := make([]string, 0, len(d.State.AuditCalls))
+	for id := range d.State.AuditCalls {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return d.State.AuditCalls[ids[i]].time.Before(d.State.AuditCalls[ids[j]].time)
+	})
+	for _, id := range ids {
+		d.writeAuditRecord(id, AuditNoResult)
+	}
+}
+
+// writeAuditRecord writes the audit line for the call with id and forgets it.
+func (d *Display) writeAuditRecord(id, status string) {
+	call := d.State.AuditCalls[id]
+	if call == nil || d.AuditLog == nil {
+		return
+	}
+	delete(d.State.AuditCalls, id)
+
+	data, err := json.Marshal(auditRecord{
+		Time:   call.time.UTC().Format(time.RFC3339Nano),
+		ID:     id,
+		Tool:   call.tool,
+		Params: call.params,
+		Status: status,
+	})
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(d.AuditLog, string(data))
+}
+
+// auditParams selects the parameters of a tool call to log, with secrets
+// redacted.
+func auditParams(input map[string]interface{}) map[string]interface{} {
+	params := make(map[string]interface{})
+	for _, key := range auditKeyParams {
+		if value, ok := input[key]; ok {
+			params[key] = redactAuditParam(key, value)
+		}
+	}
+	if len(params) > 0 {
+		return params
+	}
+	for key, value := range input {
+		switch value.(type) {
+		case string, float64, bool:
+			params[key] = redactAuditParam(key, value)
+		}
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return params
+}
+
+// redactAuditParam returns "[REDACTED]" for a parameter whose name looks like
+// it holds a secret. URL credentials are redacted in every string parameter,
+// and in a command so are the values of secret-looking NAME=value assignments.
+func redactAuditParam(key string, value interface{}) interface{} {
+	if redact.IsSecretName(key) {
+		return redact.Placeholder
+	}
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	s = redact.URLCredentials(s)
+	if key != "command" {
+		return s
+	}
+	return redact.Assignments(s)
+}
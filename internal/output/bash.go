@@ -0,0 +1,65 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// bashExitLine matches the "Exit code N" line the Claude CLI puts at the top
+// of a failed Bash result, with or without an "Error: " prefix.
+var bashExitLine = regexp.MustCompile(`^(?:Error: )?Exit code (\d+)\s*$`)
+
+// bashExitCode returns the exit code of a Bash result: the structured
+// exitCode when reported, else the leading "Exit code N" line of the result
+// text. ok is false when neither says.
+func bashExitCode(result *events.ToolUseResult, content string) (code int, ok bool) {
+	if result != nil && result.ExitCode != nil {
+		return *result.ExitCode, true
+	}
+	for _, text := range []string{content, rawToolResult(result)} {
+		first, _, _ := strings.Cut(text, "\n")
+		if m := bashExitLine.FindStringSubmatch(first); m != nil {
+			code, err := strconv.Atoi(m[1])
+			return code, err == nil
+		}
+	}
+	return 0, false
+}
+
+// rawToolResult returns the plain string form of a tool_use_result, or "".
+func rawToolResult(result *events.ToolUseResult) string {
+	if result == nil || !result.IsStringValue {
+		return ""
+	}
+	return result.RawValue
+}
+
+// bashFailure reports whether a tool result is a Bash command that exited
+// non-zero, whether or not the block was flagged is_error, and returns its
+// summary line: "exit N", followed by the first line of its error output.
+func bashFailure(toolName string, result *events.ToolUseResult, content string) (string, bool) {
+	if !strings.EqualFold(toolName, "bash") {
+		return "", false
+	}
+	code, ok := bashExitCode(result, content)
+	if !ok || code == 0 {
+		return "", false
+	}
+
+	summary := fmt.Sprintf("exit %d", code)
+	detail := content
+	if result != nil && result.Stderr != "" {
+		detail = result.Stderr
+	}
+	for _, line := range strings.Split(detail, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !bashExitLine.MatchString(line) {
+			return summary + ": " + truncateLine(line, 60), true
+		}
+	}
+	return summary, true
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// bashResultEvent builds a Bash tool_result with structured metadata.
+func bashResultEvent(id, content string, isError bool, result *events.ToolUseResult) events.UserEvent {
+	e := toolResultEvent(id, content, isError)
+	e.ToolUseResult = result
+	return e
+}
+
+func TestBashResult_NonZeroExit(t *testing.T) {
+	exitCode := 2
+	tests := []struct {
+		name    string
+		content string
+		isError bool
+		result  *events.ToolUseResult
+		want    string
+	}{
+		{"structured exit code without is_error", "make: *** [build] Error 2", false,
+			&events.ToolUseResult{Stderr: "make: *** [build] Error 2", ExitCode: &exitCode}, "exit 2: make: *** [build] Error 2"},
+		{"exit code line in content", "Error: Exit code 1\ngo: cannot find main module", true, nil,
+			"exit 1: go: cannot find main module"},
+		{"exit code line only", "Exit code 127", true, &events.ToolUseResult{IsStringValue: true, RawValue: "Exit code 127"},
+			"exit 127"},
+	}
+
+	for _, tt := range tests {
+		for _, verbosity := range []Verbosity{VerbosityNormal, VerbosityVerbose} {
+			buf := &strings.Builder{}
+			d := NewDisplay(NewFormatter(true, false, buf), verbosity)
+			d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "make"}))
+			d.HandleEvent(bashResultEvent("b1", tt.content, tt.isError, tt.result))
+
+			if want := colorRed + TreeBranch + tt.want + colorReset; !strings.Contains(buf.String(), want) {
+				t.Errorf("%s, verbosity %d: expected %q, got %q", tt.name, verbosity, want, buf.String())
+			}
+		}
+	}
+}
+
+func TestBashResult_SuccessUnchanged(t *testing.T) {
+	zero := 0
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "ls"}))
+	d.HandleEvent(bashResultEvent("b1", "main.go", false, &events.ToolUseResult{Stdout: "main.go", ExitCode: &zero}))
+
+	if want := TreeBranch + "main.go\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestBashResult_FailureShownWithHiddenToolOutput(t *testing.T) {
+	exitCode := 1
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HideToolOutput = true
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "false"}))
+	d.HandleEvent(bashResultEvent("b1", "", false, &events.ToolUseResult{ExitCode: &exitCode}))
+
+	if !strings.Contains(buf.String(), TreeBranch+"exit 1") {
+		t.Errorf("expected the failure despite --no-tool-output, got %q", buf.String())
+	}
+}
@@ -0,0 +1,165 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// BatchRun is one prompt of a --batch run and how it ended.
+type BatchRun struct {
+	Prompt   string
+	ExitCode int
+	Result   *events.ResultEvent // nil if no result event arrived
+}
+
+// SessionLog collects how each Claude session of a run ended, in order, for
+// a report in the batch report format (--debug-dir's stats.json). A nil
+// *SessionLog records nothing.
+type SessionLog struct {
+	runs []BatchRun
+}
+
+// Runs returns the sessions recorded so far.
+func (l *SessionLog) Runs() []BatchRun {
+	if l == nil {
+		return nil
+	}
+	return l.runs
+}
+
+// RecordSession adds a finished session to Sessions, with the prompt hidden
+// if HidePrompt is set. exitCode is the Claude CLI's; result is nil if no
+// result event arrived. No-op when Sessions is nil.
+func (d *Display) RecordSession(prompt string, exitCode int, result *events.ResultEvent) {
+	if d.Sessions == nil {
+		return
+	}
+	d.Sessions.runs = append(d.Sessions.runs, BatchRun{Prompt: d.shownPrompt(prompt), ExitCode: exitCode, Result: result})
+}
+
+// batchRunReport is the usage of one batch prompt, as reported.
+type batchRunReport struct {
+	Index        int     `json:"index"`
+	Prompt       string  `json:"prompt"`
+	Status       string  `json:"status"` // "ok" or "failed"
+	ExitCode     int     `json:"exit_code"`
+	CostUSD      float64 `json:"cost_usd"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Turns        int     `json:"turns"`
+	DurationMS   int64   `json:"duration_ms"`
+}
+
+// batchTotals aggregates usage across a batch.
+type batchTotals struct {
+	Runs         int     `json:"runs"`
+	Failed       int     `json:"failed"`
+	CostUSD      float64 `json:"cost_usd"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	DurationMS   int64   `json:"duration_ms"`
+}
+
+// batchReport builds the per-prompt breakdown and totals for runs.
+func batchReport(runs []BatchRun) ([]batchRunReport, batchTotals) {
+	reports := make([]batchRunReport, len(runs))
+	var totals batchTotals
+	for i, run := range runs {
+		r := batchRunReport{Index: i + 1, Prompt: run.Prompt, Status: "ok", ExitCode: run.ExitCode}
+		if run.ExitCode != 0 {
+			r.Status = "failed"
+			totals.Failed++
+		}
+		if run.Result != nil {
+			r.CostUSD = run.Result.TotalCostUSD
+			r.InputTokens, r.OutputTokens = calculateTotalTokens(*run.Result)
+			r.Turns = run.Result.NumTurns
+			r.DurationMS = run.Result.DurationMS
+		}
+		reports[i] = r
+
+		totals.Runs++
+		totals.CostUSD += r.CostUSD
+		totals.InputTokens += r.InputTokens
+		totals.OutputTokens += r.OutputTokens
+		totals.DurationMS += r.DurationMS
+	}
+	return reports, totals
+}
+
+// ShowBatchReport displays a table of each batch prompt's status, cost and
+// tokens, followed by the totals. Shown at every verbosity, since it is the
+// only summary of the whole batch.
+func (d *Display) ShowBatchReport(runs []BatchRun) {
+	reports, totals := batchReport(runs)
+
+	rows := [][]string{{"#", "Prompt", "Status", "Cost", "In", "Out", "Turns"}}
+	for _, r := range reports {
+		rows = append(rows, []string{
+			strconv.Itoa(r.Index),
+			truncateLine(d.shownPrompt(r.Prompt), 40),
+			r.Status,
+			formatCost(r.CostUSD),
+			strconv.Itoa(r.InputTokens),
+			strconv.Itoa(r.OutputTokens),
+			strconv.Itoa(r.Turns),
+		})
+	}
+
+	d.Formatter.Plain("")
+	d.Formatter.Info("=== Batch Report ===")
+	aligns := []int{alignRight, alignLeft, alignLeft, alignRight, alignRight, alignRight, alignRight}
+	for _, line := range formatTable(rows, aligns) {
+		d.Formatter.Plain("%s%s", d.indent(1), line)
+	}
+	summary := fmt.Sprintf("Total: %d prompts, %d failed, %s, %d in / %d out, %s",
+		totals.Runs, totals.Failed, formatCost(totals.CostUSD), totals.InputTokens, totals.OutputTokens, formatDuration(totals.DurationMS))
+	if totals.Failed > 0 {
+		d.Formatter.Warning("%s", summary)
+	} else {
+		d.Formatter.Success("%s", summary)
+	}
+}
+
+// WriteBatchReportJSON writes the batch report as an indented JSON object
+// with "runs" (the per-prompt breakdown) and "total".
+func WriteBatchReportJSON(w io.Writer, runs []BatchRun) error {
+	reports, totals := batchReport(runs)
+	data, err := json.MarshalIndent(struct {
+		Runs  []batchRunReport `json:"runs"`
+		Total batchTotals      `json:"total"`
+	}{reports, totals}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// WriteBatchReportCSV writes the per-prompt breakdown as CSV with a header
+// row, for spreadsheet import. Totals are left to the spreadsheet.
+func WriteBatchReportCSV(w io.Writer, runs []BatchRun) error {
+	reports, _ := batchReport(runs)
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"index", "prompt", "status", "exit_code", "cost_usd", "input_tokens", "output_tokens", "turns", "duration_ms"})
+	for _, r := range reports {
+		_ = cw.Write([]string{
+			strconv.Itoa(r.Index),
+			r.Prompt,
+			r.Status,
+			strconv.Itoa(r.ExitCode),
+			strconv.FormatFloat(r.CostUSD, 'f', -1, 64),
+			strconv.Itoa(r.InputTokens),
+			strconv.Itoa(r.OutputTokens),
+			strconv.Itoa(r.Turns),
+			strconv.FormatInt(r.DurationMS, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
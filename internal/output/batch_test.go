@@ -0,0 +1,75 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func batchRuns() []BatchRun {
+	ok := &events.ResultEvent{TotalCostUSD: 0.25, NumTurns: 2, DurationMS: 1500, ModelUsage: map[string]*events.ModelUsage{
+		"claude-sonnet-4": {InputTokens: 100, OutputTokens: 20},
+	}}
+	failed := &events.ResultEvent{TotalCostUSD: 0.05, NumTurns: 1, IsError: true}
+	return []BatchRun{
+		{Prompt: "Summarize, briefly", ExitCode: 0, Result: ok},
+		{Prompt: "Fix the build", ExitCode: 1, Result: failed},
+		{Prompt: "Never answered", ExitCode: 130},
+	}
+}
+
+func TestShowBatchReport(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityQuiet)
+	d.ShowBatchReport(batchRuns())
+
+	out := buf.String()
+	for _, want := range []string{
+		"1 Summarize, briefly ok       $0.25 100  20     2",
+		"2 Fix the build      failed   $0.05   0   0     1",
+		"3 Never answered     failed",
+		"Total: 3 prompts, 2 failed, $0.30, 100 in / 20 out, 1.5s",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in report, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteBatchReportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBatchReportJSON(&buf, batchRuns()); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Runs  []batchRunReport `json:"runs"`
+		Total batchTotals      `json:"total"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(report.Runs) != 3 || report.Runs[0].InputTokens != 100 || report.Runs[1].Status != "failed" {
+		t.Errorf("unexpected runs: %+v", report.Runs)
+	}
+	if report.Total.Runs != 3 || report.Total.Failed != 2 || report.Total.OutputTokens != 20 {
+		t.Errorf("unexpected totals: %+v", report.Total)
+	}
+}
+
+func TestWriteBatchReportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteBatchReportCSV(&buf, batchRuns()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "index,prompt,status,exit_code,cost_usd,input_tokens,output_tokens,turns,duration_ms\n" +
+		"1,\"Summarize, briefly\",ok,0,0.25,100,20,2,1500\n" +
+		"2,Fix the build,failed,1,0.05,0,0,1,0\n" +
+		"3,Never answered,failed,130,0,0,0,0,0\n"
+	if buf.String() != want {
+		t.Errorf("CSV mismatch\nwant: %q\ngot:  %q", want, buf.String())
+	}
+}
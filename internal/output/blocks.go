@@ -0,0 +1,326 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// Content block types recorded for --blocks-json.
+const (
+	BlockText       = "text"
+	BlockToolUse    = "tool_use"
+	BlockToolResult = "tool_result"
+)
+
+// ContentBlockRecord is one content block of a session, in the order it
+// arrived. Turn counts assistant messages from 0; a tool_result belongs to
+// the turn whose tool call it answers. Fields not used by a block's type
+// are omitted.
+type ContentBlockRecord struct {
+	Index     int                    `json:"index"`
+	Turn      int                    `json:"turn"`
+	Type      string                 `json:"type"`                  // BlockText, BlockToolUse or BlockToolResult
+	Text      string                 `json:"text,omitempty"`        // text: the block's full text
+	ID        string                 `json:"id,omitempty"`          // tool_use: the call's ID
+	Name      string                 `json:"name,omitempty"`        // tool_use: the tool's name
+	Input     map[string]interface{} `json:"input,omitempty"`       // tool_use: the call's parameters
+	ToolUseID string                 `json:"tool_use_id,omitempty"` // tool_result: the answered call's ID
+	Content   string                 `json:"content,omitempty"`     // tool_result: the result text
+	IsError   bool                   `json:"is_error,omitempty"`    // tool_result: the call failed
+}
+
+// BlocksDocument is the JSON line written to BlocksWriter when a session
+// ends: {"session_id":"...","is_error":false,"blocks":[...]}.
+type BlocksDocument struct {
+	SessionID string               `json:"session_id,omitempty"`
+	IsError   bool                 `json:"is_error"`
+	Blocks    []ContentBlockRecord `json:"blocks"`
+}
+
+// blocksSpill is the temp file that recorded blocks move to once they
+// outgrow Display.BlocksSpillBytes. It holds the blocks written so far as
+// comma-separated JSON objects, ready to splice into the document.
+type blocksSpill struct {
+	file  *os.File
+	count int   // Blocks written to file
+	err   error // First failure writing file; the document reports it
+}
+
+// trackContentBlocks records the session's text, tool_use and tool_result
+// blocks in arrival order and writes them to BlocksWriter as one
+// BlocksDocument when the result arrives. Text is assembled from the
+// streamed deltas; tool calls are taken from the assistant event, which
+// carries their full input. No-op when BlocksWriter is nil.
+func (d *Display) trackContentBlocks(event events.Event) {
+	if d.BlocksWriter == nil {
+		return
+	}
+	switch e := event.(type) {
+	case events.StreamEvent:
+		switch e.Event.Type {
+		case "content_block_start":
+			d.closeBlockText()
+		case "content_block_delta":
+			if e.Event.Delta != nil && e.Event.Delta.Text != "" {
+				d.appendBlockText(e.Event.Delta.Text)
+			}
+		case "content_block_stop":
+			d.closeBlockText()
+		case "message_stop":
+			d.closeBlockText()
+			d.State.BlockTurn++
+		}
+	case events.AssistantEvent:
+		for _, block := range e.Message.Content {
+			if _, seen := d.State.BlockToolTurns[block.ID]; block.Type != BlockToolUse || seen {
+				continue
+			}
+			if d.State.BlockToolTurns == nil {
+				d.State.BlockToolTurns = make(map[string]int)
+			}
+			d.State.BlockToolTurns[block.ID] = d.State.BlockTurn
+			d.addBlock(ContentBlockRecord{Turn: d.State.BlockTurn, Type: BlockToolUse, ID: block.ID, Name: block.Name, Input: block.Input})
+		}
+	case events.UserEvent:
+		for _, block := range e.Message.Content {
+			if block.Type != BlockToolResult {
+				continue
+			}
+			record := ContentBlockRecord{
+				Turn:      d.State.BlockTurn,
+				Type:      BlockToolResult,
+				ToolUseID: block.ToolUseID,
+				Content:   toolResultText(block),
+				IsError:   block.IsError,
+			}
+			if turn, ok := d.State.BlockToolTurns[block.ToolUseID]; ok {
+				record.Turn = turn
+			}
+			// A repeated result replaces the earlier one in place, unless
+			// that one was already spilled
+			if i := d.findResultBlock(block.ToolUseID); i >= 0 {
+				record.Index = d.State.Blocks[i].Index
+				d.State.BlocksBytes += blockSize(record) - blockSize(d.State.Blocks[i])
+				d.State.Blocks[i] = record
+				continue
+			}
+			d.addBlock(record)
+		}
+	case events.ResultEvent:
+		d.writeBlocks(BlocksDocument{SessionID: e.SessionID, IsError: e.IsError, Blocks: d.State.Blocks})
+		d.discardBlocks()
+	}
+}
+
+// FinishBlocks ends a session's --blocks-json recording. Blocks still
+// recorded, because the session ended without a result event (interrupted
+// or crashed), are written as a document marked as an error, so what was
+// collected isn't lost. Call it whenever a session ends; it does nothing
+// once the result event has written the document.
+func (d *Display) FinishBlocks(sessionID string) {
+	if d.BlocksWriter != nil && d.State.BlockCount > 0 {
+		d.closeBlockText()
+		d.writeBlocks(BlocksDocument{SessionID: sessionID, IsError: true, Blocks: d.State.Blocks})
+	}
+	d.discardBlocks()
+}
+
+// discardBlocks drops the blocks recorded for --blocks-json and removes any
+// spill file.
+func (d *Display) discardBlocks() {
+	if spill := d.State.BlocksSpill; spill != nil {
+		spill.file.Close()
+		os.Remove(spill.file.Name())
+	}
+	d.State.Blocks = nil
+	d.State.BlocksSpill = nil
+	d.State.BlocksBytes = 0
+	d.State.BlockCount = 0
+	d.State.BlockToolTurns = nil
+	d.State.BlockTextOpen = false
+	d.State.BlockTurn = 0
+}
+
+// appendBlockText adds streamed text to the open text block, starting a new
+// one if a tool call or block boundary came in between.
+func (d *Display) appendBlockText(text string) {
+	if !d.State.BlockTextOpen {
+		d.addBlock(ContentBlockRecord{Turn: d.State.BlockTurn, Type: BlockText, Text: text})
+		d.State.BlockTextOpen = true
+		return
+	}
+	d.State.Blocks[len(d.State.Blocks)-1].Text += text
+	d.State.BlocksBytes += len(text)
+}
+
+// closeBlockText ends the open text block, which may then be spilled.
+func (d *Display) closeBlockText() {
+	if d.State.BlockTextOpen {
+		d.State.BlockTextOpen = false
+		d.spillBlocksIfLarge()
+	}
+}
+
+// addBlock appends record as the session's next block.
+func (d *Display) addBlock(record ContentBlockRecord) {
+	// Closes any open text block first
+	d.closeBlockText()
+	record.Index = d.State.BlockCount
+	d.State.BlockCount++
+	d.State.Blocks = append(d.State.Blocks, record)
+	d.State.BlocksBytes += blockSize(record)
+	if record.Type != BlockText {
+		d.spillBlocksIfLarge()
+	}
+}
+
+// findResultBlock returns the index in State.Blocks of the tool_result for
+// tool call id, or -1.
+func (d *Display) findResultBlock(id string) int {
+	for i, b := range d.State.Blocks {
+		if b.Type == BlockToolResult && b.ToolUseID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// blockSize estimates the memory a recorded block holds.
+func blockSize(b ContentBlockRecord) int {
+	size := len(b.Text) + len(b.ID) + len(b.Name) + len(b.ToolUseID) + len(b.Content)
+	if b.Input != nil {
+		if data, err := json.Marshal(b.Input); err == nil {
+			size += len(data)
+		}
+	}
+	return size
+}
+
+// spillBlocksIfLarge moves the recorded blocks to the spill file once they
+// hold more than BlocksSpillBytes. An open text block stays in memory until
+// it ends. If no temp file can be created the blocks stay in memory.
+func (d *Display) spillBlocksIfLarge() {
+	if d.BlocksSpillBytes <= 0 || d.State.BlocksBytes <= d.BlocksSpillBytes || d.State.BlockTextOpen {
+		return
+	}
+	spill := d.State.BlocksSpill
+	if spill == nil {
+		file, err := os.CreateTemp(d.BlocksSpillDir, "claude-print-blocks-*.json")
+		if err != nil {
+			return
+		}
+		spill = &blocksSpill{file: file}
+		d.State.BlocksSpill = spill
+	}
+	for _, b := range d.State.Blocks {
+		data, err := json.Marshal(b)
+		if err == nil && spill.count > 0 {
+			_, err = spill.file.WriteString(",")
+		}
+		if err == nil {
+			_, err = spill.file.Write(data)
+		}
+		if err != nil && spill.err == nil {
+			spill.err = err
+		}
+		spill.count++
+	}
+	d.State.Blocks = nil
+	d.State.BlocksBytes = 0
+}
+
+// toolResultText returns a tool_result's content as text, joining the text
+// parts of array content (as returned by Task agents).
+func toolResultText(block events.ContentBlock) string {
+	if block.ContentString != "" {
+		return block.ContentString
+	}
+	var parts []string
+	for _, part := range block.ContentBlocks {
+		if part.Type == BlockText {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// toolErrorText returns a tool_result error's content ready to print (see
+// sanitizeToolText). Only user events have their tool_result content
+// decoded by the events package, so raw JSON content is decoded here.
+func toolErrorText(block events.ContentBlock) string {
+	if block.ContentString == "" && block.ContentBlocks == nil && len(block.Content) > 0 {
+		if err := json.Unmarshal(block.Content, &block.ContentString); err != nil {
+			if err := json.Unmarshal(block.Content, &block.ContentBlocks); err != nil {
+				block.ContentString = string(block.Content)
+			}
+		}
+	}
+	return sanitizeToolText(toolResultText(block))
+}
+
+// writeBlocks writes doc to BlocksWriter as a single JSON line, with any
+// blocks spilled to the temp file ahead of doc.Blocks. A failure is
+// reported as a warning.
+func (d *Display) writeBlocks(doc BlocksDocument) {
+	if err := d.encodeBlocks(doc); err != nil {
+		d.Formatter.Warning("--blocks-json: writing the session's blocks failed: %v", err)
+	}
+}
+
+// encodeBlocks writes doc to BlocksWriter. With blocks spilled, the
+// document is written field by field, in BlocksDocument's encoding, so the
+// spilled blocks are copied from the temp file rather than read back into
+// memory. Nothing is written if the spill file is incomplete.
+func (d *Display) encodeBlocks(doc BlocksDocument) error {
+	spill := d.State.BlocksSpill
+	if spill == nil {
+		if doc.Blocks == nil {
+			doc.Blocks = []ContentBlockRecord{}
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(d.BlocksWriter, string(data))
+		return err
+	}
+	if spill.err != nil {
+		return fmt.Errorf("spilling blocks to %s: %w", spill.file.Name(), spill.err)
+	}
+	if _, err := spill.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(d.BlocksWriter)
+	w.WriteString("{")
+	if doc.SessionID != "" {
+		id, err := json.Marshal(doc.SessionID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, `"session_id":%s,`, id)
+	}
+	fmt.Fprintf(w, `"is_error":%t,"blocks":[`, doc.IsError)
+	if _, err := io.Copy(w, spill.file); err != nil {
+		return err
+	}
+	for i, b := range doc.Blocks {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		if spill.count > 0 || i > 0 {
+			w.WriteString(",")
+		}
+		w.Write(data)
+	}
+	w.WriteString("]}\n")
+	// bufio.Writer keeps the first write error
+	return w.Flush()
+}
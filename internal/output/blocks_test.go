@@ -0,0 +1,225 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestContentBlocks_InterleavedSession(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	out := &bytes.Buffer{}
+	d.BlocksWriter = out
+
+	// Turn 0: text, then a tool call; turn 1: two text blocks around a second call
+	var evts []events.Event
+	evts = append(evts, streamTextEvents("Let me ", "check.")...)
+	evts = append(evts,
+		toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "go.mod"}),
+		streamEvent("message_stop"),
+		toolResultEvent("t1", "module example", false),
+	)
+	evts = append(evts, streamTextEvents("Now grep.")...)
+	evts = append(evts,
+		toolUseEvent("t2", "Grep", map[string]interface{}{"pattern": "TODO"}),
+		toolResultEvent("t2", "no matches", true),
+		toolResultEvent("t2", "no matches", true), // repeated result
+		streamEvent("message_stop"),
+	)
+	evts = append(evts, streamTextEvents("Done.")...)
+	evts = append(evts, streamEvent("message_stop"), successResult())
+	for _, e := range evts {
+		d.HandleEvent(e)
+	}
+
+	var doc BlocksDocument
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	want := []ContentBlockRecord{
+		{Index: 0, Turn: 0, Type: BlockText, Text: "Let me check."},
+		{Index: 1, Turn: 0, Type: BlockToolUse, ID: "t1", Name: "Read", Input: map[string]interface{}{"file_path": "go.mod"}},
+		{Index: 2, Turn: 0, Type: BlockToolResult, ToolUseID: "t1", Content: "module example"},
+		{Index: 3, Turn: 1, Type: BlockText, Text: "Now grep."},
+		{Index: 4, Turn: 1, Type: BlockToolUse, ID: "t2", Name: "Grep", Input: map[string]interface{}{"pattern": "TODO"}},
+		{Index: 5, Turn: 1, Type: BlockToolResult, ToolUseID: "t2", Content: "no matches", IsError: true},
+		{Index: 6, Turn: 2, Type: BlockText, Text: "Done."},
+	}
+	if !reflect.DeepEqual(doc.Blocks, want) {
+		t.Errorf("blocks:\n got %+v\nwant %+v", doc.Blocks, want)
+	}
+	if bytes.Count(out.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("expected a single JSON line, got %q", out.String())
+	}
+}
+
+func TestContentBlocks_ResetPerSession(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityQuiet)
+	out := &bytes.Buffer{}
+	d.BlocksWriter = out
+
+	for _, text := range []string{"first", "second"} {
+		for _, e := range streamTextEvents(text) {
+			d.HandleEvent(e)
+		}
+		d.HandleEvent(streamEvent("message_stop"))
+		d.HandleEvent(successResult())
+	}
+
+	dec := json.NewDecoder(out)
+	for _, text := range []string{"first", "second"} {
+		var doc BlocksDocument
+		if err := dec.Decode(&doc); err != nil {
+			t.Fatalf("decoding %q: %v", out.String(), err)
+		}
+		want := []ContentBlockRecord{{Type: BlockText, Text: text}}
+		if !reflect.DeepEqual(doc.Blocks, want) {
+			t.Errorf("got %+v, want %+v", doc.Blocks, want)
+		}
+	}
+}
+
+func TestContentBlocks_EmptySession(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	out := &bytes.Buffer{}
+	d.BlocksWriter = out
+	d.HandleEvent(successResult())
+
+	if want := `{"is_error":false,"blocks":[]}` + "\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+// interleavedBlocksSession returns a session of several turns, each with text
+// and a tool call, ending in a result.
+func interleavedBlocksSession() []events.Event {
+	var evts []events.Event
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("t%d", i)
+		evts = append(evts, streamTextEvents("Step ", strings.Repeat("x", 40))...)
+		evts = append(evts,
+			toolUseEvent(id, "Bash", map[string]interface{}{"command": "make " + id}),
+			toolResultEvent(id, "output of "+id, false),
+			streamEvent("message_stop"),
+		)
+	}
+	return append(evts, successResult())
+}
+
+func TestContentBlocks_SpillMatchesInMemory(t *testing.T) {
+	var outputs []string
+	spillDir := t.TempDir()
+	for _, spillBytes := range []int{0, 50} {
+		d, _ := newBufferedDisplay(VerbosityNormal)
+		out := &bytes.Buffer{}
+		d.BlocksWriter = out
+		d.BlocksSpillBytes = spillBytes
+		d.BlocksSpillDir = spillDir
+
+		// The session ID needs escaping, which both encodings must agree on
+		evts := interleavedBlocksSession()
+		result := successResult()
+		result.SessionID = `s"1<&>`
+		evts[len(evts)-1] = result
+
+		var spillFile string
+		for _, e := range evts {
+			d.HandleEvent(e)
+			if d.State.BlocksSpill != nil {
+				spillFile = d.State.BlocksSpill.file.Name()
+				if d.State.BlocksBytes > spillBytes+100 {
+					t.Errorf("spill %d: %d bytes held in memory", spillBytes, d.State.BlocksBytes)
+				}
+			}
+		}
+		if spillBytes > 0 {
+			if spillFile == "" || filepath.Dir(spillFile) != spillDir {
+				t.Fatalf("expected blocks to be spilled to %s, got %q", spillDir, spillFile)
+			}
+			if _, err := os.Stat(spillFile); !os.IsNotExist(err) {
+				t.Errorf("spill file %s not removed: %v", spillFile, err)
+			}
+		}
+		outputs = append(outputs, out.String())
+	}
+
+	if outputs[0] != outputs[1] {
+		t.Errorf("spilled output differs:\n in memory: %s\n spilled:   %s", outputs[0], outputs[1])
+	}
+	var doc BlocksDocument
+	if err := json.Unmarshal([]byte(outputs[1]), &doc); err != nil || len(doc.Blocks) != 15 {
+		t.Errorf("expected 15 blocks in valid JSON, got %d (%v)", len(doc.Blocks), err)
+	}
+}
+
+func TestFinishBlocks_WritesUnfinishedSession(t *testing.T) {
+	for _, spillBytes := range []int{0, 50} {
+		d, _ := newBufferedDisplay(VerbosityNormal)
+		out := &bytes.Buffer{}
+		d.BlocksWriter = out
+		d.BlocksSpillBytes = spillBytes
+		d.BlocksSpillDir = t.TempDir()
+
+		// The session is interrupted before its result event
+		evts := interleavedBlocksSession()
+		for _, e := range evts[:len(evts)-1] {
+			d.HandleEvent(e)
+		}
+		d.FinishBlocks("s1")
+
+		var doc BlocksDocument
+		if err := json.Unmarshal(out.Bytes(), &doc); err != nil || len(doc.Blocks) != 15 {
+			t.Fatalf("spill %d: expected the 15 collected blocks in valid JSON, got %d (%v)", spillBytes, len(doc.Blocks), err)
+		}
+		if doc.SessionID != "s1" || !doc.IsError {
+			t.Errorf("spill %d: expected an error document for s1, got %q, %v", spillBytes, doc.SessionID, doc.IsError)
+		}
+		if d.State.BlocksSpill != nil || d.State.BlockCount != 0 {
+			t.Errorf("spill %d: expected the blocks dropped after writing", spillBytes)
+		}
+	}
+
+	// A session that got its result was written already
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	out := &bytes.Buffer{}
+	d.BlocksWriter = out
+	for _, e := range interleavedBlocksSession() {
+		d.HandleEvent(e)
+	}
+	d.FinishBlocks("s1")
+	if lines := strings.Count(out.String(), "\n"); lines != 1 {
+		t.Errorf("expected one document for a finished session, got %d", lines)
+	}
+}
+
+func TestContentBlocks_WriteError(t *testing.T) {
+	for _, spillBytes := range []int{0, 50} {
+		d, buf := newBufferedDisplay(VerbosityNormal)
+		d.BlocksWriter = failWriter{}
+		d.BlocksSpillBytes = spillBytes
+		d.BlocksSpillDir = t.TempDir()
+		for _, e := range interleavedBlocksSession() {
+			d.HandleEvent(e)
+		}
+		if !strings.Contains(buf.String(), "--blocks-json: writing the session's blocks failed: disk full") {
+			t.Errorf("spill %d: expected the write error reported, got:\n%s", spillBytes, buf.String())
+		}
+	}
+}
+
+func TestDisplay_NoTextAccumulatedWithoutCapture(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	for _, e := range interleavedBlocksSession() {
+		d.HandleEvent(e)
+	}
+	if d.State.Blocks != nil || d.State.BlocksBytes != 0 {
+		t.Errorf("blocks recorded without --blocks-json: %d blocks", len(d.State.Blocks))
+	}
+}
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// castHeader is the first line of an asciinema v2 cast file.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastWriter passes writes through to an underlying writer while recording
+// each one, with its offset from the start of the recording, as an output
+// event in an asciinema v2 cast. Close must be called to flush the cast.
+type CastWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	cast  *bufio.Writer
+	file  io.Closer
+	start time.Time
+}
+
+// NewCastWriter writes the cast header to castFile and returns a CastWriter
+// that forwards to w. width and height describe the recorded terminal;
+// non-positive values default to 80x24. castFile is closed by Close.
+func NewCastWriter(w io.Writer, castFile io.WriteCloser, width, height int) (*CastWriter, error) {
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	c := &CastWriter{
+		w:     w,
+		cast:  bufio.NewWriter(castFile),
+		file:  castFile,
+		start: time.Now(),
+	}
+	header := castHeader{
+		Version:   2,
+		Width:     width,
+		Height:    height,
+		Timestamp: c.start.Unix(),
+		Env:       map[string]string{"TERM": os.Getenv("TERM"), "SHELL": os.Getenv("SHELL")},
+	}
+	if err := c.writeLine(header); err != nil {
+		castFile.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Write forwards p to the underlying writer and records it in the cast.
+// Bare newlines are recorded as CRLF, as a terminal would render them.
+// Recording errors are ignored so they never interrupt the display.
+func (c *CastWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cast != nil {
+		elapsed := time.Since(c.start).Seconds()
+		data := strings.ReplaceAll(string(p), "\r\n", "\n")
+		data = strings.ReplaceAll(data, "\n", "\r\n")
+		_ = c.writeLine([]interface{}{elapsed, "o", data})
+	}
+	return c.w.Write(p)
+}
+
+// writeLine appends v to the cast as a single JSON line.
+func (c *CastWriter) writeLine(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := c.cast.Write(data); err != nil {
+		return err
+	}
+	return c.cast.WriteByte('\n')
+}
+
+// Close flushes and closes the cast file. Writes after Close are forwarded
+// but no longer recorded.
+func (c *CastWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cast == nil {
+		return nil
+	}
+	err := c.cast.Flush()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	c.cast = nil
+	return err
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// nopCloser adapts a bytes.Buffer to io.WriteCloser.
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func TestCastWriter(t *testing.T) {
+	var display, cast bytes.Buffer
+	c, err := NewCastWriter(&display, nopCloser{&cast}, 0, 0)
+	if err != nil {
+		t.Fatalf("NewCastWriter: %v", err)
+	}
+
+	c.Write([]byte("\x1b[32mhello\x1b[0m\n"))
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	c.Write([]byte("after close"))
+
+	if display.String() != "\x1b[32mhello\x1b[0m\nafter close" {
+		t.Errorf("display output = %q", display.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(cast.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one event, got %d lines:\n%s", len(lines), cast.String())
+	}
+
+	var header castHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if header.Version != 2 || header.Width != 80 || header.Height != 24 {
+		t.Errorf("unexpected header: %+v", header)
+	}
+
+	var event []interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatalf("event: %v", err)
+	}
+	if len(event) != 3 || event[1] != "o" || event[2] != "\x1b[32mhello\x1b[0m\r\n" {
+		t.Errorf("unexpected event: %v", event)
+	}
+	if _, ok := event[0].(float64); !ok {
+		t.Errorf("expected numeric timestamp, got %v", event[0])
+	}
+}
@@ -0,0 +1,94 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// CoalescingWriter buffers writes and flushes them to the underlying writer
+// on a fixed interval. This reduces syscalls and terminal flicker when text
+// deltas arrive faster than a human can read them.
+//
+// Flush may be called at any time to force buffered output through (for
+// example at the end of a content block). Close flushes any remaining output
+// and stops the background ticker; writes after Close go straight through.
+type CoalescingWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	buf    bytes.Buffer
+	closed bool
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewCoalescingWriter creates a CoalescingWriter that flushes to w every interval.
+func NewCoalescingWriter(w io.Writer, interval time.Duration) *CoalescingWriter {
+	c := &CoalescingWriter{
+		w:    w,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go c.loop(interval)
+	return c
+}
+
+// loop flushes the buffer on each tick until Close is called.
+func (c *CoalescingWriter) loop(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = c.Flush()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Write appends p to the buffer. It never blocks on the underlying writer
+// unless the CoalescingWriter has been closed.
+func (c *CoalescingWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return c.w.Write(p)
+	}
+	return c.buf.Write(p)
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (c *CoalescingWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+// flushLocked writes the buffer to the underlying writer. Caller must hold mu.
+func (c *CoalescingWriter) flushLocked() error {
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	_, err := c.w.Write(c.buf.Bytes())
+	c.buf.Reset()
+	return err
+}
+
+// Close stops the flush ticker and writes any remaining buffered output.
+func (c *CoalescingWriter) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	err := c.flushLocked()
+	c.mu.Unlock()
+
+	close(c.stop)
+	<-c.done
+	return err
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use by the flush goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func TestCoalescingWriter_BuffersUntilFlush(t *testing.T) {
+	out := &syncBuffer{}
+	c := NewCoalescingWriter(out, time.Hour)
+	defer c.Close()
+
+	c.Write([]byte("hello "))
+	c.Write([]byte("world"))
+	if out.String() != "" {
+		t.Fatalf("expected no output before flush, got %q", out.String())
+	}
+
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if out.String() != "hello world" {
+		t.Errorf("expected %q, got %q", "hello world", out.String())
+	}
+}
+
+func TestCoalescingWriter_FlushesOnInterval(t *testing.T) {
+	out := &syncBuffer{}
+	c := NewCoalescingWriter(out, 5*time.Millisecond)
+	defer c.Close()
+
+	c.Write([]byte("tick"))
+	deadline := time.Now().Add(time.Second)
+	for out.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if out.String() != "tick" {
+		t.Errorf("expected interval flush to write %q, got %q", "tick", out.String())
+	}
+}
+
+func TestCoalescingWriter_CloseNeverLosesText(t *testing.T) {
+	out := &syncBuffer{}
+	c := NewCoalescingWriter(out, time.Hour)
+
+	for i := 0; i < 100; i++ {
+		c.Write([]byte("x"))
+	}
+	c.Close()
+	c.Write([]byte("!"))
+
+	want := string(bytes.Repeat([]byte("x"), 100)) + "!"
+	if out.String() != want {
+		t.Errorf("expected all text after Close, got %d bytes", len(out.String()))
+	}
+}
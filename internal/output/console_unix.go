@@ -0,0 +1,9 @@
+//go:build !windows
+
+package output
+
+// EnableUTF8Console is a no-op outside Windows, where terminals take UTF-8
+// output as-is.
+func EnableUTF8Console() (restore func(), ok bool) {
+	return func() {}, true
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package output
+
+import "syscall"
+
+// cpUTF8 is the Windows code page identifier for UTF-8.
+const cpUTF8 = 65001
+
+var (
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleOutputCP = kernel32.NewProc("GetConsoleOutputCP")
+	procSetConsoleOutputCP = kernel32.NewProc("SetConsoleOutputCP")
+)
+
+// EnableUTF8Console switches the console output code page to UTF-8 so
+// glyphs, emoji, and non-ASCII text render correctly. It reports false if
+// the code page could not be set (e.g. no console is attached); restore puts
+// back the previous code page, since the setting outlives the process.
+func EnableUTF8Console() (restore func(), ok bool) {
+	previous, _, _ := procGetConsoleOutputCP.Call()
+	if previous == cpUTF8 {
+		return func() {}, true
+	}
+	if r, _, _ := procSetConsoleOutputCP.Call(cpUTF8); r == 0 {
+		return func() {}, false
+	}
+	return func() {
+		if previous != 0 {
+			procSetConsoleOutputCP.Call(previous)
+		}
+	}, true
+}
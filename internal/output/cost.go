@@ -0,0 +1,110 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// modelPrice is a model family's list price in USD per million tokens.
+type modelPrice struct {
+	family string
+	input  float64
+	output float64
+}
+
+// modelPrices are matched in order against the model name. Estimates err on
+// the high side (e.g. older Opus pricing) since they gate spending.
+var modelPrices = []modelPrice{
+	{"opus", 15, 75},
+	{"sonnet", 3, 15},
+	{"haiku", 1, 5},
+}
+
+// fallbackPrice prices models that match no known family.
+var fallbackPrice = modelPrice{"", 3, 15}
+
+// Cache reads and writes are priced relative to the input price.
+const (
+	cacheReadMultiplier  = 0.1
+	cacheWriteMultiplier = 1.25
+)
+
+// estimateCostUSD estimates the list-price cost of one message's usage.
+func estimateCostUSD(model string, u events.Usage) float64 {
+	price := fallbackPrice
+	for _, p := range modelPrices {
+		if strings.Contains(strings.ToLower(model), p.family) {
+			price = p
+			break
+		}
+	}
+	input := float64(u.InputTokens) +
+		float64(u.CacheReadInputTokens)*cacheReadMultiplier +
+		float64(u.CacheCreationInputTokens)*cacheWriteMultiplier
+	return (input*price.input + float64(u.OutputTokens)*price.output) / 1e6
+}
+
+// trackEstimatedCost accumulates an estimate of the session's cost from each
+// message's usage, since the real cost only arrives with the result event.
+func (d *Display) trackEstimatedCost(event events.Event) {
+	e, ok := event.(events.StreamEvent)
+	if !ok {
+		return
+	}
+	switch e.Event.Type {
+	case "message_start":
+		d.State.CostModel = ""
+		d.State.CostUsage = events.Usage{}
+		if m := e.Event.Message; m != nil {
+			d.State.CostModel = m.Model
+			if m.Usage != nil {
+				d.State.CostUsage = *m.Usage
+			}
+		}
+	case "message_delta":
+		if u := e.Event.Usage; u != nil && u.OutputTokens > 0 {
+			d.State.CostUsage.OutputTokens = u.OutputTokens
+		}
+	case "message_stop":
+		d.State.EstimatedCostUSD += estimateCostUSD(d.State.CostModel, d.State.CostUsage)
+		d.State.CostUsage = events.Usage{}
+	}
+}
+
+// checkCostGate asks ConfirmCost once the estimated cost reaches the next
+// multiple of ConfirmCostUSD. Declining is recorded for CostDeclined; event
+// handling is paused while ConfirmCost waits for an answer.
+func (d *Display) checkCostGate() {
+	if d.ConfirmCostUSD <= 0 || d.ConfirmCost == nil || d.State.CostDeclined {
+		return
+	}
+	if d.State.NextCostConfirmUSD == 0 {
+		d.State.NextCostConfirmUSD = d.ConfirmCostUSD
+	}
+	cost := d.State.EstimatedCostUSD
+	if cost < d.State.NextCostConfirmUSD {
+		return
+	}
+
+	d.Spinner.Stop()
+	d.flush()
+	if !d.ConfirmCost(cost) {
+		d.State.CostDeclined = true
+		return
+	}
+	for d.State.NextCostConfirmUSD <= cost {
+		d.State.NextCostConfirmUSD += d.ConfirmCostUSD
+	}
+}
+
+// EstimatedCostUSD returns the estimated cost of the messages seen so far.
+func (d *Display) EstimatedCostUSD() float64 {
+	return d.State.EstimatedCostUSD
+}
+
+// CostDeclined reports whether the user declined to continue at a
+// --confirm-cost prompt.
+func (d *Display) CostDeclined() bool {
+	return d.State.CostDeclined
+}
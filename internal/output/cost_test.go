@@ -0,0 +1,62 @@
+package output
+
+import (
+	"math"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestEstimateCostUSD(t *testing.T) {
+	tests := []struct {
+		model string
+		usage events.Usage
+		want  float64
+	}{
+		{"claude-sonnet-4-20250514", events.Usage{InputTokens: 1_000_000, OutputTokens: 100_000}, 4.50},
+		{"claude-opus-4-1", events.Usage{InputTokens: 100_000}, 1.50},
+		{"claude-haiku-4-5", events.Usage{CacheReadInputTokens: 1_000_000, CacheCreationInputTokens: 1_000_000}, 0.10 + 1.25},
+		{"some-other-model", events.Usage{OutputTokens: 1_000_000}, 15},
+	}
+	for _, tt := range tests {
+		if got := estimateCostUSD(tt.model, tt.usage); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("estimateCostUSD(%q, %+v) = %v, want %v", tt.model, tt.usage, got, tt.want)
+		}
+	}
+}
+
+func TestConfirmCost_AsksAtEachMultiple(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	d.ConfirmCostUSD = 1
+	var asked []float64
+	answers := []bool{true, false}
+	d.ConfirmCost = func(costUSD float64) bool {
+		asked = append(asked, costUSD)
+		return answers[len(asked)-1]
+	}
+
+	// Each turn is about $1.20 at fallback (Sonnet) pricing
+	for turn := 0; turn < 3; turn++ {
+		for _, e := range usageTurnEvents(400_000, 0) {
+			d.HandleEvent(e)
+		}
+	}
+
+	if len(asked) != 2 {
+		t.Fatalf("expected 2 prompts (at $1 and $2), got %v", asked)
+	}
+	if !d.CostDeclined() {
+		t.Error("expected the run to be declined after answering no")
+	}
+}
+
+func TestConfirmCost_DisabledWithoutCallback(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	d.ConfirmCostUSD = 0.01
+	for _, e := range usageTurnEvents(400_000, 0) {
+		d.HandleEvent(e)
+	}
+	if d.CostDeclined() || d.EstimatedCostUSD() == 0 {
+		t.Errorf("expected cost tracking without a prompt, got declined=%v cost=%v", d.CostDeclined(), d.EstimatedCostUSD())
+	}
+}
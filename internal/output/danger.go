@@ -0,0 +1,70 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultDangerousPatterns are regular expressions for obviously destructive
+// shell commands. A Bash tool call matching one is flagged with a warning.
+var DefaultDangerousPatterns = []string{
+	// rm -rf of /, /*, ~ or $HOME
+	`\brm\s+(-\S+\s+)*-[a-zA-Z]*[rR][a-zA-Z]*\s+(-\S+\s+)*(/\*?|~/?|\$HOME/?)(\s|;|&|\||$)`,
+	// dd onto a device
+	`\bdd\b.*\bof=/dev/`,
+	// format a filesystem
+	`\bmkfs(\.\w+)?\b`,
+	// fork bomb
+	`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`,
+	// redirect onto a raw disk
+	`>\s*/dev/(sd[a-z]|nvme\d|hd[a-z]|disk\d)`,
+}
+
+// CompileDangerousPatterns compiles patterns for Display.DangerousPatterns,
+// reporting the first invalid one.
+func CompileDangerousPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dangerous command pattern %q: %w", p, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// defaultDangerousPatterns is the compiled form of DefaultDangerousPatterns.
+var defaultDangerousPatterns = func() []*regexp.Regexp {
+	compiled, err := CompileDangerousPatterns(DefaultDangerousPatterns)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}()
+
+// dangerousPatterns returns the configured patterns, or the defaults.
+func (d *Display) dangerousPatterns() []*regexp.Regexp {
+	if d.DangerousPatterns != nil {
+		return d.DangerousPatterns
+	}
+	return defaultDangerousPatterns
+}
+
+// warnIfDangerous shows a red warning under a Bash tool call whose command
+// matches a dangerous pattern. Display only: the command is not blocked.
+func (d *Display) warnIfDangerous(toolName string, input map[string]interface{}) {
+	if toolName != "Bash" && toolName != "bash" {
+		return
+	}
+	command, ok := input["command"].(string)
+	if !ok {
+		return
+	}
+	for _, re := range d.dangerousPatterns() {
+		if re.MatchString(command) {
+			d.Formatter.ErrorWithEmoji(EmojiWarning, "%sDangerous command: %s", TreeBranch, command)
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDangerousCommandWarning(t *testing.T) {
+	tests := []struct {
+		command   string
+		dangerous bool
+	}{
+		{"rm -rf /", true},
+		{"sudo rm -fr /*", true},
+		{"rm -r -f ~", true},
+		{"rm -rf $HOME/", true},
+		{"dd if=/dev/zero of=/dev/sda bs=1M", true},
+		{"mkfs.ext4 /dev/sdb1", true},
+		{":(){ :|:& };:", true},
+		{"cat image.iso > /dev/sdb", true},
+		{"rm -rf ./build", false},
+		{"rm -rf /tmp/cache", false},
+		{"dd if=in.img of=out.img", false},
+		{"go test ./...", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			d, buf := newBufferedDisplay(VerbosityNormal)
+			d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": tt.command}))
+			got := strings.Contains(buf.String(), "Dangerous command")
+			if got != tt.dangerous {
+				t.Errorf("dangerous = %v, want %v; output:\n%s", got, tt.dangerous, buf.String())
+			}
+		})
+	}
+}
+
+func TestDangerousCommandWarning_CustomPatterns(t *testing.T) {
+	patterns, err := CompileDangerousPatterns([]string{`\bgit\s+push\s+--force\b`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.DangerousPatterns = patterns
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "git push --force origin main"}))
+	d.HandleEvent(toolUseEvent("b2", "Bash", map[string]interface{}{"command": "rm -rf /"}))
+
+	if strings.Count(buf.String(), "Dangerous command") != 1 || !strings.Contains(buf.String(), "Dangerous command: git push") {
+		t.Errorf("expected only the custom pattern to match, got:\n%s", buf.String())
+	}
+
+	if _, err := CompileDangerousPatterns([]string{"("}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -1,11 +1,18 @@
 package output
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/peakflames/claude-print/internal/events"
 )
@@ -22,13 +29,42 @@ const (
 	VerbosityVerbose
 )
 
-// Visual indicators for Claude Code style output
-const (
-	Bullet     = "\u25cf"     // ● solid circle
-	TreeBranch = "  \u23bf  " // ⎿ indented tree branch for results
-	UserPrefix = "> User: "
+// Visual indicators for Claude Code style output. The glyphs are swapped for
+// ASCII by UseASCIIGlyphs when the console can't render them.
+var (
+	Bullet      = "\u25cf"       // ● solid circle
+	TreeBranch  = "  \u23bf  "   // ⎿ indented tree branch for results
+	StatusGlyph = "\u27f3"       // ⟳ tools-running indicator
+	Rule        = "\u2500\u2500" // ── marker line segment
+	Unprintable = "\ufffd"       // � stands in for bytes in tool output that can't be shown
+	Ellipsis    = "\u2026"       // … marks a sub-agent result cut to its first line
+
+	// TodoWrite checklist markers, by todo status
+	TodoPending    = "\u2610" // ☐ pending
+	TodoInProgress = "\u25d0" // ◐ in_progress
+	TodoCompleted  = "\u2611" // ☑ completed
 )
 
+// UserPrefix marks the user's prompt.
+const UserPrefix = "> User: "
+
+// HiddenPrompt replaces the prompt in display output when HidePrompt is set.
+const HiddenPrompt = "[prompt hidden]"
+
+// UseASCIIGlyphs replaces the Unicode indicator glyphs with ASCII ones, for
+// consoles that can't display Unicode. Call it before any output.
+func UseASCIIGlyphs() {
+	Bullet = "*"
+	TreeBranch = "  |_ "
+	StatusGlyph = "~"
+	Rule = "--"
+	Unprintable = "?"
+	Ellipsis = "..."
+	TodoPending = "[ ]"
+	TodoInProgress = "[~]"
+	TodoCompleted = "[x]"
+}
+
 // Legacy emojis kept for error handling compatibility
 const (
 	EmojiError   = "\u274c"       // ❌
@@ -38,46 +74,272 @@ const (
 
 // PendingToolCall tracks a tool invocation awaiting its result
 type PendingToolCall struct {
-	ID    string
-	Name  string
-	Input map[string]interface{}
+	ID        string
+	Name      string
+	Input     map[string]interface{}
+	StartedAt time.Time // When the full tool call was seen; zero if unknown
+}
+
+// CompletedToolCall records a tool call whose result has been shown, so a
+// tool_result repeated for the same ID can be recognized. Only a digest of
+// the result is kept, so long sessions don't hold every tool's output.
+type CompletedToolCall struct {
+	Name    string
+	Digest  [sha256.Size]byte
+	IsError bool
 }
 
 // DisplayState tracks state across events
 type DisplayState struct {
 	UserPrompt              string
 	PendingTools            map[string]*PendingToolCall
-	LastOutputWasText       bool // Track if we need newline before tool output
-	InTextBlock             bool // Track if we're currently in a text block
-	LastMessageWasToolUse   bool // Track if last message was tool use (suppress extra newline)
-	ToolResultJustDisplayed bool // Track if we just showed a tool result
+	CompletedTools          map[string]*CompletedToolCall
+	LastOutputWasText       bool                     // A text block was the last output (blank line before a tool call)
+	InTextBlock             bool                     // Track if we're currently in a text block
+	TextColumn              int                      // Column reached by streamed text (for --wrap)
+	LastMessageWasToolUse   bool                     // Track if last message was tool use (suppress extra newline)
+	ToolResultJustDisplayed bool                     // Track if we just showed a tool result
+	FilesRead               []string                 // Files read during the session, in first-seen order
+	FilesModified           []string                 // Files written or edited during the session, in first-seen order
+	LastToolSignature       string                   // Tool name and canonical input of the last tool call
+	ToolRepeats             int                      // Consecutive calls matching LastToolSignature
+	ToolLoop                string                   // Description of a detected tool loop ("" if none)
+	TurnsCompleted          int                      // Assistant turns completed in the current session
+	TurnProgressPending     bool                     // Turn progress line waiting to be shown
+	ToolTime                map[string]time.Duration // Total call-to-result time per tool name
+	DeniedTools             map[string]int           // Denied tool calls per tool name this session
+	SessionStart            time.Time                // First event of the current session (for RelativeTime)
+	CurrentTurn             TurnRecord               // Assistant turn in progress
+	Turns                   []TurnRecord             // Completed turns, by turn index
+	CostModel               string                   // Model of the message in progress, for cost estimates
+	CostUsage               events.Usage             // Usage of the message in progress, for cost estimates
+	EstimatedCostUSD        float64                  // Estimated cost of completed messages
+	NextCostConfirmUSD      float64                  // Estimated cost at which ConfirmCost is next asked
+	CostDeclined            bool                     // The user declined to continue at a cost prompt
+	ToolStatusShown         bool                     // The "N tools running" line is on screen
+	TextEmitted             bool                     // Non-whitespace assistant text was streamed this session
+	ToolCallCount           int                      // Tool calls made this session
+	AuditCalls              map[string]*auditCall    // Tool calls awaiting their audit log line, by tool_use ID
+	TableRows               []string                 // Markdown table rows held for drawing (--render-tables)
+	TablePending            string                   // Streamed text not yet written or held as a table row
+	TableMidLine            bool                     // Streamed text is mid-way through a line that isn't a table row
+	Blocks                  []ContentBlockRecord     // Content blocks of the session held in memory (--blocks-json)
+	BlockTextOpen           bool                     // Streamed text extends the last text block in Blocks
+	BlockTurn               int                      // Assistant turn the next block belongs to
+	BlockCount              int                      // Blocks recorded this session, including spilled ones
+	BlockToolTurns          map[string]int           // Turn of each recorded tool_use, by ID
+	BlocksBytes             int                      // Estimated size of the blocks in Blocks
+	BlocksSpill             *blocksSpill             // Temp file holding blocks moved out of memory, if any
+	SubagentParents         map[string]string        // Parent Task tool_use ID of each sub-agent tool call, by ID
+	EditCalls               map[string]string        // File of each Write/Edit call awaiting its result, by tool_use ID (--on-edit)
+}
+
+// startDetail is a labeled run setting shown in the start banner.
+type startDetail struct {
+	label string
+	value string
 }
 
 // Display handles event display with configurable verbosity and formatting.
+// All human-readable output goes through Formatter.Writer (see Writer), so
+// redirecting the formatter redirects the whole display.
 type Display struct {
 	Formatter  *Formatter
 	Verbosity  Verbosity
-	Writer     io.Writer
 	JSONWriter io.Writer // When non-nil, structured JSON events are written here
 	State      *DisplayState
+
+	// EventWriter, when non-nil, receives every parsed event re-serialized
+	// inside an envelope, one JSON line per event (--stream-json-out).
+	EventWriter io.Writer
+	// EventFieldPrefix is prepended to each envelope field name.
+	EventFieldPrefix string
+	eventSeq         int
+
+	// Headless tracks events (loop guard, cost check, audit log, edit hooks,
+	// content blocks) without rendering them, for runs whose output is
+	// written by the caller (--raw-events, --quiet --json).
+	Headless bool
+
+	startDetails []startDetail
+	width        atomic.Int64 // Terminal width in columns; 0 when unknown
+
+	// ShowFileSummary renders a "Files changed" section in the result summary.
+	ShowFileSummary bool
+
+	// WarnCostUSD and WarnDurationMS highlight the summary's cost and total
+	// duration in yellow when exceeded (red at 2x). Zero disables each check.
+	WarnCostUSD    float64
+	WarnDurationMS int64
+
+	// HideToolOutput suppresses successful tool result lines while keeping
+	// tool call lines and errors visible (--no-tool-output).
+	HideToolOutput bool
+
+	// ShowMetadata shows a one-line session summary (model, tool and MCP
+	// server counts) in normal mode. Verbose mode always shows full metadata.
+	ShowMetadata bool
+
+	// ShowLabels prefixes assistant text and tool calls with speaker labels
+	// (--labels). AssistantLabel and ToolLabel override the defaults; "{tool}"
+	// in ToolLabel is replaced by the tool name.
+	ShowLabels     bool
+	AssistantLabel string
+	ToolLabel      string
+
+	// Spinner, when set, animates while quiet mode is waiting and is stopped
+	// before any quiet output is written.
+	Spinner *Spinner
+
+	// DangerousPatterns flag Bash commands with a red warning when shown.
+	// Nil uses DefaultDangerousPatterns (see CompileDangerousPatterns).
+	DangerousPatterns []*regexp.Regexp
+
+	// MaxTurns, when positive, shows "Turn N/M" progress as each assistant
+	// turn completes (normal and verbose modes). Set from --max-turns.
+	MaxTurns int
+
+	// LoopGuard flags a tool loop (see ToolLoop) once the same tool and input
+	// repeat more than this many times in a row. Zero disables detection.
+	LoopGuard int
+
+	// SummaryTemplate, when set, formats the completion status line using
+	// {status}, {turns}, {cost}, {total_duration}, {api_duration}, {in} and
+	// {out} placeholders instead of the built-in format.
+	SummaryTemplate string
+
+	// SummaryFields selects and orders the fields of the completion status
+	// line, by the names in config.SummaryFieldNames. Empty shows
+	// config.DefaultSummaryFields. Unknown names are skipped (config
+	// validation rejects them up front).
+	SummaryFields []string
+
+	// ShowCachedTokens splits the summary's input token figure into its
+	// cache-read share: "1200 in (900 cached) / 300 out", where the input
+	// total then includes the cache reads, as does SummaryTemplate's {in}.
+	ShowCachedTokens bool
+
+	// MaxToolParamBytes caps each tool parameter value kept in PendingTools
+	// and rendered in verbose mode. Zero uses DefaultMaxToolParamBytes.
+	MaxToolParamBytes int
+
+	// IndentWidth is the number of spaces per nesting level in detail lines.
+	// Zero uses DefaultIndentWidth.
+	IndentWidth int
+
+	// MatchLimit caps the Grep/Glob matches listed in verbose mode
+	// (DefaultMatchLimit when zero).
+	MatchLimit int
+
+	// ConfirmCostUSD, when positive with ConfirmCost set, pauses event
+	// handling each time the estimated cost passes another multiple of it and
+	// calls ConfirmCost with the estimate; returning false records the run as
+	// declined (see CostDeclined) so the caller can stop it.
+	ConfirmCostUSD float64
+	ConfirmCost    func(costUSD float64) bool
+
+	// ShowToolStatus keeps a "⟳ N tools running" line at the bottom of
+	// the display while tool calls await results (normal and verbose modes).
+	// It uses carriage returns and erase-line codes, so enable it only for
+	// terminals.
+	ShowToolStatus bool
+
+	// EditHooks, when set, runs the --on-edit command for each file written
+	// or edited successfully; failures are shown as warnings.
+	EditHooks *EditHooks
+
+	// Thinking, when set, shows a "thinking..." placeholder in normal mode
+	// while no events arrive between tool results and the next assistant
+	// output. It is erased before any other output is written.
+	Thinking *ThinkingIndicator
+
+	// GitBranch and GitCommit tie the run to the working tree's repo state
+	// (--git-context). When set they appear in the verbose statistics and
+	// the --stream-json result event.
+	GitBranch string
+	GitCommit string
+
+	// Wrap breaks streamed text at the terminal width (--wrap). It has no
+	// effect when the width is unknown.
+	Wrap bool
+
+	// AuditLog, when non-nil, receives one JSON line per tool call with its
+	// key parameters (secrets redacted) and whether it succeeded, errored or
+	// was denied (--audit-log). It is independent of verbosity.
+	AuditLog io.Writer
+
+	// RenderTables redraws markdown tables in streamed text as bordered,
+	// aligned terminal tables (--render-tables). Table rows are held until
+	// the table ends, so enable it only for terminals.
+	RenderTables bool
+
+	// HidePrompt shows HiddenPrompt in place of the prompt in the start
+	// banner and batch report (--hide-prompt), e.g. when recording a prompt
+	// that embeds credentials. Claude still receives the real prompt.
+	HidePrompt bool
+
+	// RelativeTime appends the time since the session's first event to each
+	// tool result line, e.g. "⎿  Read 120 lines (+4.2s)" (--relative-time).
+	RelativeTime bool
+
+	// RawBashOutput shows Bash tool results in verbose mode in full, with
+	// their ANSI colors, instead of truncating them (--raw-bash-output).
+	// Sequences other than colors and attributes are removed unless
+	// UnsafeRawBashOutput is set. Colors are stripped when the formatter has
+	// color disabled.
+	RawBashOutput       bool
+	UnsafeRawBashOutput bool
+
+	// BlocksWriter, when non-nil, receives one JSON line per session (a
+	// BlocksDocument) listing its text, tool_use and tool_result blocks in
+	// order (--blocks-json).
+	BlocksWriter io.Writer
+	// BlocksSpillBytes, when positive, moves the recorded blocks to a temp
+	// file whenever they hold more than this many bytes, so long sessions
+	// don't keep their whole transcript in memory.
+	BlocksSpillBytes int
+	// BlocksSpillDir is the directory spill files are created in; empty
+	// means the system temp directory.
+	BlocksSpillDir string
+
+	// Sessions, when non-nil, records how each Claude session ended (see
+	// RecordSession).
+	Sessions *SessionLog
 }
 
 // NewDisplay creates a new Display with the specified settings.
+// If formatter is nil, or has no writer, output defaults to os.Stdout.
 func NewDisplay(formatter *Formatter, verbosity Verbosity) *Display {
-	var writer io.Writer = os.Stdout
-	if formatter != nil && formatter.Writer != nil {
-		writer = formatter.Writer
+	if formatter == nil {
+		formatter = NewFormatter(false, false, os.Stdout)
+	} else if formatter.Writer == nil {
+		formatter.Writer = os.Stdout
 	}
 	return &Display{
 		Formatter: formatter,
 		Verbosity: verbosity,
-		Writer:    writer,
 		State: &DisplayState{
-			PendingTools: make(map[string]*PendingToolCall),
+			PendingTools:   make(map[string]*PendingToolCall),
+			CompletedTools: make(map[string]*CompletedToolCall),
 		},
 	}
 }
 
+// NewDisplayWithWriters creates a Display and its Formatter bound to explicit
+// writers: human-readable output goes to displayWriter and, if jsonWriter is
+// non-nil, structured JSON events go to jsonWriter. This is the injection
+// point for tests and embedders that capture or redirect output.
+func NewDisplayWithWriters(displayWriter, jsonWriter io.Writer, colorEnabled, emojiEnabled bool, verbosity Verbosity) *Display {
+	d := NewDisplay(NewFormatter(colorEnabled, emojiEnabled, displayWriter), verbosity)
+	d.JSONWriter = jsonWriter
+	return d
+}
+
+// Writer returns the writer all human-readable display output goes to.
+func (d *Display) Writer() io.Writer {
+	return d.Formatter.Writer
+}
+
 // SetUserPrompt sets the user prompt for display in the header
 func (d *Display) SetUserPrompt(prompt string) {
 	d.State.UserPrompt = prompt
@@ -87,18 +349,46 @@ func (d *Display) SetUserPrompt(prompt string) {
 // based on the current verbosity level. If JSONWriter is set, a structured
 // JSON event is also emitted before the display handler runs.
 func (d *Display) HandleEvent(event events.Event) {
+	if d.State.SessionStart.IsZero() {
+		d.State.SessionStart = time.Now()
+	}
+
 	// Emit structured JSON before display handlers so PendingTools is still
 	// populated when we need tool name lookups for tool_result events.
 	d.emitJSONForEvent(event)
-
-	switch d.Verbosity {
-	case VerbosityQuiet:
+	d.emitEventEnvelope(event)
+	d.trackToolLoop(event)
+	d.trackAuditLog(event)
+	d.trackResponseText(event)
+	d.trackContentBlocks(event)
+	d.trackEstimatedCost(event)
+	d.trackFileEdits(event)
+	d.ClearToolStatus()
+	d.reportEditHooks(event)
+
+	switch {
+	case d.Headless:
+	case d.Verbosity == VerbosityQuiet:
 		d.handleQuietEvent(event)
-	case VerbosityNormal:
+	case d.Verbosity == VerbosityNormal:
 		d.handleNormalEvent(event)
-	case VerbosityVerbose:
+	case d.Verbosity == VerbosityVerbose:
 		d.handleVerboseEvent(event)
 	}
+
+	// Ask about cost only after the event that raised it is displayed
+	d.checkCostGate()
+	d.drawToolStatus(event)
+	d.armThinking(event)
+
+	// The next session's clock starts with its first event, and its tool
+	// time and file lists from zero
+	if _, ok := event.(events.ResultEvent); ok {
+		d.State.SessionStart = time.Time{}
+		d.State.ToolTime = nil
+		d.State.FilesRead = nil
+		d.State.FilesModified = nil
+	}
 }
 
 // emitJSON marshals v as a single JSON line to JSONWriter.
@@ -114,6 +404,27 @@ func (d *Display) emitJSON(v interface{}) {
 	fmt.Fprintln(d.JSONWriter, string(data))
 }
 
+// emitEventEnvelope writes event to EventWriter wrapped in a consistent
+// envelope: {"seq":N,"time":"...","type":"...","event":{...}}.
+// Field names are prefixed with EventFieldPrefix. No-op when EventWriter is nil.
+func (d *Display) emitEventEnvelope(event events.Event) {
+	if d.EventWriter == nil {
+		return
+	}
+	d.eventSeq++
+	p := d.EventFieldPrefix
+	data, err := json.Marshal(map[string]interface{}{
+		p + "seq":   d.eventSeq,
+		p + "time":  time.Now().UTC().Format(time.RFC3339Nano),
+		p + "type":  event.EventType(),
+		p + "event": event,
+	})
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(d.EventWriter, string(data))
+}
+
 // emitJSONForEvent emits structured JSON for key event types.
 // Called at the top of HandleEvent so PendingTools is intact for tool_result lookups.
 func (d *Display) emitJSONForEvent(event events.Event) {
@@ -141,10 +452,15 @@ func (d *Display) emitJSONForEvent(event events.Event) {
 	case events.UserEvent:
 		for _, block := range e.Message.Content {
 			if block.Type == "tool_result" {
+				if d.isDuplicateToolResult(block.ToolUseID, block.ContentString, block.IsError) {
+					continue
+				}
 				pending := d.State.PendingTools[block.ToolUseID]
 				toolName := ""
 				if pending != nil {
 					toolName = pending.Name
+				} else if completed := d.State.CompletedTools[block.ToolUseID]; completed != nil {
+					toolName = completed.Name
 				}
 				summary := d.formatToolResult(toolName, e.ToolUseResult, block.ContentString)
 				d.emitJSON(map[string]interface{}{
@@ -155,13 +471,18 @@ func (d *Display) emitJSONForEvent(event events.Event) {
 			}
 		}
 	case events.ResultEvent:
-		d.emitJSON(map[string]interface{}{
+		result := map[string]interface{}{
 			"type":        "result",
 			"cost":        e.TotalCostUSD,
 			"duration_ms": e.DurationMS,
 			"turns":       e.NumTurns,
 			"is_error":    e.IsError,
-		})
+		}
+		if d.GitCommit != "" {
+			result["git_branch"] = d.GitBranch
+			result["git_commit"] = d.GitCommit
+		}
+		d.emitJSON(result)
 	}
 }
 
@@ -181,7 +502,16 @@ func (d *Display) handleNormalEvent(event events.Event) {
 	case events.ResultEvent:
 		d.showResultSummary(e, false)
 	case events.SystemEvent:
-		// System events are not shown in normal mode
+		// Only compaction markers and, on request, the compact session
+		// summary are shown in normal mode
+		switch e.Kind() {
+		case "init":
+			if d.ShowMetadata {
+				d.showCompactMetadata(e)
+			}
+		case "compact_boundary":
+			d.showCompactBoundary(e)
+		}
 	}
 }
 
@@ -192,12 +522,14 @@ func (d *Display) handleQuietEvent(event events.Event) {
 	case events.StreamEvent:
 		d.handleQuietStreamEvent(e)
 	case events.ResultEvent:
+		d.Spinner.Stop()
 		d.showQuietCompletion(e)
 	case events.AssistantMessageEvent:
 		// In quiet mode, only show errors from assistant messages
 		for _, block := range e.Message.Content {
 			if block.Type == "tool_result" && block.IsError {
-				d.Formatter.Error("%s%s", TreeBranch, block.Content)
+				d.Spinner.Stop()
+				d.Formatter.Error("%s%s", TreeBranch, toolErrorText(block))
 			}
 		}
 	case events.AssistantEvent:
@@ -206,7 +538,8 @@ func (d *Display) handleQuietEvent(event events.Event) {
 		// Show errors in quiet mode
 		for _, block := range e.Message.Content {
 			if block.Type == "tool_result" && block.IsError {
-				d.Formatter.Error("%s%s", TreeBranch, block.Content)
+				d.Spinner.Stop()
+				d.Formatter.Error("%s%s", TreeBranch, toolErrorText(block))
 			}
 		}
 	case events.SystemEvent:
@@ -221,43 +554,36 @@ func (d *Display) handleQuietStreamEvent(e events.StreamEvent) {
 	case "content_block_start":
 		// Only show errors in quiet mode
 		if e.Event.ContentBlock != nil && e.Event.ContentBlock.Type == "tool_result" && e.Event.ContentBlock.IsError {
-			d.Formatter.Error("%s%s", TreeBranch, e.Event.ContentBlock.Content)
+			d.Spinner.Stop()
+			d.Formatter.Error("%s%s", TreeBranch, toolErrorText(*e.Event.ContentBlock))
 		}
 	case "content_block_delta":
 		// Stream final text output (important to preserve Claude's response)
 		if e.Event.Delta != nil && e.Event.Delta.Text != "" {
-			d.Formatter.PlainNoNewline("%s", e.Event.Delta.Text)
+			d.Spinner.Stop()
+			d.writeStreamText(e.Event.Delta.Text)
 		}
 	case "message_stop":
+		if d.RenderTables {
+			d.finishTableText()
+		}
 		// Add newline after streaming text if there was any
-		fmt.Fprintln(d.Writer)
+		fmt.Fprintln(d.Writer())
+		d.State.TextColumn = 0
+		d.Formatter.Flush()
+		// Resume the spinner while waiting for the next message
+		d.Spinner.Start()
 	}
 }
 
 // showQuietCompletion displays minimal completion message in quiet mode.
 // Shows session summary with cost and duration even in quiet mode.
 func (d *Display) showQuietCompletion(e events.ResultEvent) {
-	// Show error if the result indicates an error
-	if e.IsError {
-		d.Formatter.Error("Session ended with error")
-		if e.Result != "" {
-			d.Formatter.Error("%s", e.Result)
-		}
+	// Display status line; stop here if the result was an error
+	if !d.showResultStatus(e) {
 		return
 	}
 
-	// Format duration values
-	totalDuration := formatDuration(e.DurationMS)
-	apiDuration := formatDuration(e.DurationAPIMS)
-	cost := formatCost(e.TotalCostUSD)
-
-	// Calculate total tokens from model usage
-	totalIn, totalOut := calculateTotalTokens(e)
-
-	// Display summary line with token counts
-	d.Formatter.Success("Session complete: %d turns, %s total (%s API), %d in / %d out, %s",
-		e.NumTurns, totalDuration, apiDuration, totalIn, totalOut, cost)
-
 	// Show condensed per-model usage
 	d.showModelUsageSummary(e)
 }
@@ -265,6 +591,9 @@ func (d *Display) showQuietCompletion(e events.ResultEvent) {
 // handleVerboseEvent handles events in verbose mode with detailed output.
 // Shows full tool parameters, results, token usage, and session metadata.
 func (d *Display) handleVerboseEvent(event events.Event) {
+	if d.handleVerboseSubagentEvent(event) {
+		return
+	}
 	switch e := event.(type) {
 	case events.StreamEvent:
 		d.handleVerboseStreamEvent(e)
@@ -286,6 +615,8 @@ func (d *Display) handleVerboseEvent(event events.Event) {
 func (d *Display) handleVerboseStreamEvent(e events.StreamEvent) {
 	switch e.Event.Type {
 	case "message_start":
+		d.flushTurnProgress()
+		d.trackTurnUsage(e)
 		d.showVerboseMessageStart(e) // verbose-only: model info
 	case "message_stop":
 		d.showMessageStop() // shared
@@ -296,6 +627,7 @@ func (d *Display) handleVerboseStreamEvent(e events.StreamEvent) {
 	case "content_block_stop":
 		d.handleContentBlockStop(e) // shared (closes text block)
 	case "message_delta":
+		d.trackTurnUsage(e)
 		d.handleMessageDelta(e) // verbose-only: token usage
 	}
 }
@@ -315,7 +647,7 @@ func (d *Display) handleVerboseAssistantMessage(e events.AssistantMessageEvent)
 			d.showVerboseToolUse(block.Name, block.ID, block.Input)
 		case "tool_result":
 			if block.IsError {
-				d.Formatter.Error("%sError: %s", TreeBranch, block.Content)
+				d.Formatter.Error("%sError: %s", TreeBranch, toolErrorText(block))
 			}
 		}
 	}
@@ -335,54 +667,123 @@ func (d *Display) handleVerboseAssistantEvent(e events.AssistantEvent) {
 
 // handleVerboseSystemEvent displays system event metadata.
 func (d *Display) handleVerboseSystemEvent(e events.SystemEvent) {
-	switch e.Type {
-	case "system.init":
+	switch e.Kind() {
+	case "init":
 		d.showSessionMetadata(e)
 	case "hook_started":
 		d.Formatter.Info("%s Hook started: %s (%s)", Bullet, e.HookName, e.HookType)
 	case "hook_response":
 		d.Formatter.Info("%s Hook response: %s", Bullet, e.Response)
+	case "compact_boundary":
+		d.showCompactBoundary(e)
+	default:
+		d.showGenericSystemEvent(e)
 	}
 }
 
+// systemEventSkipFields are raw fields not worth repeating for generic
+// system events (the type is in the heading; IDs are noise).
+var systemEventSkipFields = map[string]bool{"type": true, "subtype": true, "session_id": true, "uuid": true}
+
+// showGenericSystemEvent renders a system event without a dedicated
+// renderer: its type/subtype, then each remaining field (sorted), so events
+// added by newer CLIs are visible rather than dropped.
+func (d *Display) showGenericSystemEvent(e events.SystemEvent) {
+	name := e.Type
+	if e.Subtype != "" {
+		name += "/" + e.Subtype
+	}
+	d.Formatter.Info("%s System event: %s", Bullet, name)
+
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		if !systemEventSkipFields[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := e.Fields[key]
+		text, ok := value.(string)
+		if !ok {
+			data, _ := json.Marshal(value)
+			text = string(data)
+		}
+		d.Formatter.Plain("%s%s: %s", d.indent(1), key, truncateLine(text, d.lineLimit(100, d.indentWidth()+2+len(key))))
+	}
+}
+
+// showCompactBoundary marks where the CLI compacted (summarized) the
+// conversation context, with the trigger and prior size when known.
+func (d *Display) showCompactBoundary(e events.SystemEvent) {
+	marker := Rule + " context compacted " + Rule
+	if m := e.CompactMetadata; m != nil {
+		var details []string
+		if m.Trigger != "" {
+			details = append(details, m.Trigger)
+		}
+		if m.PreTokens > 0 {
+			details = append(details, fmt.Sprintf("%d tokens before", m.PreTokens))
+		}
+		if len(details) > 0 {
+			marker = fmt.Sprintf("%s context compacted (%s) %s", Rule, strings.Join(details, ", "), Rule)
+		}
+	}
+	d.Formatter.Plain("")
+	d.Formatter.Info("%s", marker)
+}
+
 // showSessionMetadata displays session initialization metadata.
 func (d *Display) showSessionMetadata(e events.SystemEvent) {
 	d.Formatter.Info("=== Session Metadata ===")
 	if e.SessionID != "" {
-		d.Formatter.Plain("  Session ID: %s", e.SessionID)
+		d.Formatter.Plain("%sSession ID: %s", d.indent(1), e.SessionID)
 	}
 	if e.Model != "" {
-		d.Formatter.Plain("  Model: %s", e.Model)
+		d.Formatter.Plain("%sModel: %s", d.indent(1), e.Model)
 	}
 	if e.Cwd != "" {
-		d.Formatter.Plain("  Working Directory: %s", e.Cwd)
+		d.Formatter.Plain("%sWorking Directory: %s", d.indent(1), e.Cwd)
 	}
 	if len(e.Tools) > 0 {
-		d.Formatter.Plain("  Available Tools: %d", len(e.Tools))
+		d.Formatter.Plain("%sAvailable Tools: %d", d.indent(1), len(e.Tools))
 		for _, tool := range e.Tools {
-			d.Formatter.Plain("    - %s", tool.Name)
+			d.Formatter.Plain("%s- %s", d.indent(2), tool.Name)
 		}
 	}
 	if len(e.McpServers) > 0 {
-		d.Formatter.Plain("  MCP Servers: %d", len(e.McpServers))
+		d.Formatter.Plain("%sMCP Servers: %d", d.indent(1), len(e.McpServers))
 		for _, server := range e.McpServers {
-			d.Formatter.Plain("    - %s (%s)", server.Name, server.Status)
+			d.Formatter.Plain("%s- %s (%s)", d.indent(2), server.Name, server.Status)
 		}
 	}
 	d.Formatter.Plain("========================")
 }
 
+// showCompactMetadata displays a one-line summary of session initialization
+// (model, tool count, MCP server count) for normal mode.
+func (d *Display) showCompactMetadata(e events.SystemEvent) {
+	var parts []string
+	if e.Model != "" {
+		parts = append(parts, "Model: "+e.Model)
+	}
+	parts = append(parts, fmt.Sprintf("Tools: %d", len(e.Tools)))
+	parts = append(parts, fmt.Sprintf("MCP servers: %d", len(e.McpServers)))
+	d.Formatter.Info("%s", strings.Join(parts, " | "))
+}
+
 // showVerboseToolUse displays a tool use event with full parameters.
 // Uses the shared compact header (green bullet, state tracking) then appends parameter detail.
 func (d *Display) showVerboseToolUse(toolName string, toolID string, input map[string]interface{}) {
 	// Shared compact header: ● ToolName(params) with green bullet + state tracking
 	d.showToolUse(toolName, toolID, input)
 
-	// Verbose addition: full parameter listing
-	if len(input) > 0 {
-		d.Formatter.Plain("  Parameters:")
+	// Verbose addition: full parameter listing (a TodoWrite checklist is
+	// already shown in full)
+	if _, isTodos := todoWriteList(toolName, input); len(input) > 0 && !isTodos {
+		d.Formatter.Plain("%sParameters:", d.indent(1))
 		for key, value := range input {
-			d.formatParameterValue(key, value, "    ")
+			d.formatParameterValue(key, value, d.indent(2))
 		}
 	}
 }
@@ -391,6 +792,7 @@ func (d *Display) showVerboseToolUse(toolName string, toolID string, input map[s
 func (d *Display) formatParameterValue(key string, value interface{}, indent string) {
 	switch v := value.(type) {
 	case string:
+		v = d.capParamValue(v)
 		// Truncate very long strings (e.g., file contents)
 		if len(v) > 200 {
 			lines := strings.Split(v, "\n")
@@ -401,7 +803,7 @@ func (d *Display) formatParameterValue(key string, value interface{}, indent str
 					if len(line) > 80 {
 						line = line[:77] + "..."
 					}
-					d.Formatter.Plain("%s  %s", indent, line)
+					d.Formatter.Plain("%s%s%s", indent, d.indent(1), line)
 				}
 			} else {
 				d.Formatter.Plain("%s%s: %s...", indent, key, v[:197])
@@ -420,23 +822,141 @@ func (d *Display) formatParameterValue(key string, value interface{}, indent str
 	}
 }
 
-// truncateLine truncates a line to the specified max length.
+// DefaultIndentWidth is the number of spaces per nesting level in detail
+// lines (parameters, verbose tool output, metadata, statistics) when
+// Display.IndentWidth is unset.
+const DefaultIndentWidth = 2
+
+// indentWidth returns the effective spaces per nesting level.
+func (d *Display) indentWidth() int {
+	if d.IndentWidth > 0 {
+		return d.IndentWidth
+	}
+	return DefaultIndentWidth
+}
+
+// indent returns the leading spaces for nesting level n.
+func (d *Display) indent(n int) string {
+	return strings.Repeat(" ", n*d.indentWidth())
+}
+
+// DefaultMaxToolParamBytes is the default cap on a single stored tool
+// parameter value.
+const DefaultMaxToolParamBytes = 64 * 1024
+
+// maxToolParamBytes returns the effective per-value cap for tool parameters.
+func (d *Display) maxToolParamBytes() int {
+	if d.MaxToolParamBytes > 0 {
+		return d.MaxToolParamBytes
+	}
+	return DefaultMaxToolParamBytes
+}
+
+// capParamValue truncates v to the tool parameter cap, cutting on a UTF-8
+// boundary and noting how many bytes were dropped.
+func (d *Display) capParamValue(v string) string {
+	limit := d.maxToolParamBytes()
+	if len(v) <= limit {
+		return v
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(v[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... (%d bytes truncated)", v[:cut], len(v)-cut)
+}
+
+// capToolParams returns input with oversized top-level string values
+// truncated, so huge parameters (e.g. Write content) are not retained in
+// PendingTools or rendered in full. input is returned as-is when nothing
+// exceeds the cap.
+func (d *Display) capToolParams(input map[string]interface{}) map[string]interface{} {
+	limit := d.maxToolParamBytes()
+	var capped map[string]interface{}
+	for key, value := range input {
+		s, ok := value.(string)
+		if !ok || len(s) <= limit {
+			continue
+		}
+		if capped == nil {
+			capped = make(map[string]interface{}, len(input))
+			for k, v := range input {
+				capped[k] = v
+			}
+		}
+		capped[key] = d.capParamValue(s)
+	}
+	if capped == nil {
+		return input
+	}
+	return capped
+}
+
+// SetWidth sets the terminal width used for width-aware truncation.
+// Safe to call from a signal-handling goroutine while events are displayed.
+func (d *Display) SetWidth(width int) {
+	d.width.Store(int64(width))
+}
+
+// lineLimit returns the maximum content length for a line with the given
+// indent: the terminal width minus the indent when known, else fallback.
+func (d *Display) lineLimit(fallback, indent int) int {
+	width := int(d.width.Load())
+	if width <= 0 {
+		return fallback
+	}
+	if limit := width - indent; limit > 20 {
+		return limit
+	}
+	return 20
+}
+
+// truncateLine shortens line to at most maxLen terminal columns (see
+// runeColumns), cutting on a rune boundary and ending it with "...".
 func truncateLine(line string, maxLen int) string {
-	if len(line) > maxLen {
-		return line[:maxLen-3] + "..."
+	// A rune never takes more columns than bytes
+	if len(line) <= maxLen {
+		return line
+	}
+	width, cut := 0, -1
+	for i, r := range line {
+		w := runeColumns(r)
+		if cut < 0 && width+w > maxLen-3 {
+			cut = i
+		}
+		width += w
+		if width > maxLen {
+			return line[:cut] + "..."
+		}
 	}
 	return line
 }
 
+// runeColumns approximates the number of terminal columns r takes: none for
+// combining marks, joiners and variation selectors, two for East Asian wide
+// characters and emoji, one for anything else.
+func runeColumns(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf), r >= 0xfe00 && r <= 0xfe0f:
+		return 0
+	case r >= 0x1100 && r <= 0x115f, r >= 0x2e80 && r <= 0xa4cf && r != 0x303f,
+		r >= 0xac00 && r <= 0xd7a3, r >= 0xf900 && r <= 0xfaff, r >= 0xfe30 && r <= 0xfe4f,
+		r >= 0xff00 && r <= 0xff60, r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1f64f, r >= 0x1f900 && r <= 0x1f9ff, r >= 0x20000 && r <= 0x3fffd:
+		return 2
+	}
+	return 1
+}
+
 // showTokenUsage displays token usage from message_delta events.
 func (d *Display) showTokenUsage(usage *events.Usage) {
 	if usage.InputTokens > 0 || usage.OutputTokens > 0 {
-		d.Formatter.Info("  Tokens - Input: %d, Output: %d", usage.InputTokens, usage.OutputTokens)
+		d.Formatter.Info("%sTokens - Input: %d, Output: %d", d.indent(1), usage.InputTokens, usage.OutputTokens)
 		if usage.CacheReadInputTokens > 0 {
-			d.Formatter.Plain("    Cache read: %d tokens", usage.CacheReadInputTokens)
+			d.Formatter.Plain("%sCache read: %d tokens", d.indent(2), usage.CacheReadInputTokens)
 		}
 		if usage.CacheCreationInputTokens > 0 {
-			d.Formatter.Plain("    Cache creation: %d tokens", usage.CacheCreationInputTokens)
+			d.Formatter.Plain("%sCache creation: %d tokens", d.indent(2), usage.CacheCreationInputTokens)
 		}
 	}
 }
@@ -445,7 +965,7 @@ func (d *Display) showTokenUsage(usage *events.Usage) {
 // No blank line here — the shared text block handler emits a separator before text.
 func (d *Display) showVerboseMessageStart(e events.StreamEvent) {
 	if e.Event.Message != nil && e.Event.Message.Model != "" {
-		d.Formatter.Info("  Model: %s", e.Event.Message.Model)
+		d.Formatter.Info("%sModel: %s", d.indent(1), e.Event.Message.Model)
 	}
 }
 
@@ -480,17 +1000,27 @@ func (d *Display) handleContentBlockStart(e events.StreamEvent) {
 		d.State.PendingTools[block.ID] = &PendingToolCall{
 			ID:    block.ID,
 			Name:  block.Name,
-			Input: block.Input,
+			Input: d.capToolParams(block.Input),
 		}
 	case "text":
 		// Add newline before text if we have pending tool results displayed
-		fmt.Fprintln(d.Writer)
-		// Start text with bullet
+		fmt.Fprintln(d.Writer())
+		// Text now follows whatever came before, so the tool call and result
+		// spacing state no longer applies
+		d.State.LastMessageWasToolUse = false
+		d.State.ToolResultJustDisplayed = false
+		d.State.LastOutputWasText = false
+		// Start text with bullet (and speaker label if enabled)
 		d.State.InTextBlock = true
-		d.Formatter.PlainNoNewline("%s ", Bullet)
+		prefix := Bullet + " "
+		if d.ShowLabels {
+			prefix += d.Formatter.Label(d.assistantLabel()) + " "
+		}
+		d.Formatter.PlainNoNewline("%s", prefix)
+		d.State.TextColumn = visibleWidth(prefix)
 	case "tool_result":
 		if block.IsError {
-			d.Formatter.Error("%sError: %s", TreeBranch, block.Content)
+			d.Formatter.Error("%sError: %s", TreeBranch, toolErrorText(*block))
 		}
 	}
 }
@@ -503,16 +1033,26 @@ func (d *Display) handleContentBlockDelta(e events.StreamEvent) {
 
 	// Stream text output in real-time
 	if e.Event.Delta.Text != "" {
-		d.Formatter.PlainNoNewline("%s", e.Event.Delta.Text)
+		d.writeStreamText(e.Event.Delta.Text)
 	}
 }
 
 // handleContentBlockStop processes the end of a content block.
 func (d *Display) handleContentBlockStop(_ events.StreamEvent) {
+	if d.RenderTables {
+		d.finishTableText()
+	}
 	if d.State.InTextBlock {
 		d.State.InTextBlock = false
-		fmt.Fprintln(d.Writer) // Newline after text block
+		d.State.LastOutputWasText = true
+		fmt.Fprintln(d.Writer()) // Newline after text block
 	}
+	d.flush()
+}
+
+// flush forces buffered display output through (see Formatter.Flush).
+func (d *Display) flush() {
+	d.Formatter.Flush()
 }
 
 // handleAssistantMessage processes complete assistant messages.
@@ -525,7 +1065,7 @@ func (d *Display) handleAssistantMessage(e events.AssistantMessageEvent) {
 			d.showToolUse(block.Name, block.ID, block.Input)
 		case "tool_result":
 			if block.IsError {
-				d.Formatter.Error("%sError: %s", TreeBranch, block.Content)
+				d.Formatter.Error("%sError: %s", TreeBranch, toolErrorText(block))
 			}
 		}
 	}
@@ -549,11 +1089,14 @@ func (d *Display) handleAssistantEvent(e events.AssistantEvent) {
 func (d *Display) handleUserEvent(e events.UserEvent) {
 	for _, block := range e.Message.Content {
 		if block.Type == "tool_result" {
+			if d.isDuplicateToolResult(block.ToolUseID, block.ContentString, block.IsError) {
+				continue
+			}
 			// Check if this was a denied tool (error with permission message)
 			if block.IsError && d.isToolDenied(block.ContentString) {
 				d.showToolDenied(block.ToolUseID, block.ContentString)
 			} else {
-				d.showToolResult(block.ToolUseID, e.ToolUseResult, block.ContentString)
+				d.showToolResult(block.ToolUseID, e.ToolUseResult, block.ContentString, block.IsError)
 			}
 		}
 	}
@@ -564,41 +1107,143 @@ func (d *Display) handleUserEvent(e events.UserEvent) {
 func (d *Display) handleVerboseUserEvent(e events.UserEvent) {
 	for _, block := range e.Message.Content {
 		if block.Type == "tool_result" {
+			if d.isDuplicateToolResult(block.ToolUseID, block.ContentString, block.IsError) {
+				continue
+			}
 			if block.IsError && d.isToolDenied(block.ContentString) {
 				d.showToolDenied(block.ToolUseID, block.ContentString)
 			} else {
+				// Look up the tool name before showToolResult clears the pending entry
+				toolName := ""
+				if pending := d.State.PendingTools[block.ToolUseID]; pending != nil {
+					toolName = pending.Name
+				} else if completed := d.State.CompletedTools[block.ToolUseID]; completed != nil {
+					toolName = completed.Name
+				}
 				// Compact summary line (shared): ⎿  Read N lines
-				d.showToolResult(block.ToolUseID, e.ToolUseResult, block.ContentString)
-				// Verbose addition: truncated raw content
-				d.showVerboseToolContent(block.ContentString, block.IsError)
+				d.showToolResult(block.ToolUseID, e.ToolUseResult, block.ContentString, block.IsError)
+				// Verbose addition: matches for searches, truncated raw content otherwise
+				_, bashFailed := bashFailure(toolName, e.ToolUseResult, block.ContentString)
+				if d.HideToolOutput && !block.IsError && !bashFailed {
+					continue
+				}
+				// A Task sub-agent's own tool calls, nested beneath its line
+				if hasSubagentCalls(block.ContentBlocks) {
+					d.showSubagentBlocks(block.ContentBlocks, 1)
+				}
+				switch strings.ToLower(toolName) {
+				case "grep", "glob":
+					d.showVerboseMatches(block.ContentString, block.IsError)
+				case "bash":
+					if d.RawBashOutput {
+						d.showRawToolContent(block.ContentString)
+					} else {
+						d.showVerboseToolContent(block.ContentString, block.IsError || bashFailed)
+					}
+				default:
+					d.showVerboseToolContent(block.ContentString, block.IsError || bashFailed)
+				}
 			}
 		}
 	}
 }
 
+// DefaultMatchLimit is the number of Grep/Glob matches shown in verbose mode
+// when Display.MatchLimit is unset.
+const DefaultMatchLimit = 20
+
+// grepMatchLine matches Grep content-mode output lines: "path:line:text".
+var grepMatchLine = regexp.MustCompile(`^(.+?):(\d+):(.*)$`)
+
+// showVerboseMatches displays the files or matching lines found by a Grep or
+// Glob call, up to MatchLimit entries. Lines with a file:line prefix are
+// rendered with the location separated from the matched text. Falls back to
+// the raw content display for errors or unrecognized output.
+func (d *Display) showVerboseMatches(content string, isError bool) {
+	content = sanitizeToolText(content)
+	if isError {
+		d.showVerboseToolContent(content, isError)
+		return
+	}
+
+	var matches []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		// Skip blank lines and Grep's "Found N files" header
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "Found ") {
+			continue
+		}
+		matches = append(matches, line)
+	}
+	if len(matches) == 0 {
+		d.showVerboseToolContent(content, isError)
+		return
+	}
+
+	limit := d.MatchLimit
+	if limit <= 0 {
+		limit = DefaultMatchLimit
+	}
+	for i, match := range matches {
+		if i == limit {
+			d.Formatter.Plain("%s... and %d more", d.indent(1), len(matches)-limit)
+			break
+		}
+		if m := grepMatchLine.FindStringSubmatch(match); m != nil {
+			text := strings.TrimSpace(m[3])
+			d.Formatter.Plain("%s%s:%s  %s", d.indent(1), m[1], m[2], truncateLine(text, d.lineLimit(100, d.indentWidth()+2+len(m[1])+len(m[2]))))
+		} else {
+			d.Formatter.Plain("%s%s", d.indent(1), truncateLine(match, d.lineLimit(120, d.indentWidth())))
+		}
+	}
+}
+
 // showVerboseToolContent displays truncated tool output content below the compact result line.
 func (d *Display) showVerboseToolContent(content string, isError bool) {
 	if content == "" {
 		return
 	}
+	content = sanitizeToolText(content)
 	lines := strings.Split(content, "\n")
 	total := len(lines)
 	const maxLines = 15
 	if total > maxLines {
-		d.Formatter.Plain("  (Showing %d of %d lines)", maxLines, total)
+		d.Formatter.Plain("%s(Showing %d of %d lines)", d.indent(1), maxLines, total)
 		for i := 0; i < 10 && i < total; i++ {
-			d.Formatter.Plain("  %s", truncateLine(lines[i], 120))
+			d.Formatter.Plain("%s%s", d.indent(1), truncateLine(lines[i], d.lineLimit(120, d.indentWidth())))
 		}
-		d.Formatter.Plain("  ...")
+		d.Formatter.Plain("%s...", d.indent(1))
 		for i := total - 5; i < total; i++ {
 			if i >= 0 {
-				d.Formatter.Plain("  %s", truncateLine(lines[i], 120))
+				d.Formatter.Plain("%s%s", d.indent(1), truncateLine(lines[i], d.lineLimit(120, d.indentWidth())))
 			}
 		}
 	} else {
 		for _, line := range lines {
-			d.Formatter.Plain("  %s", truncateLine(line, 120))
+			d.Formatter.Plain("%s%s", d.indent(1), truncateLine(line, d.lineLimit(120, d.indentWidth())))
+		}
+	}
+}
+
+// showRawToolContent displays tool output in full below the compact result
+// line, keeping its ANSI colors (see Display.RawBashOutput). Each colored
+// line ends with a reset so an unclosed color can't bleed into later output.
+func (d *Display) showRawToolContent(content string) {
+	if !d.UnsafeRawBashOutput {
+		content = strings.ToValidUTF8(SanitizeANSI(content), Unprintable)
+	}
+	if !d.Formatter.ColorEnabled {
+		content = StripANSI(content)
+	}
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, "\x1b") {
+			line += colorReset
 		}
+		d.Formatter.Plain("%s%s", d.indent(1), line)
 	}
 }
 
@@ -611,12 +1256,18 @@ func (d *Display) isToolDenied(content string) bool {
 func (d *Display) showToolDenied(toolID string, content string) {
 	pending := d.State.PendingTools[toolID]
 	if pending == nil {
+		d.showUpdatedToolResult(toolID, nil, content, true)
 		return
 	}
 	delete(d.State.PendingTools, toolID)
+	d.State.CompletedTools[toolID] = &CompletedToolCall{Name: pending.Name, Digest: sha256.Sum256([]byte(content)), IsError: true}
+	if d.State.DeniedTools == nil {
+		d.State.DeniedTools = make(map[string]int)
+	}
+	d.State.DeniedTools[pending.Name]++
 
 	// Format: ⎿ Tool denied (not in allowed-tools)
-	d.Formatter.Warning("%sTool denied (not in allowed-tools)", TreeBranch)
+	d.Formatter.Warning("%sTool denied (not in allowed-tools)%s", TreeBranch, d.relativeTime())
 	d.State.LastMessageWasToolUse = false
 	d.State.ToolResultJustDisplayed = true
 }
@@ -624,17 +1275,22 @@ func (d *Display) showToolDenied(toolID string, content string) {
 // showToolUse displays a tool use event with Claude Code style.
 // Format: ● ToolName(param) where only ● is green
 func (d *Display) showToolUse(toolName string, toolID string, input map[string]interface{}) {
+	input = d.capToolParams(input)
+
 	// Track pending tool for result matching
 	d.State.PendingTools[toolID] = &PendingToolCall{
-		ID:    toolID,
-		Name:  toolName,
-		Input: input,
+		ID:        toolID,
+		Name:      toolName,
+		Input:     input,
+		StartedAt: time.Now(),
 	}
 
-	// Separate consecutive tool call headers (or a header after a result line) with a blank line.
-	if d.State.LastMessageWasToolUse || d.State.ToolResultJustDisplayed {
-		fmt.Fprintln(d.Writer)
+	// Separate a tool call header from a previous header, result line or
+	// text block with a blank line.
+	if d.State.LastMessageWasToolUse || d.State.ToolResultJustDisplayed || d.State.LastOutputWasText {
+		fmt.Fprintln(d.Writer())
 		d.State.ToolResultJustDisplayed = false
+		d.State.LastOutputWasText = false
 	}
 
 	// Format: ● ToolName(param) - only bullet is colored green
@@ -645,8 +1301,64 @@ func (d *Display) showToolUse(toolName string, toolID string, input map[string]i
 	} else {
 		text = toolName
 	}
+	if d.ShowLabels {
+		text = d.Formatter.Label(d.toolLabel(toolName)) + " " + text
+	}
 	d.Formatter.ToolCall(Bullet, text)
+	if todos, ok := todoWriteList(toolName, input); ok {
+		d.showTodoList(todos)
+	}
+	d.warnIfDangerous(toolName, input)
 	d.State.LastMessageWasToolUse = true
+
+	d.trackFileOperation(toolName, input)
+}
+
+// trackFileOperation records the file path touched by a Read call, or by
+// a call that writes or edits a file (see editedFile), so it can be listed
+// in the file summary.
+func (d *Display) trackFileOperation(toolName string, input map[string]interface{}) {
+	if path := editedFile(events.ContentBlock{Name: toolName, Input: input}); path != "" {
+		d.State.FilesModified = appendUnique(d.State.FilesModified, path)
+		return
+	}
+	if path, ok := input["file_path"].(string); ok && path != "" && strings.EqualFold(toolName, "read") {
+		d.State.FilesRead = appendUnique(d.State.FilesRead, path)
+	}
+}
+
+// appendUnique appends s to list unless it is already present.
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// Default speaker labels for --labels.
+const (
+	DefaultAssistantLabel = "Assistant:"
+	DefaultToolLabel      = "Tool ({tool}):"
+)
+
+// assistantLabel returns the label shown before assistant text.
+func (d *Display) assistantLabel() string {
+	if d.AssistantLabel != "" {
+		return d.AssistantLabel
+	}
+	return DefaultAssistantLabel
+}
+
+// toolLabel returns the label shown before a tool call, with {tool}
+// replaced by the tool name.
+func (d *Display) toolLabel(toolName string) string {
+	label := d.ToolLabel
+	if label == "" {
+		label = DefaultToolLabel
+	}
+	return strings.ReplaceAll(label, "{tool}", toolName)
 }
 
 // formatToolParams formats tool parameters for compact display
@@ -679,27 +1391,133 @@ func (d *Display) formatToolParams(toolName string, input map[string]interface{}
 		if desc, ok := input["description"].(string); ok {
 			return desc
 		}
+	case "todowrite":
+		if todos, ok := parseTodos(input); ok {
+			return todoSummary(todos)
+		}
 	}
 	return ""
 }
 
-// showToolResult displays a tool result with tree branch
-func (d *Display) showToolResult(toolID string, result *events.ToolUseResult, content string) {
+// showToolResult displays a tool result with tree branch.
+// With HideToolOutput set, successful results are suppressed (errors are
+// still shown), but the pending entry is always cleared.
+func (d *Display) showToolResult(toolID string, result *events.ToolUseResult, content string, isError bool) {
 	pending := d.State.PendingTools[toolID]
 	if pending == nil {
+		d.showUpdatedToolResult(toolID, result, content, isError)
 		return
 	}
 	delete(d.State.PendingTools, toolID)
+	d.State.CompletedTools[toolID] = &CompletedToolCall{Name: pending.Name, Digest: sha256.Sum256([]byte(content)), IsError: isError}
+	d.recordToolTime(pending)
 
-	// Format result based on tool type
+	_, bashFailed := bashFailure(pending.Name, result, content)
+	if d.HideToolOutput && !isError && !bashFailed {
+		return
+	}
+
+	// Format result based on tool type; errors and failed shell commands
+	// stand out in red (denials are shown by showToolDenied instead). An
+	// error shows its message, since the tool summaries describe successes.
 	resultStr := d.formatToolResult(pending.Name, result, content)
-	d.Formatter.Plain("%s%s", TreeBranch, resultStr)
+	if isError && !bashFailed {
+		resultStr = toolErrorSummary(content)
+	}
+	resultStr = sanitizeToolText(resultStr) + d.relativeTime()
+	if isError || bashFailed {
+		d.Formatter.Error("%s%s", TreeBranch, resultStr)
+	} else {
+		d.Formatter.Plain("%s%s", TreeBranch, resultStr)
+	}
 
 	// Reset tool use state, mark that we just displayed a result
 	d.State.LastMessageWasToolUse = false
 	d.State.ToolResultJustDisplayed = true
 }
 
+// relativeTime returns " (+4.2s)", the time since the session started, for
+// appending to a tool result line, or "" unless RelativeTime is set.
+func (d *Display) relativeTime() string {
+	if !d.RelativeTime {
+		return ""
+	}
+	return fmt.Sprintf(" (+%s)", formatDuration(time.Since(d.State.SessionStart).Milliseconds()))
+}
+
+// toolErrorSummary returns the result line text for a failed tool call:
+// "Error: " and the first non-blank line of its output.
+func toolErrorSummary(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return "Error: " + truncateLine(line, 60)
+		}
+	}
+	return "Error"
+}
+
+// isDuplicateToolResult reports whether a tool_result repeats, exactly, one
+// already shown for toolID (seen occasionally when the stream retries).
+// Exact repeats are ignored.
+func (d *Display) isDuplicateToolResult(toolID, content string, isError bool) bool {
+	completed := d.State.CompletedTools[toolID]
+	return completed != nil && completed.Digest == sha256.Sum256([]byte(content)) && completed.IsError == isError
+}
+
+// showUpdatedToolResult shows a second, differing result for a tool call
+// whose result was already shown, as "⎿ Updated result: ...". Results for
+// unknown tool IDs are ignored.
+func (d *Display) showUpdatedToolResult(toolID string, result *events.ToolUseResult, content string, isError bool) {
+	completed := d.State.CompletedTools[toolID]
+	if completed == nil {
+		return
+	}
+	completed.Digest, completed.IsError = sha256.Sum256([]byte(content)), isError
+
+	if d.HideToolOutput && !isError {
+		return
+	}
+	resultStr := d.formatToolResult(completed.Name, result, content)
+	if isError && d.isToolDenied(content) {
+		resultStr = "Tool denied (not in allowed-tools)"
+	}
+	d.Formatter.Warning("%sUpdated result: %s", TreeBranch, sanitizeToolText(resultStr))
+	d.State.LastMessageWasToolUse = false
+	d.State.ToolResultJustDisplayed = true
+}
+
+// recordToolTime adds the time since pending's call was seen to its tool's
+// total. Calls whose result never arrives are never counted.
+func (d *Display) recordToolTime(pending *PendingToolCall) {
+	if pending.StartedAt.IsZero() {
+		return
+	}
+	if d.State.ToolTime == nil {
+		d.State.ToolTime = make(map[string]time.Duration)
+	}
+	d.State.ToolTime[pending.Name] += time.Since(pending.StartedAt)
+}
+
+// formatToolTime lists per-tool totals, slowest first:
+// "Bash 12.3s, Read 400ms".
+func formatToolTime(times map[string]time.Duration) string {
+	names := make([]string, 0, len(times))
+	for name := range times {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if times[names[i]] != times[names[j]] {
+			return times[names[i]] > times[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %s", name, formatDuration(times[name].Milliseconds()))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // formatToolResult formats tool result for display
 func (d *Display) formatToolResult(toolName string, result *events.ToolUseResult, content string) string {
 	switch strings.ToLower(toolName) {
@@ -733,6 +1551,10 @@ func (d *Display) formatToolResult(toolName string, result *events.ToolUseResult
 		}
 		return fmt.Sprintf("%d matches", count)
 	case "bash":
+		// A non-zero exit leads with "exit N", even if is_error wasn't set
+		if failure, failed := bashFailure(toolName, result, content); failed {
+			return failure
+		}
 		// Show first line of output or "Done"
 		if content == "" {
 			return "Done"
@@ -758,68 +1580,210 @@ func (d *Display) formatToolResult(toolName string, result *events.ToolUseResult
 // showMessageStart displays visual indicator at message start.
 func (d *Display) showMessageStart() {
 	// No separator - bullet structure provides hierarchy
+	d.flushTurnProgress()
 }
 
 // showMessageStop ensures newline after streaming text.
 // Suppresses extra newline after tool use to keep result immediately below.
 func (d *Display) showMessageStop() {
+	// Each assistant message is one turn, matching the CLI's num_turns count
+	d.State.TurnsCompleted++
+	if d.Verbosity == VerbosityVerbose {
+		d.completeTurnUsage()
+	}
+	d.State.TurnProgressPending = d.MaxTurns > 0 || d.Verbosity == VerbosityVerbose
+
 	// Skip newline if we just displayed a tool use (result should appear immediately below)
 	if d.State.LastMessageWasToolUse {
 		return
 	}
 	if !d.State.InTextBlock {
-		fmt.Fprintln(d.Writer)
+		fmt.Fprintln(d.Writer())
+	}
+	d.flushTurnProgress()
+}
+
+// flushTurnProgress shows a line for the last completed turn: "Turn N/M (P%)"
+// when MaxTurns is set, with the turn's tokens appended in verbose mode
+// ("Turn N [msg_id]: 1,200 in / 340 out" without MaxTurns). When a turn ends on a tool
+// call the line is deferred until the next message (or the result) so tool
+// results stay under their call.
+func (d *Display) flushTurnProgress() {
+	if !d.State.TurnProgressPending {
+		return
+	}
+	d.State.TurnProgressPending = false
+
+	line := fmt.Sprintf("Turn %d", d.State.TurnsCompleted)
+	if d.MaxTurns > 0 {
+		pct := d.State.TurnsCompleted * 100 / d.MaxTurns
+		line = fmt.Sprintf("Turn %d/%d (%d%%)", d.State.TurnsCompleted, d.MaxTurns, pct)
+	}
+	if d.Verbosity == VerbosityVerbose && len(d.State.Turns) > 0 {
+		turn := d.State.Turns[len(d.State.Turns)-1]
+		if turn.MessageID != "" {
+			line += " [" + turn.MessageID + "]"
+		}
+		line += ": " + formatTurnTokens(turn.Usage.InputTokens, turn.Usage.OutputTokens)
 	}
+	d.Formatter.Info("%s", line)
 }
 
 // showResultSummary displays the session result summary with cost and duration.
 // Format: 'Session complete: N turns, X.Xs total (Y.Ys API), XXXX in / YYY out, $Z.ZZ'
+// The label and color depend on the result subtype (see resultStatuses).
 // Shows per-model usage in both normal and verbose modes.
 func (d *Display) showResultSummary(e events.ResultEvent, verbose bool) {
-	// Check for errors first
-	if e.IsError {
-		d.Formatter.Error("Session ended with error")
-		if e.Result != "" {
-			d.Formatter.Error("%s", e.Result)
+	d.flushTurnProgress()
+	d.State.TurnsCompleted = 0
+
+	// Display status line; stop here if the result was an error, after
+	// noting any denials, which may be why it failed, and the files it
+	// touched
+	ok := d.showResultStatus(e)
+	d.showDeniedSummary()
+	if !ok {
+		if d.ShowFileSummary {
+			d.showFileSummary()
 		}
 		return
 	}
 
-	// Format duration values
-	totalDuration := formatDuration(e.DurationMS)
-	apiDuration := formatDuration(e.DurationAPIMS)
-
-	// Format cost as currency
-	cost := formatCost(e.TotalCostUSD)
-
-	// Calculate total tokens from model usage
-	totalIn, totalOut := calculateTotalTokens(e)
-
-	// Display summary line with token counts
-	d.Formatter.Success("Session complete: %d turns, %s total (%s API), %d in / %d out, %s",
-		e.NumTurns, totalDuration, apiDuration, totalIn, totalOut, cost)
-
 	// Always show per-model usage summary
 	d.showModelUsageSummary(e)
 
+	if d.ShowFileSummary {
+		d.showFileSummary()
+	}
+
 	// In verbose mode, show additional detailed statistics
 	if verbose {
 		d.showVerboseResultDetails(e)
 	}
 }
 
-// showModelUsageSummary displays per-model token counts and costs.
+// showDeniedSummary lists the tool calls denied during the session, most
+// denied tool first, so an allowlist that is too tight is easy to spot.
+// Nothing is shown if no calls were denied.
+// Format: '3 tool calls denied: WebFetch ×2, Bash ×1'
+func (d *Display) showDeniedSummary() {
+	denied := d.State.DeniedTools
+	if len(denied) == 0 {
+		return
+	}
+	d.State.DeniedTools = nil // next session starts fresh
+
+	names := make([]string, 0, len(denied))
+	total := 0
+	for name, n := range denied {
+		names = append(names, name)
+		total += n
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if denied[names[i]] != denied[names[j]] {
+			return denied[names[i]] > denied[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s ×%d", name, denied[name])
+	}
+	noun := "calls"
+	if total == 1 {
+		noun = "call"
+	}
+	d.Formatter.Warning("%d tool %s denied: %s", total, noun, strings.Join(parts, ", "))
+}
+
+// showModelUsageSummary displays per-model token counts and costs, one
+// model per line with columns aligned across models.
 // Format: '  - model-name: 12345 in / 678 out (85%) $0.42'
 func (d *Display) showModelUsageSummary(e events.ResultEvent) {
 	if len(e.ModelUsage) == 0 {
 		return
 	}
 
-	for model, usage := range e.ModelUsage {
+	var rows [][]string
+	for _, model := range sortedModels(e.ModelUsage) {
+		usage := e.ModelUsage[model]
 		pct := calculateModelPercentage(usage.CostUSD, e.TotalCostUSD)
-		cost := formatCost(usage.CostUSD)
-		d.Formatter.Plain("  - %s: %d in / %d out (%.0f%%) %s",
-			model, usage.InputTokens, usage.OutputTokens, pct, cost)
+		rows = append(rows, []string{
+			model + ":",
+			fmt.Sprint(usage.InputTokens), "in /",
+			fmt.Sprint(usage.OutputTokens), "out",
+			fmt.Sprintf("(%.0f%%)", pct),
+			formatCost(usage.CostUSD),
+		})
+	}
+	aligns := []int{alignLeft, alignRight, alignLeft, alignRight, alignLeft, alignRight, alignRight}
+	for _, line := range formatTable(rows, aligns) {
+		d.Formatter.Plain("%s- %s", d.indent(1), line)
+	}
+}
+
+// perModelUsageTable lays out verbose per-model token counts as a table with
+// a header row. Cache columns appear only when some model used the cache.
+func perModelUsageTable(usage map[string]*events.ModelUsage) []string {
+	models := sortedModels(usage)
+	showCacheRead, showCacheCreation := false, false
+	for _, u := range usage {
+		showCacheRead = showCacheRead || u.CacheReadInputTokens > 0
+		showCacheCreation = showCacheCreation || u.CacheCreationInputTokens > 0
+	}
+
+	header := []string{"Model", "Input", "Output"}
+	if showCacheRead {
+		header = append(header, "Cache read")
+	}
+	if showCacheCreation {
+		header = append(header, "Cache creation")
+	}
+	rows := [][]string{header}
+	for _, model := range models {
+		u := usage[model]
+		row := []string{model, fmt.Sprint(u.InputTokens), fmt.Sprint(u.OutputTokens)}
+		if showCacheRead {
+			row = append(row, fmt.Sprint(u.CacheReadInputTokens))
+		}
+		if showCacheCreation {
+			row = append(row, fmt.Sprint(u.CacheCreationInputTokens))
+		}
+		rows = append(rows, row)
+	}
+	return formatTable(rows, []int{alignLeft, alignRight, alignRight, alignRight, alignRight})
+}
+
+// sortedModels returns the model names in usage, sorted.
+func sortedModels(usage map[string]*events.ModelUsage) []string {
+	models := make([]string, 0, len(usage))
+	for model := range usage {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// showFileSummary lists the files read and modified during the session.
+// Nothing is shown if no file tools were called.
+func (d *Display) showFileSummary() {
+	if len(d.State.FilesModified) == 0 && len(d.State.FilesRead) == 0 {
+		return
+	}
+
+	d.Formatter.Plain("")
+	d.Formatter.Info("Files changed:")
+	if len(d.State.FilesModified) > 0 {
+		d.Formatter.Plain("%sWritten/Edited (%d):", d.indent(1), len(d.State.FilesModified))
+		for _, path := range d.State.FilesModified {
+			d.Formatter.Plain("%s%s", d.indent(2), path)
+		}
+	}
+	if len(d.State.FilesRead) > 0 {
+		d.Formatter.Plain("%sRead (%d):", d.indent(1), len(d.State.FilesRead))
+		for _, path := range d.State.FilesRead {
+			d.Formatter.Plain("%s%s", d.indent(2), path)
+		}
 	}
 }
 
@@ -831,11 +1795,23 @@ func calculateModelPercentage(modelCost, totalCost float64) float64 {
 	return (modelCost / totalCost) * 100
 }
 
-// calculateTotalTokens sums input and output tokens across all models.
+// calculateTotalTokens returns a session's input and output token totals,
+// the single figure shown everywhere a total appears (summary line, verbose
+// statistics, batch report). The result's aggregate usage is authoritative
+// when present, since per-model figures may not reconcile with it (e.g.
+// cache accounting); otherwise the per-model figures are summed.
 func calculateTotalTokens(e events.ResultEvent) (totalIn, totalOut int) {
-	for _, usage := range e.ModelUsage {
-		totalIn += usage.InputTokens
-		totalOut += usage.OutputTokens
+	if e.Usage != nil && (e.Usage.InputTokens > 0 || e.Usage.OutputTokens > 0) {
+		return e.Usage.InputTokens, e.Usage.OutputTokens
+	}
+	return sumModelTokens(e.ModelUsage)
+}
+
+// sumModelTokens sums input and output tokens across all models.
+func sumModelTokens(usage map[string]*events.ModelUsage) (totalIn, totalOut int) {
+	for _, u := range usage {
+		totalIn += u.InputTokens
+		totalOut += u.OutputTokens
 	}
 	return
 }
@@ -845,50 +1821,63 @@ func (d *Display) showVerboseResultDetails(e events.ResultEvent) {
 	d.Formatter.Plain("")
 	d.Formatter.Info("=== Session Statistics ===")
 
-	// Show aggregated usage if available
+	if d.GitCommit != "" {
+		d.Formatter.Plain("%sGit: %s @ %s", d.indent(1), d.GitBranch, d.GitCommit)
+	}
+
+	// Show the same totals as the summary line, plus aggregate cache details
+	totalIn, totalOut := calculateTotalTokens(e)
+	if e.Usage != nil || len(e.ModelUsage) > 0 {
+		d.Formatter.Plain("%sTotal Tokens:", d.indent(1))
+		d.Formatter.Plain("%sInput: %d", d.indent(2), totalIn)
+		d.Formatter.Plain("%sOutput: %d", d.indent(2), totalOut)
+	}
 	if e.Usage != nil {
-		d.Formatter.Plain("  Total Tokens:")
-		d.Formatter.Plain("    Input: %d", e.Usage.InputTokens)
-		d.Formatter.Plain("    Output: %d", e.Usage.OutputTokens)
 		if e.Usage.CacheReadInputTokens > 0 {
-			d.Formatter.Plain("    Cache read: %d", e.Usage.CacheReadInputTokens)
+			d.Formatter.Plain("%sCache read: %d", d.indent(2), e.Usage.CacheReadInputTokens)
 		}
 		if e.Usage.CacheCreationInputTokens > 0 {
-			d.Formatter.Plain("    Cache creation: %d", e.Usage.CacheCreationInputTokens)
+			d.Formatter.Plain("%sCache creation: %d", d.indent(2), e.Usage.CacheCreationInputTokens)
 		}
 		if e.Usage.TotalTokens > 0 {
-			d.Formatter.Plain("    Total: %d", e.Usage.TotalTokens)
+			d.Formatter.Plain("%sTotal: %d", d.indent(2), e.Usage.TotalTokens)
 		}
 	}
 
 	// Show per-model usage if available
 	if len(e.ModelUsage) > 0 {
 		d.Formatter.Plain("")
-		d.Formatter.Plain("  Per-Model Usage:")
-		for model, usage := range e.ModelUsage {
-			d.Formatter.Plain("    %s:", model)
-			d.Formatter.Plain("      Input: %d, Output: %d", usage.InputTokens, usage.OutputTokens)
-			if usage.CacheReadInputTokens > 0 {
-				d.Formatter.Plain("      Cache read: %d", usage.CacheReadInputTokens)
-			}
-			if usage.CacheCreationInputTokens > 0 {
-				d.Formatter.Plain("      Cache creation: %d", usage.CacheCreationInputTokens)
-			}
+		d.Formatter.Plain("%sPer-Model Usage:", d.indent(1))
+		for _, line := range perModelUsageTable(e.ModelUsage) {
+			d.Formatter.Plain("%s%s", d.indent(2), line)
+		}
+		// Say so when the breakdown doesn't add up to the totals shown above
+		if modelIn, modelOut := sumModelTokens(e.ModelUsage); modelIn != totalIn || modelOut != totalOut {
+			d.Formatter.Plain("%s(models sum to %d in / %d out; totals use the aggregate usage)", d.indent(2), modelIn, modelOut)
 		}
 	}
 
 	// Show tool usage if available
 	if e.TotalToolUse > 0 {
 		d.Formatter.Plain("")
-		d.Formatter.Plain("  Tool Usage: %d total", e.TotalToolUse)
+		d.Formatter.Plain("%sTool Usage: %d total", d.indent(1), e.TotalToolUse)
 		if e.TotalToolErrors > 0 {
-			d.Formatter.Warning("    Errors: %d", e.TotalToolErrors)
+			d.Formatter.Warning("%sErrors: %d", d.indent(2), e.TotalToolErrors)
 		}
 		if e.TotalToolCancels > 0 {
-			d.Formatter.Plain("    Cancels: %d", e.TotalToolCancels)
+			d.Formatter.Plain("%sCancels: %d", d.indent(2), e.TotalToolCancels)
 		}
 	}
 
+	// Show which turns consumed the tokens
+	d.showTurnUsageTable(e)
+
+	// Show where tool wall time went
+	if len(d.State.ToolTime) > 0 {
+		d.Formatter.Plain("")
+		d.Formatter.Plain("%sTool time: %s", d.indent(1), formatToolTime(d.State.ToolTime))
+	}
+
 	d.Formatter.Plain("===========================")
 }
 
@@ -925,10 +1914,50 @@ func (d *Display) ShowStart() {
 		return
 	}
 	// Newline before prompt (matches Claude Code style)
-	fmt.Fprintln(d.Writer)
+	fmt.Fprintln(d.Writer())
 	// Simple header format: "> User: prompt" - plain text, no color
-	d.Formatter.Plain("%s%s", UserPrefix, d.State.UserPrompt)
-	fmt.Fprintln(d.Writer) // Blank line after prompt
+	d.Formatter.Plain("%s%s", UserPrefix, d.shownPrompt(d.State.UserPrompt))
+	// Run settings (model, limits, ...) as "Label: value" lines
+	for _, detail := range d.startDetails {
+		d.Formatter.Info("%s: %s", detail.label, detail.value)
+	}
+	fmt.Fprintln(d.Writer()) // Blank line after prompt
+}
+
+// maxWatchPaths is how many changed paths the --watch separator names
+// before summarizing the rest as a count.
+const maxWatchPaths = 3
+
+// ShowWatchSeparator marks the start of a --watch re-run, naming the changed
+// paths that triggered it.
+func (d *Display) ShowWatchSeparator(changed []string) {
+	if d.Verbosity == VerbosityQuiet {
+		return
+	}
+	names := changed
+	if len(names) > maxWatchPaths {
+		names = names[:maxWatchPaths]
+	}
+	summary := strings.Join(names, ", ")
+	if more := len(changed) - len(names); more > 0 {
+		summary += fmt.Sprintf(" (+%d more)", more)
+	}
+	fmt.Fprintln(d.Writer())
+	d.Formatter.Info("%s changed: %s %s", Rule, summary, Rule)
+}
+
+// shownPrompt returns prompt as it should appear in display output.
+func (d *Display) shownPrompt(prompt string) string {
+	if d.HidePrompt {
+		return HiddenPrompt
+	}
+	return prompt
+}
+
+// AddStartDetail registers a "Label: value" line shown under the prompt by
+// ShowStart, e.g. the effective model.
+func (d *Display) AddStartDetail(label, value string) {
+	d.startDetails = append(d.startDetails, startDetail{label: label, value: value})
 }
 
 // ShowAllowedTools displays the allowed tools banner.
@@ -936,7 +1965,7 @@ func (d *Display) ShowAllowedTools(tools string, dangerous bool) {
 	if d.Verbosity == VerbosityQuiet {
 		return
 	}
-	fmt.Fprintln(d.Writer) // Blank line before banner
+	fmt.Fprintln(d.Writer()) // Blank line before banner
 	if dangerous {
 		d.Formatter.Warning("AllowedTools: ALL (dangerous mode)")
 	} else {
@@ -944,13 +1973,11 @@ func (d *Display) ShowAllowedTools(tools string, dangerous bool) {
 	}
 }
 
-// ShowPermissionMode displays the permission mode banner.
+// ShowPermissionMode adds the permission mode to the start banner, so call
+// it before ShowStart.
 func (d *Display) ShowPermissionMode(mode string) {
-	if d.Verbosity == VerbosityQuiet {
-		return
-	}
 	if mode == "" {
 		return // Don't show if not specified
 	}
-	d.Formatter.Info("Permission Mode: %s", mode)
+	d.AddStartDetail("Permission Mode", mode)
 }
@@ -12,10 +12,7 @@ import (
 // newTestDisplay creates a Display wired to a bytes.Buffer for both display
 // and JSON output, making it easy to assert on both streams.
 func newTestDisplay(jsonBuf *bytes.Buffer) *Display {
-	formatter := NewFormatter(false, false, &bytes.Buffer{})
-	d := NewDisplay(formatter, VerbosityNormal)
-	d.JSONWriter = jsonBuf
-	return d
+	return NewDisplayWithWriters(&bytes.Buffer{}, jsonBuf, false, false, VerbosityNormal)
 }
 
 // decodeLines parses newline-delimited JSON objects from buf into a slice of
@@ -195,3 +192,53 @@ func TestJSONWriter_DisplayUnchanged(t *testing.T) {
 		t.Errorf("expected display buf to contain streamed text, got %q", displayBuf.String())
 	}
 }
+
+func TestEventWriter_Envelope(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewFormatter(false, false, &bytes.Buffer{})
+	d := NewDisplay(formatter, VerbosityNormal)
+	d.EventWriter = buf
+	d.EventFieldPrefix = "cp_"
+
+	delta := events.StreamEvent{}
+	delta.Type = "stream_event"
+	delta.Event.Type = "content_block_delta"
+	delta.Event.Delta = &events.Delta{Text: "hi"}
+	d.HandleEvent(delta)
+
+	result := events.ResultEvent{}
+	result.Type = "result"
+	result.NumTurns = 1
+	d.HandleEvent(result)
+
+	lines := decodeLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 JSON lines, got %d", len(lines))
+	}
+	for i, want := range []string{"stream_event", "result"} {
+		if lines[i]["cp_type"] != want {
+			t.Errorf("line %d: expected cp_type=%s, got %v", i, want, lines[i]["cp_type"])
+		}
+		if lines[i]["cp_seq"] != float64(i+1) {
+			t.Errorf("line %d: expected cp_seq=%d, got %v", i, i+1, lines[i]["cp_seq"])
+		}
+		if _, ok := lines[i]["cp_event"].(map[string]interface{}); !ok {
+			t.Errorf("line %d: expected cp_event object, got %T", i, lines[i]["cp_event"])
+		}
+	}
+}
+
+func TestJSONWriter_ResultGitContext(t *testing.T) {
+	var jsonBuf bytes.Buffer
+	d := newTestDisplay(&jsonBuf)
+	d.GitBranch, d.GitCommit = "main", "abc1234"
+
+	result := events.ResultEvent{}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	lines := decodeLines(t, &jsonBuf)
+	if len(lines) != 1 || lines[0]["git_branch"] != "main" || lines[0]["git_commit"] != "abc1234" {
+		t.Errorf("expected git fields on the result event, got %v", lines)
+	}
+}
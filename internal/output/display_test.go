@@ -0,0 +1,1055 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// newBufferedDisplay creates a Display writing plain (uncolored) output to
+// the returned buffer.
+func newBufferedDisplay(verbosity Verbosity) (*Display, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return NewDisplayWithWriters(buf, nil, false, false, verbosity), buf
+}
+
+// toolUseEvent builds an assistant event containing a single tool_use block.
+func toolUseEvent(id, name string, input map[string]interface{}) events.AssistantEvent {
+	e := events.AssistantEvent{}
+	e.Type = "assistant"
+	e.Message.Content = []events.ContentBlock{
+		{Type: "tool_use", ID: id, Name: name, Input: input},
+	}
+	return e
+}
+
+func TestFileSummary_GroupsByOperation(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.ShowFileSummary = true
+
+	d.HandleEvent(toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolUseEvent("t2", "Edit", map[string]interface{}{"file_path": "b.go"}))
+	d.HandleEvent(toolUseEvent("t3", "Write", map[string]interface{}{"file_path": "c.go"}))
+	d.HandleEvent(toolUseEvent("t4", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolUseEvent("t5", "MultiEdit", map[string]interface{}{"file_path": "d.go"}))
+	d.HandleEvent(toolUseEvent("t6", "NotebookEdit", map[string]interface{}{"notebook_path": "e.ipynb"}))
+
+	result := events.ResultEvent{}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	out := buf.String()
+	if !strings.Contains(out, "Written/Edited (4):") || !strings.Contains(out, "e.ipynb") {
+		t.Errorf("expected 4 modified files, including the notebook, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Read (1):") {
+		t.Errorf("expected duplicate reads to be collapsed, got:\n%s", out)
+	}
+}
+
+func TestFileSummary_PerSession(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.ShowFileSummary = true
+	result := events.ResultEvent{}
+	result.Type = "result"
+
+	d.HandleEvent(toolUseEvent("t1", "Write", map[string]interface{}{"file_path": "first.go"}))
+	d.HandleEvent(result)
+	buf.Reset()
+
+	// A failed session still lists the files it touched, and only those
+	d.HandleEvent(toolUseEvent("t2", "Edit", map[string]interface{}{"file_path": "second.go"}))
+	failed := result
+	failed.Subtype = "error_max_turns"
+	failed.IsError = true
+	d.HandleEvent(failed)
+
+	out := buf.String()
+	if !strings.Contains(out, "Written/Edited (1):") || !strings.Contains(out, "second.go") {
+		t.Errorf("expected the second session's file, got:\n%s", out)
+	}
+	if strings.Contains(out, "first.go") {
+		t.Errorf("expected the first session's files to be cleared, got:\n%s", out)
+	}
+}
+
+func TestFileSummary_DisabledByDefault(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+
+	d.HandleEvent(toolUseEvent("t1", "Write", map[string]interface{}{"file_path": "c.go"}))
+	result := events.ResultEvent{}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	if strings.Contains(buf.String(), "Files changed") {
+		t.Errorf("file summary should be opt-in, got:\n%s", buf.String())
+	}
+}
+
+// toolResultEvent builds a user event containing a single tool_result block.
+func toolResultEvent(id, content string, isError bool) events.UserEvent {
+	e := events.UserEvent{}
+	e.Type = "user"
+	e.Message.Role = "user"
+	e.Message.Content = []events.ContentBlock{
+		{Type: "tool_result", ToolUseID: id, ContentString: content, IsError: isError},
+	}
+	return e
+}
+
+func TestVerboseMatches_GrepContent(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.MatchLimit = 2
+
+	d.HandleEvent(toolUseEvent("g1", "Grep", map[string]interface{}{"pattern": "TODO"}))
+	d.HandleEvent(toolResultEvent("g1", "a.go:10:// TODO one\nb.go:22:// TODO two\nc.go:3:// TODO three", false))
+
+	out := buf.String()
+	if !strings.Contains(out, "  a.go:10  // TODO one") {
+		t.Errorf("expected file:line prefixed match, got:\n%s", out)
+	}
+	if strings.Contains(out, "c.go:3") {
+		t.Errorf("expected matches beyond the limit to be hidden, got:\n%s", out)
+	}
+	if !strings.Contains(out, "... and 1 more") {
+		t.Errorf("expected overflow note, got:\n%s", out)
+	}
+}
+
+func TestIndentWidth(t *testing.T) {
+	for _, tt := range []struct {
+		width  int
+		params string
+		value  string
+	}{
+		{0, "  Parameters:\n", "    file_path: a.go\n"},
+		{4, "    Parameters:\n", "        file_path: a.go\n"},
+	} {
+		d, buf := newBufferedDisplay(VerbosityVerbose)
+		d.IndentWidth = tt.width
+		d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+
+		out := buf.String()
+		if !strings.Contains(out, "\n"+tt.params+tt.value) {
+			t.Errorf("IndentWidth %d: expected %q then %q, got %q", tt.width, tt.params, tt.value, out)
+		}
+	}
+}
+
+func TestVerboseMatches_GlobFiles(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+
+	d.HandleEvent(toolUseEvent("g2", "Glob", map[string]interface{}{"pattern": "*.go"}))
+	d.HandleEvent(toolResultEvent("g2", "/src/main.go\n/src/util.go\n", false))
+
+	out := buf.String()
+	if !strings.Contains(out, "Found 2 files") || !strings.Contains(out, "  /src/util.go") {
+		t.Errorf("expected glob file list, got:\n%s", out)
+	}
+}
+
+// streamEvent builds a stream_event wrapping the given message event type.
+func streamEvent(eventType string) events.StreamEvent {
+	e := events.StreamEvent{}
+	e.Type = "stream_event"
+	e.Event.Type = eventType
+	return e
+}
+
+// textBlockEvents returns the stream events for a single streamed text block.
+func textBlockEvents(text string) []events.Event {
+	start := streamEvent("content_block_start")
+	start.Event.ContentBlock = &events.ContentBlock{Type: "text"}
+	delta := streamEvent("content_block_delta")
+	delta.Event.Delta = &events.Delta{Type: "text_delta", Text: text}
+	return []events.Event{start, delta, streamEvent("content_block_stop")}
+}
+
+func TestNewDisplayWithWriters_SingleWriter(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.SetUserPrompt("hi")
+	d.ShowStart()
+	for _, e := range textBlockEvents("Hello") {
+		d.HandleEvent(e)
+	}
+
+	// Direct newlines and formatter output must interleave on the same writer.
+	want := "\n> User: hi\n\n\n" + Bullet + " Hello\n"
+	if buf.String() != want {
+		t.Errorf("display output mismatch\nwant: %q\ngot:  %q", want, buf.String())
+	}
+}
+
+// textToolTextEvents returns a text → tool call → text exchange as the CLI
+// streams it: a message with text and a Read call, the tool result, then a
+// message with the answer.
+func textToolTextEvents() []events.Event {
+	seq := []events.Event{streamEvent("message_start")}
+	seq = append(seq, textBlockEvents("Let me check.")...)
+	toolStart := streamEvent("content_block_start")
+	toolStart.Event.ContentBlock = &events.ContentBlock{Type: "tool_use", ID: "t1", Name: "Read"}
+	seq = append(seq, toolStart, streamEvent("content_block_stop"))
+	seq = append(seq, toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	seq = append(seq, streamEvent("message_stop"))
+	seq = append(seq, toolResultEvent("t1", "one\ntwo", false))
+	seq = append(seq, streamEvent("message_start"))
+	seq = append(seq, textBlockEvents("It is fine.")...)
+	return append(seq, streamEvent("message_stop"))
+}
+
+func TestTextToolTextSpacing(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(d *Display)
+		want  string
+	}{
+		{
+			name:  "normal",
+			setup: func(d *Display) {},
+			want: "\n" + Bullet + " Let me check.\n" +
+				"\n" + Bullet + " Read(a.go)\n" +
+				TreeBranch + "Read 2 lines\n" +
+				"\n" + Bullet + " It is fine.\n" +
+				"\n",
+		},
+		{
+			name:  "hidden tool output",
+			setup: func(d *Display) { d.HideToolOutput = true },
+			want: "\n" + Bullet + " Let me check.\n" +
+				"\n" + Bullet + " Read(a.go)\n" +
+				"\n" + Bullet + " It is fine.\n" +
+				"\n",
+		},
+		{
+			name:  "turn progress",
+			setup: func(d *Display) { d.MaxTurns = 5 },
+			want: "\n" + Bullet + " Let me check.\n" +
+				"\n" + Bullet + " Read(a.go)\n" +
+				TreeBranch + "Read 2 lines\n" +
+				"Turn 1/5 (20%)\n" +
+				"\n" + Bullet + " It is fine.\n" +
+				"\n" +
+				"Turn 2/5 (40%)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, buf := newBufferedDisplay(VerbosityNormal)
+			tt.setup(d)
+			for _, e := range textToolTextEvents() {
+				d.HandleEvent(e)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("display output mismatch\nwant: %q\ngot:  %q", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestSetWidth_TruncatesVerboseContent(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.SetWidth(40)
+
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "cat long.txt"}))
+	d.HandleEvent(toolResultEvent("b1", "short\n"+strings.Repeat("x", 200), false))
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if len(line) > 40 && strings.HasPrefix(line, "  x") {
+			t.Errorf("expected content truncated to terminal width 40, got %d chars", len(line))
+		}
+	}
+	if !strings.Contains(buf.String(), "...") {
+		t.Errorf("expected truncation marker, got:\n%s", buf.String())
+	}
+}
+
+func TestHideToolOutput(t *testing.T) {
+	for _, verbosity := range []Verbosity{VerbosityNormal, VerbosityVerbose} {
+		d, buf := newBufferedDisplay(verbosity)
+		d.HideToolOutput = true
+
+		d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+		d.HandleEvent(toolResultEvent("r1", "package main\n", false))
+		d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "false"}))
+		d.HandleEvent(toolResultEvent("b1", "exit status 1", true))
+
+		out := buf.String()
+		if !strings.Contains(out, "Read(a.go)") {
+			t.Errorf("verbosity %d: expected tool call line, got:\n%s", verbosity, out)
+		}
+		if strings.Contains(out, "Read 1 lines") || strings.Contains(out, "package main") {
+			t.Errorf("verbosity %d: expected successful result hidden, got:\n%s", verbosity, out)
+		}
+		if !strings.Contains(out, "exit status 1") {
+			t.Errorf("verbosity %d: expected error result shown, got:\n%s", verbosity, out)
+		}
+		if len(d.State.PendingTools) != 0 {
+			t.Errorf("verbosity %d: expected PendingTools cleared, got %d", verbosity, len(d.State.PendingTools))
+		}
+	}
+}
+
+func TestLabels(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.ShowLabels = true
+	d.ToolLabel = "[{tool}]"
+
+	for _, e := range textBlockEvents("Checking.") {
+		d.HandleEvent(e)
+	}
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "ls"}))
+
+	out := buf.String()
+	if !strings.Contains(out, Bullet+" Assistant: Checking.") {
+		t.Errorf("expected default assistant label, got:\n%s", out)
+	}
+	if !strings.Contains(out, Bullet+` [Bash] Bash(command: "ls")`) {
+		t.Errorf("expected custom tool label, got:\n%s", out)
+	}
+}
+
+func TestShowMetadata_CompactInNormalMode(t *testing.T) {
+	init := events.SystemEvent{
+		Model:      "claude-sonnet-4",
+		Tools:      []events.ToolInfo{{Name: "Read"}, {Name: "Bash"}},
+		McpServers: []events.MCPServerInfo{{Name: "github", Status: "connected"}},
+	}
+	init.Type = "system.init"
+
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(init)
+	if buf.Len() != 0 {
+		t.Errorf("metadata should be opt-in in normal mode, got:\n%s", buf.String())
+	}
+
+	d, buf = newBufferedDisplay(VerbosityNormal)
+	d.ShowMetadata = true
+	d.HandleEvent(init)
+	out := buf.String()
+	if !strings.Contains(out, "Model: claude-sonnet-4 | Tools: 2 | MCP servers: 1") {
+		t.Errorf("expected compact metadata line, got:\n%s", out)
+	}
+	if strings.Contains(out, "Session Metadata") {
+		t.Errorf("full metadata should be reserved for verbose mode, got:\n%s", out)
+	}
+}
+
+func TestMaxToolParamBytes(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.MaxToolParamBytes = 16
+
+	content := strings.Repeat("x", 100)
+	d.HandleEvent(toolUseEvent("w1", "Write", map[string]interface{}{
+		"file_path": "big.txt",
+		"content":   content,
+	}))
+
+	pending := d.State.PendingTools["w1"]
+	if pending == nil {
+		t.Fatal("expected pending Write tool")
+	}
+	stored := pending.Input["content"].(string)
+	if !strings.HasPrefix(stored, strings.Repeat("x", 16)+"...") || !strings.Contains(stored, "(84 bytes truncated)") {
+		t.Errorf("expected stored content to be capped, got %q", stored)
+	}
+	if pending.Input["file_path"] != "big.txt" {
+		t.Errorf("small parameters should be kept, got %v", pending.Input["file_path"])
+	}
+	if strings.Contains(buf.String(), strings.Repeat("x", 17)) {
+		t.Errorf("expected rendered content to be capped, got:\n%s", buf.String())
+	}
+}
+
+func TestHeadless_TracksWithoutRendering(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityQuiet)
+	d.Headless = true
+	d.LoopGuard = 1
+
+	read := map[string]interface{}{"file_path": "file.go"}
+	d.HandleEvent(toolUseEvent("r1", "Read", read))
+	d.HandleEvent(toolUseEvent("r2", "Read", read))
+	d.HandleEvent(toolResultEvent("r2", "permission denied", true))
+	for _, e := range textBlockEvents("the answer") {
+		d.HandleEvent(e)
+	}
+	d.HandleEvent(successResult())
+
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing rendered, got:\n%s", buf.String())
+	}
+	if loop, ok := d.ToolLoop(); !ok || loop != "Read file.go ×2" {
+		t.Errorf("ToolLoop() = %q, %v; want the loop tracked", loop, ok)
+	}
+}
+
+func TestLoopGuard(t *testing.T) {
+	d, _ := newBufferedDisplay(VerbosityNormal)
+	d.LoopGuard = 2
+
+	read := map[string]interface{}{"file_path": "file.go"}
+	d.HandleEvent(toolUseEvent("r1", "Read", read))
+	d.HandleEvent(toolUseEvent("r2", "Read", map[string]interface{}{"file_path": "other.go"}))
+	d.HandleEvent(toolUseEvent("r3", "Read", read))
+	d.HandleEvent(toolUseEvent("r4", "Read", read))
+	if loop, ok := d.ToolLoop(); ok {
+		t.Fatalf("loop reported before threshold exceeded: %q", loop)
+	}
+
+	d.HandleEvent(toolUseEvent("r5", "Read", read))
+	loop, ok := d.ToolLoop()
+	if !ok || loop != "Read file.go ×3" {
+		t.Errorf("ToolLoop() = %q, %v; want %q", loop, ok, "Read file.go ×3")
+	}
+
+	d.ResetToolLoop()
+	if _, ok := d.ToolLoop(); ok {
+		t.Error("expected ResetToolLoop to clear the detected loop")
+	}
+}
+
+func TestMaxTurnsProgress(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.MaxTurns = 4
+
+	// Turn 1 ends on a tool call: progress waits until after the result
+	d.HandleEvent(streamEvent("message_start"))
+	d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(streamEvent("message_stop"))
+	if strings.Contains(buf.String(), "Turn 1/4") {
+		t.Fatalf("progress should not split a tool call from its result, got:\n%s", buf.String())
+	}
+	d.HandleEvent(toolResultEvent("r1", "package main", false))
+
+	// Turn 2 is plain text
+	d.HandleEvent(streamEvent("message_start"))
+	for _, e := range textBlockEvents("Done.") {
+		d.HandleEvent(e)
+	}
+	d.HandleEvent(streamEvent("message_stop"))
+
+	out := buf.String()
+	first := strings.Index(out, "Turn 1/4 (25%)")
+	result := strings.Index(out, TreeBranch)
+	second := strings.Index(out, "Turn 2/4 (50%)")
+	if first < 0 || second < 0 {
+		t.Fatalf("expected progress for both turns, got:\n%s", out)
+	}
+	if result < 0 || result > first {
+		t.Errorf("expected turn 1 progress after its tool result, got:\n%s", out)
+	}
+	if strings.Count(out, "Turn ") != 2 {
+		t.Errorf("expected exactly two progress lines, got:\n%s", out)
+	}
+}
+
+func TestMaxTurnsProgress_DisabledByDefault(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(streamEvent("message_start"))
+	d.HandleEvent(streamEvent("message_stop"))
+	if strings.Contains(buf.String(), "Turn ") {
+		t.Errorf("progress should only show with --max-turns, got:\n%s", buf.String())
+	}
+}
+
+func TestToolTime(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "make"}))
+	d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.State.PendingTools["b1"].StartedAt = time.Now().Add(-2 * time.Second)
+	d.HandleEvent(toolResultEvent("b1", "ok", false))
+	d.HandleEvent(toolResultEvent("r1", "package main", false))
+
+	// Interrupted call: its result never arrives, so it is not counted
+	d.HandleEvent(toolUseEvent("g1", "Grep", map[string]interface{}{"pattern": "x"}))
+
+	result := events.ResultEvent{Subtype: "success"}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	out := buf.String()
+	if !strings.Contains(out, "Tool time: Bash 2.0s, Read ") {
+		t.Errorf("expected per-tool time sorted slowest first, got:\n%s", out)
+	}
+	if strings.Contains(out, "Grep 0") || strings.Contains(out, ", Grep") {
+		t.Errorf("tool without a result should not be timed, got:\n%s", out)
+	}
+}
+
+func TestToolTime_PerSession(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	result := events.ResultEvent{Subtype: "success"}
+	result.Type = "result"
+
+	// A first session at normal verbosity, which doesn't show tool time
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "make"}))
+	d.State.PendingTools["b1"].StartedAt = time.Now().Add(-2 * time.Second)
+	d.HandleEvent(toolResultEvent("b1", "ok", false))
+	d.HandleEvent(result)
+
+	// The second session's summary counts only its own calls
+	d.Verbosity = VerbosityVerbose
+	d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolResultEvent("r1", "package main", false))
+	d.HandleEvent(result)
+
+	if out := buf.String(); !strings.Contains(out, "Tool time: Read ") || strings.Contains(out, "Bash 2") {
+		t.Errorf("expected only the second session's tool time, got:\n%s", out)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.RelativeTime = true
+	d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.State.SessionStart = time.Now().Add(-4200 * time.Millisecond)
+	d.HandleEvent(toolResultEvent("r1", "package main", false))
+	d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "make"}))
+	d.HandleEvent(toolResultEvent("b1", "Permission to use Bash has been denied.", true))
+
+	out := buf.String()
+	if !strings.Contains(out, TreeBranch+"Read 1 lines (+4.2s)\n") {
+		t.Errorf("expected the elapsed time on the result line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Tool denied (not in allowed-tools) (+4.2s)\n") {
+		t.Errorf("expected the elapsed time on the denial line, got:\n%s", out)
+	}
+
+	// A result event ends the session; the next one starts its own clock
+	result := events.ResultEvent{Subtype: "success"}
+	result.Type = "result"
+	d.HandleEvent(result)
+	if !d.State.SessionStart.IsZero() {
+		t.Error("expected the session clock to reset after the result")
+	}
+}
+
+func TestDeniedToolsSummary(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	for i, name := range []string{"WebFetch", "Bash", "WebFetch", "Read"} {
+		id := fmt.Sprintf("t%d", i)
+		d.HandleEvent(toolUseEvent(id, name, map[string]interface{}{}))
+		if name == "Read" {
+			d.HandleEvent(toolResultEvent(id, "package main", false))
+		} else {
+			d.HandleEvent(toolResultEvent(id, "Permission to use "+name+" has been denied.", true))
+		}
+	}
+	result := events.ResultEvent{Subtype: "success"}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	if want := "3 tool calls denied: WebFetch ×2, Bash ×1\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got:\n%s", want, buf.String())
+	}
+
+	// The next session starts fresh and shows no summary without denials
+	buf.Reset()
+	d.HandleEvent(result)
+	if strings.Contains(buf.String(), "denied") {
+		t.Errorf("expected no denial summary, got:\n%s", buf.String())
+	}
+}
+
+func TestCompactBoundary(t *testing.T) {
+	compact := events.SystemEvent{
+		Subtype:         "compact_boundary",
+		CompactMetadata: &events.CompactMetadata{Trigger: "auto", PreTokens: 150000},
+	}
+	compact.Type = "system"
+
+	for _, verbosity := range []Verbosity{VerbosityNormal, VerbosityVerbose} {
+		d, buf := newBufferedDisplay(verbosity)
+		d.HandleEvent(compact)
+		if !strings.Contains(buf.String(), "── context compacted (auto, 150000 tokens before) ──") {
+			t.Errorf("verbosity %d: expected compaction marker, got:\n%s", verbosity, buf.String())
+		}
+	}
+}
+
+func TestUnknownSystemSubtype_VerboseOnly(t *testing.T) {
+	unknown := events.SystemEvent{Subtype: "status_update"}
+	unknown.Type = "system"
+
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(unknown)
+	if buf.Len() != 0 {
+		t.Errorf("unknown system subtype should be silent in normal mode, got:\n%s", buf.String())
+	}
+
+	d, buf = newBufferedDisplay(VerbosityVerbose)
+	d.HandleEvent(unknown)
+	if !strings.Contains(buf.String(), "System event: system/status_update") {
+		t.Errorf("expected generic line in verbose mode, got:\n%s", buf.String())
+	}
+}
+
+func TestUnknownSystemEvent_ShowsFields(t *testing.T) {
+	event, err := events.ParseEvent(`{"type":"system","subtype":"api_retry","session_id":"s1","attempt":2,"error":"overloaded","detail":{"delay_ms":500}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.HandleEvent(event)
+
+	want := "System event: system/api_retry\n  attempt: 2\n  detail: {\"delay_ms\":500}\n  error: overloaded\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got:\n%s", want, buf.String())
+	}
+	if strings.Contains(buf.String(), "session_id") {
+		t.Errorf("expected session_id to be skipped, got:\n%s", buf.String())
+	}
+}
+
+// usageTurnEvents returns the stream events for a text-only assistant turn
+// reporting the given token usage.
+func usageTurnEvents(in, out int) []events.Event {
+	start := streamEvent("message_start")
+	start.Event.Message = &events.Message{ID: fmt.Sprintf("msg_%d", in), Usage: &events.Usage{InputTokens: in, OutputTokens: 1}}
+	delta := streamEvent("message_delta")
+	delta.Event.Usage = &events.Usage{OutputTokens: out}
+	evs := []events.Event{start}
+	evs = append(evs, textBlockEvents("ok")...)
+	return append(evs, delta, streamEvent("message_stop"))
+}
+
+func TestPerTurnUsage(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	for _, e := range usageTurnEvents(1200, 340) {
+		d.HandleEvent(e)
+	}
+	for _, e := range usageTurnEvents(5000, 20) {
+		d.HandleEvent(e)
+	}
+
+	result := events.ResultEvent{Subtype: "success", Usage: &events.AggregatedUsage{InputTokens: 6300, OutputTokens: 360}}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	out := buf.String()
+	for _, want := range []string{
+		"Turn 1 [msg_1200]: 1,200 in / 340 out",
+		"Turn 2 [msg_5000]: 5,000 in / 20 out",
+		"Sum of turns: 6,200 in / 360 out",
+		"Final totals: 6,300 in / 360 out (+100 in / +0 out outside these turns)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1,000", 1234567: "1,234,567", -4500: "-4,500"}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestWrap_BreaksLongTokensAtWidth(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.Wrap = true
+	d.SetWidth(10)
+
+	for _, e := range textBlockEvents("abcdefghijklmnop\nxyz") {
+		d.HandleEvent(e)
+	}
+
+	// The bullet prefix takes two columns of the first line
+	if want := "● abcdefgh\nijklmnop\nxyz\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected wrapped text %q, got %q", want, buf.String())
+	}
+}
+
+func TestWrap_OffByDefault(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.SetWidth(10)
+
+	for _, e := range textBlockEvents("abcdefghijklmnop") {
+		d.HandleEvent(e)
+	}
+
+	if !strings.Contains(buf.String(), "abcdefghijklmnop") {
+		t.Errorf("expected unwrapped text, got %q", buf.String())
+	}
+}
+
+func TestToolStatus_CountsOutstandingTools(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.ShowToolStatus = true
+
+	d.HandleEvent(toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolUseEvent("t2", "Read", map[string]interface{}{"file_path": "b.go"}))
+	if !strings.HasSuffix(buf.String(), "⟳ 2 tools running") {
+		t.Fatalf("expected status line for 2 tools, got %q", buf.String())
+	}
+
+	d.HandleEvent(toolResultEvent("t1", "package a", false))
+	if !strings.HasSuffix(buf.String(), "⟳ 1 tool running") {
+		t.Fatalf("expected status line for 1 tool, got %q", buf.String())
+	}
+
+	d.HandleEvent(toolResultEvent("t2", "package b", false))
+	out := buf.String()
+	if strings.Contains(out[strings.LastIndex(out, clearLine):], "running") {
+		t.Errorf("expected status line cleared once no tools remain, got %q", out)
+	}
+}
+
+func TestToolStatus_SuppressedInQuiet(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityQuiet)
+	d.ShowToolStatus = true
+
+	d.HandleEvent(toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	if strings.Contains(buf.String(), "running") {
+		t.Errorf("expected no status line in quiet mode, got %q", buf.String())
+	}
+}
+
+func TestVerboseSubagentCalls_Nested(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.HandleEvent(toolUseEvent("task1", "Task", map[string]interface{}{"description": "explore"}))
+
+	event, err := events.ParseEvent(`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":[
+		{"type":"tool_use","id":"r1","name":"Read","input":{"file_path":"main.go"}},
+		{"type":"tool_result","tool_use_id":"r1","content":"package main\nfunc main() {}"},
+		{"type":"tool_use","id":"t2","name":"Task","input":{"description":"dig deeper"}},
+		{"type":"tool_result","tool_use_id":"t2","content":[
+			{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"ls"}},
+			{"type":"tool_result","tool_use_id":"b1","content":"boom","is_error":true}
+		]},
+		{"type":"text","text":"Done exploring"}
+	]}]}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.HandleEvent(event)
+
+	out := buf.String()
+	for _, want := range []string{
+		"    ● Read(main.go)\n",
+		"    " + TreeBranch + "package main " + Ellipsis + "\n",
+		"        ● Bash(",
+		"        " + TreeBranch + "boom\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestVerboseSubagentEvents_ParentLinkage(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.HandleEvent(toolUseEvent("task1", "Task", map[string]interface{}{"description": "explore"}))
+
+	for _, line := range []string{
+		`{"type":"stream_event","parent_tool_use_id":"task1","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"agent musing"}}}`,
+		`{"type":"assistant","uuid":"u1","parent_tool_use_id":"task1","message":{"role":"assistant","content":[{"type":"tool_use","id":"r1","name":"Read","input":{"file_path":"main.go"}}]}}`,
+		`{"type":"user","uuid":"u2","parent_tool_use_id":"task1","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"r1","content":"package main\nfunc main() {}"}]}}`,
+		`{"type":"assistant","uuid":"u3","parent_tool_use_id":"task1","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Task","input":{"description":"dig deeper"}}]}}`,
+		`{"type":"assistant","uuid":"u4","parent_tool_use_id":"t2","message":{"role":"assistant","content":[{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"ls"}}]}}`,
+		`{"type":"user","uuid":"u5","parent_tool_use_id":"t2","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"b1","content":"boom","is_error":true}]}}`,
+	} {
+		event, err := events.ParseEvent(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.HandleEvent(event)
+	}
+	d.HandleEvent(toolResultEvent("task1", "Done exploring", false))
+
+	out := buf.String()
+	for _, want := range []string{
+		"\n    ● Read(main.go)\n",
+		"\n    " + TreeBranch + "package main " + Ellipsis + "\n",
+		"\n    ● Task(",
+		"\n        ● Bash(",
+		"\n        " + TreeBranch + "boom\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "agent musing") {
+		t.Errorf("expected sub-agent text to be left to the Task result, got:\n%s", out)
+	}
+	if _, pending := d.State.PendingTools["r1"]; pending {
+		t.Error("expected sub-agent calls not to be tracked as top-level calls")
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		maxLen int
+		want   string
+	}{
+		{"short", 10, "short"},
+		{"exactly ten", 11, "exactly ten"},
+		{"a longer line of text", 10, "a longe..."},
+		{"héllo wörld, ça va", 15, "héllo wörld,..."},
+		{"日本語のテキストです", 10, "日本語..."},
+		{"naïve café", 10, "naïve café"},
+	}
+	for _, tt := range tests {
+		got := truncateLine(tt.line, tt.maxLen)
+		if got != tt.want {
+			t.Errorf("truncateLine(%q, %d) = %q, want %q", tt.line, tt.maxLen, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateLine(%q, %d) is not valid UTF-8: %q", tt.line, tt.maxLen, got)
+		}
+	}
+}
+
+func TestUseASCIIGlyphs(t *testing.T) {
+	bullet, branch, status, rule, ellipsis := Bullet, TreeBranch, StatusGlyph, Rule, Ellipsis
+	defer func() { Bullet, TreeBranch, StatusGlyph, Rule, Ellipsis = bullet, branch, status, rule, ellipsis }()
+	UseASCIIGlyphs()
+
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.ShowToolStatus = true
+	d.HandleEvent(toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolResultEvent("t1", "package a", false))
+
+	out := buf.String()
+	for _, r := range StripANSI(out) {
+		if r > 127 {
+			t.Fatalf("expected ASCII-only output, found %q in:\n%s", r, out)
+		}
+	}
+	if !strings.Contains(out, "* Read(a.go)") {
+		t.Errorf("expected ASCII bullet, got:\n%s", out)
+	}
+}
+
+func TestToolResult_Duplicates(t *testing.T) {
+	for _, verbosity := range []Verbosity{VerbosityNormal, VerbosityVerbose} {
+		d, buf := newBufferedDisplay(verbosity)
+		d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "make"}))
+		d.HandleEvent(toolResultEvent("b1", "build ok", false))
+		first := buf.String()
+		d.HandleEvent(toolResultEvent("b1", "build ok", false))
+		if buf.String() != first {
+			t.Errorf("verbosity %d: exact duplicate should be ignored, got:\n%s", verbosity, buf.String())
+		}
+
+		d.HandleEvent(toolResultEvent("b1", "build failed", true))
+		out := buf.String()
+		if !strings.Contains(out, TreeBranch+"Updated result: build failed") {
+			t.Errorf("verbosity %d: expected an update note for a differing result, got:\n%s", verbosity, out)
+		}
+
+		// The updated result is now the one duplicates are compared with
+		buf.Reset()
+		d.HandleEvent(toolResultEvent("b1", "build failed", true))
+		if buf.Len() != 0 {
+			t.Errorf("verbosity %d: repeated update should be ignored, got %q", verbosity, buf.String())
+		}
+	}
+}
+
+func TestToolResult_ErrorInRed(t *testing.T) {
+	for _, verbosity := range []Verbosity{VerbosityNormal, VerbosityVerbose} {
+		buf := &strings.Builder{}
+		d := NewDisplay(NewFormatter(true, false, buf), verbosity)
+		d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "a.go"}))
+		d.HandleEvent(toolResultEvent("r1", "package main", false))
+		d.HandleEvent(toolUseEvent("r2", "Read", map[string]interface{}{"file_path": "missing.go"}))
+		d.HandleEvent(toolResultEvent("r2", "\nFile does not exist.\nCurrent directory: /src", true))
+
+		out := buf.String()
+		if !strings.Contains(out, "\n"+TreeBranch+"Read 1 lines\n") {
+			t.Errorf("verbosity %d: expected a plain success line, got %q", verbosity, out)
+		}
+		if want := colorRed + TreeBranch + "Error: File does not exist." + colorReset; !strings.Contains(out, want) {
+			t.Errorf("verbosity %d: expected one red error line %q, got %q", verbosity, want, out)
+		}
+	}
+}
+
+func TestRawBashOutput(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("\x1b[32mok %d\x1b[0m", i)
+	}
+	lines[5] += "\x1b[2J"
+	content := strings.Join(lines, "\n") + "\n\x1b[31mFAIL"
+
+	render := func(color, unsafe bool) string {
+		buf := &strings.Builder{}
+		d := NewDisplay(NewFormatter(color, false, buf), VerbosityVerbose)
+		d.RawBashOutput = true
+		d.UnsafeRawBashOutput = unsafe
+		d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "go test"}))
+		d.HandleEvent(toolResultEvent("b1", content, false))
+		return buf.String()
+	}
+
+	out := render(true, false)
+	if strings.Contains(out, "Showing") || !strings.Contains(out, "  \x1b[32mok 19\x1b[0m"+colorReset+"\n") {
+		t.Errorf("expected every line with its colors, got %q", out)
+	}
+	if !strings.Contains(out, "  \x1b[31mFAIL"+colorReset+"\n") {
+		t.Errorf("expected an unclosed color to be reset, got %q", out)
+	}
+	if strings.Contains(out, "\x1b[2J") {
+		t.Errorf("expected the screen clear to be removed, got %q", out)
+	}
+	if out := render(true, true); !strings.Contains(out, "\x1b[2J") {
+		t.Errorf("expected unsafe mode to keep every sequence, got %q", out)
+	}
+	if out := render(false, false); strings.Contains(out, "\n  \x1b") || !strings.Contains(out, "\n  ok 19\n") {
+		t.Errorf("expected colors stripped without color, got %q", out)
+	}
+}
+
+func TestToolResult_BinaryOutputSanitized(t *testing.T) {
+	content := "PK\x03\x04\x00\x00\xff\xfe ok\r\nnext\tline \u00e9\x1b[2J"
+	for _, verbosity := range []Verbosity{VerbosityNormal, VerbosityVerbose} {
+		d, buf := newBufferedDisplay(verbosity)
+		d.HandleEvent(toolUseEvent("b1", "Bash", map[string]interface{}{"command": "cat a.zip"}))
+		d.HandleEvent(toolResultEvent("b1", content, false))
+		out := buf.String()
+		if strings.ContainsAny(out, "\x00\x03\x04\r\x1b") || !utf8.ValidString(out) {
+			t.Errorf("verbosity %d: expected control characters and invalid bytes replaced, got %q", verbosity, out)
+		}
+		if !strings.Contains(out, "PK"+strings.Repeat(Unprintable, 6)+" ok\n") {
+			t.Errorf("verbosity %d: expected a placeholder per unprintable byte, got %q", verbosity, out)
+		}
+	}
+
+	want := "PK" + strings.Repeat(Unprintable, 6) + " ok\nnext\tline \u00e9" + Unprintable + "[2J"
+	if got := sanitizeToolText(content); got != want {
+		t.Errorf("sanitizeToolText = %q, want %q", got, want)
+	}
+}
+
+func TestToolResultError_Sanitized(t *testing.T) {
+	raw := json.RawMessage(`"bad\u001b[2Jthing\u0007"`)
+	errorBlock := events.ContentBlock{Type: "tool_result", ToolUseID: "t1", Content: raw, IsError: true}
+
+	var user events.UserEvent
+	line := `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":` + string(raw) + `,"is_error":true}]}}`
+	if err := json.Unmarshal([]byte(line), &user); err != nil {
+		t.Fatal(err)
+	}
+	message := events.AssistantMessageEvent{}
+	message.Type = "assistant_message"
+	message.Message.Content = []events.ContentBlock{errorBlock}
+	start := streamEvent("content_block_start")
+	start.Event.ContentBlock = &errorBlock
+
+	tests := []struct {
+		name      string
+		verbosity Verbosity
+		event     events.Event
+	}{
+		{"quiet user event", VerbosityQuiet, user},
+		{"quiet assistant message", VerbosityQuiet, message},
+		{"quiet stream block", VerbosityQuiet, start},
+		{"normal assistant message", VerbosityNormal, message},
+		{"normal stream block", VerbosityNormal, start},
+		{"verbose assistant message", VerbosityVerbose, message},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, buf := newBufferedDisplay(tt.verbosity)
+			d.HandleEvent(tt.event)
+			out := buf.String()
+			if !strings.Contains(out, "bad"+Unprintable+"[2Jthing"+Unprintable) || strings.ContainsAny(out, "\x1b\x07\"") {
+				t.Errorf("expected the decoded error with control characters replaced, got %q", out)
+			}
+		})
+	}
+}
+
+func TestToolResult_UnknownIDIgnored(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(toolResultEvent("nope", "stray", false))
+	if buf.Len() != 0 {
+		t.Errorf("expected no output for an unknown tool ID, got %q", buf.String())
+	}
+}
+
+func TestShowPermissionMode_InStartBanner(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.SetUserPrompt("hi")
+	d.ShowPermissionMode("plan")
+	d.ShowStart()
+
+	if want := UserPrefix + "hi\nPermission Mode: plan\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q in banner, got %q", want, buf.String())
+	}
+}
+
+func TestShowStart_HidePrompt(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HidePrompt = true
+	d.SetUserPrompt("password is hunter2")
+	d.ShowStart()
+
+	if strings.Contains(buf.String(), "hunter2") || !strings.Contains(buf.String(), UserPrefix+HiddenPrompt+"\n") {
+		t.Errorf("expected the prompt to be hidden, got %q", buf.String())
+	}
+}
+
+func TestShowWatchSeparator(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.ShowWatchSeparator([]string{"a.go", "b.go", "c.go", "d.go", "e.go"})
+	if want := Rule + " changed: a.go, b.go, c.go (+2 more) " + Rule; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	d, buf = newBufferedDisplay(VerbosityQuiet)
+	d.ShowWatchSeparator([]string{"a.go"})
+	if buf.Len() != 0 {
+		t.Errorf("expected no separator in quiet mode, got %q", buf.String())
+	}
+}
+
+func TestFormatter_EmojiSet(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := NewFormatter(false, true, buf)
+	f.EmojiSet = map[string]string{"error": "x", "tool": ">", "info": "i", "warning": ""}
+
+	f.ErrorWithEmoji(EmojiError, "failed")
+	f.WarningWithEmoji(EmojiWarning, "careful")
+	f.SuccessWithEmoji(EmojiDone, "done")
+	f.InfoWithEmoji("", "note")
+	f.ToolCall(Bullet, "Bash(ls)")
+
+	want := "x failed\ncareful\n" + EmojiDone + " done\ni note\n> Bash(ls)\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	// --no-emoji suppresses custom glyphs too, leaving the plain bullet
+	buf.Reset()
+	f.EmojiEnabled = false
+	f.ErrorWithEmoji(EmojiError, "failed")
+	f.ToolCall(Bullet, "Bash(ls)")
+	if want := "failed\n" + Bullet + " Bash(ls)\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	// The custom tool glyph still replaces the ASCII bullet
+	bullet := Bullet
+	defer func() { Bullet = bullet }()
+	Bullet = "*"
+	buf.Reset()
+	f.EmojiEnabled = true
+	f.ToolCall(Bullet, "Bash(ls)")
+	if want := "> Bash(ls)\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
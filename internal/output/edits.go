@@ -0,0 +1,161 @@
+package output
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// editHookQueueSize is how many edited files may wait for the --on-edit
+// command before the display waits for the queue to drain.
+const editHookQueueSize = 64
+
+// EditHooks runs a command for each file Claude writes or edits (--on-edit),
+// one file at a time on a background goroutine so the display isn't held up
+// while it runs. Failures are kept until the display reports them between
+// events. A nil *EditHooks is valid and does nothing.
+type EditHooks struct {
+	run      func(path string) error
+	queue    chan string
+	pending  sync.WaitGroup
+	mu       sync.Mutex
+	failures []string
+}
+
+// NewEditHooks creates EditHooks that call run for each edited file. run's
+// error is reported as the file's failure.
+func NewEditHooks(run func(path string) error) *EditHooks {
+	h := &EditHooks{run: run, queue: make(chan string, editHookQueueSize)}
+	go h.worker()
+	return h
+}
+
+// worker runs the command for each queued file in turn.
+func (h *EditHooks) worker() {
+	for path := range h.queue {
+		if err := h.run(path); err != nil {
+			h.mu.Lock()
+			h.failures = append(h.failures, path+": "+err.Error())
+			h.mu.Unlock()
+		}
+		h.pending.Done()
+	}
+}
+
+// add queues path for the command.
+func (h *EditHooks) add(path string) {
+	if h == nil {
+		return
+	}
+	h.pending.Add(1)
+	h.queue <- path
+}
+
+// Wait returns once every queued file has been handled.
+func (h *EditHooks) Wait() {
+	if h == nil {
+		return
+	}
+	h.pending.Wait()
+}
+
+// Close stops the background goroutine once the queue is drained. The hooks
+// can't be used afterwards.
+func (h *EditHooks) Close() {
+	if h == nil {
+		return
+	}
+	close(h.queue)
+}
+
+// takeFailures returns the failures since the last call, as "path: error".
+func (h *EditHooks) takeFailures() []string {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	failures := h.failures
+	h.failures = nil
+	return failures
+}
+
+// trackFileEdits queues the file of each Write, Edit, MultiEdit or
+// NotebookEdit call for EditHooks once the call's result arrives without an
+// error. No-op when EditHooks is nil.
+func (d *Display) trackFileEdits(event events.Event) {
+	if d.EditHooks == nil {
+		return
+	}
+	switch e := event.(type) {
+	case events.AssistantEvent:
+		for _, block := range e.Message.Content {
+			if block.Type != "tool_use" {
+				continue
+			}
+			if path := editedFile(block); path != "" {
+				if d.State.EditCalls == nil {
+					d.State.EditCalls = make(map[string]string)
+				}
+				d.State.EditCalls[block.ID] = path
+			}
+		}
+	case events.UserEvent:
+		for _, block := range e.Message.Content {
+			path, ok := d.State.EditCalls[block.ToolUseID]
+			if block.Type != "tool_result" || !ok {
+				continue
+			}
+			delete(d.State.EditCalls, block.ToolUseID)
+			if !block.IsError {
+				d.EditHooks.add(path)
+			}
+		}
+	}
+}
+
+// reportEditHooks warns about each --on-edit command that failed since the
+// last report, unless text is mid-stream. The session's result waits for
+// its outstanding commands first, so their failures come before the
+// summary.
+func (d *Display) reportEditHooks(event events.Event) {
+	if _, ok := event.(events.ResultEvent); ok {
+		d.EditHooks.Wait()
+	}
+	if d.State.InTextBlock {
+		return
+	}
+	d.reportEditHookFailures()
+}
+
+// reportEditHookFailures warns about each --on-edit command that failed
+// since the last report.
+func (d *Display) reportEditHookFailures() {
+	for _, failure := range d.EditHooks.takeFailures() {
+		d.Formatter.Warning("--on-edit command failed for %s", failure)
+	}
+}
+
+// FinishEditHooks waits for the --on-edit commands of the session's edits
+// to finish and reports any failures. Call it when a session ends, before
+// its exit status is shown.
+func (d *Display) FinishEditHooks() {
+	d.EditHooks.Wait()
+	d.reportEditHookFailures()
+}
+
+// editedFile returns the file a tool_use block writes or edits, or "" if
+// it doesn't edit a file.
+func editedFile(block events.ContentBlock) string {
+	param := "file_path"
+	switch strings.ToLower(block.Name) {
+	case "write", "edit", "multiedit":
+	case "notebookedit":
+		param = "notebook_path"
+	default:
+		return ""
+	}
+	path, _ := block.Input[param].(string)
+	return path
+}
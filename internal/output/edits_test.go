@@ -0,0 +1,54 @@
+package output
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestEditHooks_RunForSuccessfulEdits(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	hooks := NewEditHooks(func(path string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ran = append(ran, path)
+		if path == "bad.go" {
+			return errors.New("exit status 2: syntax error")
+		}
+		return nil
+	})
+	defer hooks.Close()
+
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.EditHooks = hooks
+	d.HandleEvent(toolUseEvent("w1", "Write", map[string]interface{}{"file_path": "main.go"}))
+	d.HandleEvent(toolUseEvent("e1", "Edit", map[string]interface{}{"file_path": "denied.go"}))
+	d.HandleEvent(toolUseEvent("e2", "Edit", map[string]interface{}{"file_path": "bad.go"}))
+	d.HandleEvent(toolUseEvent("r1", "Read", map[string]interface{}{"file_path": "read.go"}))
+	d.HandleEvent(toolUseEvent("n1", "NotebookEdit", map[string]interface{}{"notebook_path": "plot.ipynb"}))
+	d.HandleEvent(toolResultEvent("w1", "File created", false))
+	d.HandleEvent(toolResultEvent("e1", "String not found", true))
+	d.HandleEvent(toolResultEvent("e2", "Updated", false))
+	d.HandleEvent(toolResultEvent("r1", "package main", false))
+	d.HandleEvent(toolResultEvent("n1", "Updated cell", false))
+
+	result := events.ResultEvent{Subtype: "success"}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"main.go", "bad.go", "plot.ipynb"}; !slices.Equal(ran, want) {
+		t.Errorf("hooks ran for %v, want %v", ran, want)
+	}
+	out := buf.String()
+	failure := strings.Index(out, "--on-edit command failed for bad.go: exit status 2: syntax error")
+	if failure < 0 || failure > strings.Index(out, "Session complete") {
+		t.Errorf("expected the failure reported before the summary, got:\n%s", out)
+	}
+}
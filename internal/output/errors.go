@@ -45,11 +45,12 @@ func DetectToolError(block *events.ContentBlock) *ErrorContext {
 	}
 
 	if block.Type == "tool_result" && block.IsError {
+		content := sanitizeToolText(block.ContentString)
 		return &ErrorContext{
 			IsError:   true,
 			ToolName:  "",
-			ToolError: block.ContentString,
-			Message:   fmt.Sprintf("Tool error: %s", truncateErrorMessage(block.ContentString, 500)),
+			ToolError: content,
+			Message:   fmt.Sprintf("Tool error: %s", truncateErrorMessage(content, 500)),
 		}
 	}
 
@@ -94,6 +95,27 @@ func DetectExitCodeError(exitCode int, stderr string) *ErrorContext {
 	return ctx
 }
 
+// DetectStartupError is DetectExitCodeError for a Claude CLI that exited
+// before producing any output, as when it rejects a flag. Such failures
+// are explained by stderr alone, so its first line becomes the message
+// (and the rest, if any, the details); with no stderr the message says so.
+func DetectStartupError(exitCode int, stderr string) *ErrorContext {
+	ctx := DetectExitCodeError(exitCode, stderr)
+	if ctx == nil {
+		return nil
+	}
+	first, rest, _ := strings.Cut(strings.TrimSpace(stderr), "\n")
+	if first == "" {
+		ctx.Message += " before producing any output (no error output)"
+		return ctx
+	}
+	ctx.Message = "Claude CLI failed before producing any output: " + truncateErrorMessage(first, 200)
+	if strings.TrimSpace(rest) == "" {
+		ctx.Stderr = ""
+	}
+	return ctx
+}
+
 // FormatError formats an error context for display.
 // Returns the formatted error string with 'ERROR:' prefix.
 func FormatError(ctx *ErrorContext) string {
@@ -191,6 +213,16 @@ func GetToolResultError(e events.StreamEvent) *ErrorContext {
 	return DetectToolError(e.Event.ContentBlock)
 }
 
+// IsOverloadedError reports whether errorContent indicates the API rejected
+// the request because the model is overloaded: the overloaded_error type or
+// an "API Error: 529". Other mentions of "overloaded" (in Claude's own
+// text, say) don't count.
+func IsOverloadedError(errorContent string) bool {
+	errorLower := strings.ToLower(errorContent)
+	return strings.Contains(errorLower, "overloaded_error") ||
+		strings.Contains(errorLower, "api error: 529")
+}
+
 // MapCommonError maps common error patterns to user-friendly messages.
 func MapCommonError(errorContent string) string {
 	errorLower := strings.ToLower(errorContent)
@@ -221,6 +253,20 @@ func MapCommonError(errorContent string) string {
 		return "Expected a directory but found a file"
 	}
 
+	// File system capacity and mount errors (common in sandboxed/CI environments)
+	if strings.Contains(errorLower, "no space left on device") ||
+		strings.Contains(errorLower, "enospc") {
+		return "Disk full - no space left on device"
+	}
+	if strings.Contains(errorLower, "disk quota exceeded") ||
+		strings.Contains(errorLower, "edquot") {
+		return "Disk quota exceeded - free up space or raise the quota"
+	}
+	if strings.Contains(errorLower, "read-only file system") ||
+		strings.Contains(errorLower, "erofs") {
+		return "Read-only file system - the target location cannot be written"
+	}
+
 	// Process errors
 	if strings.Contains(errorLower, "command not found") {
 		return "Command not found - ensure the required tool is installed and in PATH"
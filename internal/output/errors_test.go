@@ -0,0 +1,64 @@
+package output
+
+import "testing"
+
+func TestMapCommonError(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"EACCES: permission denied, open '/etc/x'", "Permission denied - check file or directory permissions"},
+		{"ENOENT: no such file or directory, open 'a.go'", "File or directory not found"},
+		{"write /tmp/out.txt: no space left on device", "Disk full - no space left on device"},
+		{"Error: ENOSPC: write failed", "Disk full - no space left on device"},
+		{"write /home/u/f: disk quota exceeded", "Disk quota exceeded - free up space or raise the quota"},
+		{"open /usr/lib/x: read-only file system", "Read-only file system - the target location cannot be written"},
+		{"Error: EROFS: cannot write", "Read-only file system - the target location cannot be written"},
+		{"something unexpected", "something unexpected"},
+	}
+
+	for _, tt := range tests {
+		if got := MapCommonError(tt.input); got != tt.want {
+			t.Errorf("MapCommonError(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIsOverloadedError(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{`API Error: 529 {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, true},
+		{`{"type":"overloaded_error"}`, true},
+		{"api error: 529", true},
+		{"The server is overloaded with requests, so I added a queue.", false},
+		{"Error: function overloaded for int and string", false},
+		{"API Error: 500 internal server error", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsOverloadedError(tt.input); got != tt.want {
+			t.Errorf("IsOverloadedError(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDetectStartupError(t *testing.T) {
+	ctx := DetectStartupError(1, "error: unknown option '--bogus'\n")
+	if ctx.Message != "Claude CLI failed before producing any output: error: unknown option '--bogus'" || ctx.Stderr != "" || ctx.ExitCode != 1 {
+		t.Errorf("unexpected context for one stderr line: %+v", ctx)
+	}
+
+	stderr := "fatal: cannot start\n  at main.js:1\n"
+	if ctx := DetectStartupError(2, stderr); ctx.Message != "Claude CLI failed before producing any output: fatal: cannot start" || ctx.Stderr != stderr {
+		t.Errorf("expected the full stderr kept as details, got %+v", ctx)
+	}
+
+	if ctx := DetectStartupError(1, " \n"); ctx.Message != errorMessages[1]+" before producing any output (no error output)" {
+		t.Errorf("unexpected message without stderr: %q", ctx.Message)
+	}
+	if DetectStartupError(0, "warning") != nil {
+		t.Error("expected no error for exit code 0")
+	}
+}
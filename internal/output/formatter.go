@@ -13,17 +13,54 @@ const (
 	colorGreen  = "\033[32m"
 	colorRed    = "\033[31m"
 	colorYellow = "\033[33m"
+	colorDim    = "\033[2m"
 )
 
 // Formatter handles colored and emoji-enhanced output.
 // When ColorEnabled is false, output is plain text without ANSI codes.
 // When EmojiEnabled is false, emoji prefixes are omitted.
+// EmojiSet overrides the glyph shown for a role (see emojiRoles).
 type Formatter struct {
 	ColorEnabled bool
 	EmojiEnabled bool
+	EmojiSet     map[string]string
 	Writer       io.Writer
 }
 
+// emojiRoles maps the built-in emoji to the emojiSet role they stand for.
+// The tool bullet isn't one of them: UseASCIIGlyphs can change it, so
+// ToolCall asks for the "tool" role itself.
+var emojiRoles = map[string]string{
+	EmojiError:   "error",
+	EmojiWarning: "warning",
+	EmojiDone:    "success",
+}
+
+// glyph returns the emoji to show in place of emoji: the EmojiSet entry for
+// its role, or for role itself when emoji is empty. Anything else is kept.
+func (f *Formatter) glyph(emoji, role string) string {
+	if r, ok := emojiRoles[emoji]; ok {
+		role = r
+	} else if emoji != "" {
+		return emoji
+	}
+	if custom, ok := f.EmojiSet[role]; ok {
+		return custom
+	}
+	return emoji
+}
+
+// withEmoji prefixes msg with the glyph for emoji when emoji are enabled.
+func (f *Formatter) withEmoji(emoji, role, msg string) string {
+	if !f.EmojiEnabled {
+		return msg
+	}
+	if g := f.glyph(emoji, role); g != "" {
+		return g + " " + msg
+	}
+	return msg
+}
+
 // NewFormatter creates a new Formatter with the specified settings.
 // If writer is nil, it defaults to os.Stderr (display output is separate from data output).
 func NewFormatter(colorEnabled, emojiEnabled bool, writer io.Writer) *Formatter {
@@ -45,6 +82,16 @@ func (f *Formatter) colorize(text, color string) string {
 	return color + text + colorReset
 }
 
+// highlight colors a single field inside a line that is itself colorized
+// with base, restoring base afterwards. Returns text unchanged if colors are
+// disabled or no highlight color is given.
+func (f *Formatter) highlight(text, color, base string) string {
+	if !f.ColorEnabled || color == "" {
+		return text
+	}
+	return color + text + colorReset + base
+}
+
 // Info outputs an informational message in blue.
 func (f *Formatter) Info(format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
@@ -85,9 +132,28 @@ func (f *Formatter) PlainNoNewline(format string, args ...interface{}) {
 	fmt.Fprint(f.Writer, msg)
 }
 
+// Label returns text styled as a speaker label (blue when colors are enabled).
+func (f *Formatter) Label(text string) string {
+	return f.colorize(text, colorBlue)
+}
+
+// Flush forces buffered output through when the writer supports it
+// (e.g. a CoalescingWriter). No-op for unbuffered writers.
+func (f *Formatter) Flush() {
+	if fl, ok := f.Writer.(interface{ Flush() error }); ok {
+		_ = fl.Flush()
+	}
+}
+
 // ToolCall outputs a tool call with only the bullet colored green and rest plain.
 // Format: "● ToolName(params)" where only ● is green.
+// An emojiSet "tool" glyph replaces the bullet when emoji are enabled.
 func (f *Formatter) ToolCall(bullet, text string) {
+	if f.EmojiEnabled {
+		if g := f.glyph("", "tool"); g != "" {
+			bullet = g
+		}
+	}
 	if f.ColorEnabled {
 		fmt.Fprintf(f.Writer, "%s%s%s %s\n", colorGreen, bullet, colorReset, text)
 	} else {
@@ -97,40 +163,28 @@ func (f *Formatter) ToolCall(bullet, text string) {
 
 // InfoWithEmoji outputs an informational message with an optional emoji prefix.
 func (f *Formatter) InfoWithEmoji(emoji, format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	if f.EmojiEnabled && emoji != "" {
-		msg = emoji + " " + msg
-	}
+	msg := f.withEmoji(emoji, "info", fmt.Sprintf(format, args...))
 	colored := f.colorize(msg, colorBlue)
 	fmt.Fprintln(f.Writer, colored)
 }
 
 // SuccessWithEmoji outputs a success message with an optional emoji prefix.
 func (f *Formatter) SuccessWithEmoji(emoji, format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	if f.EmojiEnabled && emoji != "" {
-		msg = emoji + " " + msg
-	}
+	msg := f.withEmoji(emoji, "success", fmt.Sprintf(format, args...))
 	colored := f.colorize(msg, colorGreen)
 	fmt.Fprintln(f.Writer, colored)
 }
 
 // ErrorWithEmoji outputs an error message with an optional emoji prefix.
 func (f *Formatter) ErrorWithEmoji(emoji, format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	if f.EmojiEnabled && emoji != "" {
-		msg = emoji + " " + msg
-	}
+	msg := f.withEmoji(emoji, "error", fmt.Sprintf(format, args...))
 	colored := f.colorize(msg, colorRed)
 	fmt.Fprintln(f.Writer, colored)
 }
 
 // WarningWithEmoji outputs a warning message with an optional emoji prefix.
 func (f *Formatter) WarningWithEmoji(emoji, format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	if f.EmojiEnabled && emoji != "" {
-		msg = emoji + " " + msg
-	}
+	msg := f.withEmoji(emoji, "warning", fmt.Sprintf(format, args...))
 	colored := f.colorize(msg, colorYellow)
 	fmt.Fprintln(f.Writer, colored)
 }
@@ -0,0 +1,60 @@
+//go:build !windows
+
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// restoreTerminal erases the current line, resets text attributes and shows
+// the cursor, undoing anything a half-drawn status line or colored write
+// may have left behind.
+const restoreTerminal = clearLine + colorReset + "\033[?25h"
+
+// HandleJobControl keeps the terminal attached to f sane across Ctrl+Z. On
+// SIGTSTP the display's spinner is paused and its thinking indicator
+// disarmed; then, holding w (the display's writer) so no other display
+// write can interleave, buffered output is flushed and the terminal
+// restored before the process suspends itself. Once continued (SIGCONT),
+// the spinner resumes unless the display stopped it meanwhile. Status
+// lines are redrawn with the next event. Returns a function that stops the
+// handling.
+func HandleJobControl(f *os.File, w *SyncWriter, display *Display) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTSTP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				resume := display.Spinner.Pause()
+				display.Thinking.Disarm()
+				w.locked(func(out io.Writer) {
+					if IsTTY(f) {
+						fmt.Fprint(out, restoreTerminal)
+					}
+					_ = flushWriter(out)
+
+					// Suspend for real: with the handler removed, SIGTSTP's
+					// default action stops the process until SIGCONT
+					signal.Reset(syscall.SIGTSTP)
+					_ = syscall.Kill(os.Getpid(), syscall.SIGTSTP)
+					signal.Notify(sigChan, syscall.SIGTSTP)
+				})
+				resume()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package output
+
+import "os"
+
+// HandleJobControl is a no-op on Windows, which has no job-control signals.
+func HandleJobControl(f *os.File, w *SyncWriter, display *Display) (stop func()) {
+	return func() {}
+}
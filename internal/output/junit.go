@@ -0,0 +1,163 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// JUnitCase is one session reported as a test case by --junit. A case
+// fails when its exit code is non-zero or it has a Failure.
+type JUnitCase struct {
+	Name        string              // Test case name, usually the prompt
+	ExitCode    int                 // Exit code reported for the session
+	Failure     string              // Why the session failed
+	FailureType string              // Kind of failure; "exit <code>" if empty
+	Elapsed     time.Duration       // Measured wall time of the session
+	Result      *events.ResultEvent // nil if no result event arrived
+}
+
+// junitSuites is the <testsuites> root of a JUnit XML report.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite is a <testsuite> element.
+type junitSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a <testcase> element.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitText    `xml:"system-out,omitempty"`
+}
+
+// junitText is element text written as CDATA, so line breaks stay readable.
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+// junitFailure is a <failure> element.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Detail  string `xml:",cdata"`
+}
+
+// junitSuiteName names the suite and classname of every case.
+const junitSuiteName = "claude-print"
+
+// maxJUnitNameLen caps test case names, which CI systems show in lists.
+const maxJUnitNameLen = 100
+
+// WriteJUnitReport writes cases as a JUnit XML report with a single
+// "claude-print" test suite. A failed case has Failure as its message; the
+// session ID, cost, turns and tool call counts go to its system-out. Times
+// are in seconds. started is the suite timestamp. Text is written without
+// ANSI sequences or characters XML doesn't allow, so raw stderr in a
+// failure message can't make the report malformed.
+func WriteJUnitReport(w io.Writer, cases []JUnitCase, started time.Time) error {
+	suite := junitSuite{Name: junitSuiteName, Timestamp: started.UTC().Format("2006-01-02T15:04:05")}
+	var total time.Duration
+	for _, c := range cases {
+		tc := junitTestCase{
+			Name:      junitCaseName(c.Name),
+			Classname: junitSuiteName,
+			Time:      junitSeconds(c.Elapsed),
+			SystemOut: junitSystemOut(c.Result),
+		}
+		if c.ExitCode != 0 || c.Failure != "" {
+			message := xmlText(c.Failure)
+			if message == "" {
+				message = fmt.Sprintf("exit code %d", c.ExitCode)
+			}
+			failureType := c.FailureType
+			if failureType == "" {
+				failureType = fmt.Sprintf("exit %d", c.ExitCode)
+			}
+			tc.Failure = &junitFailure{
+				Message: message,
+				Type:    failureType,
+				Detail:  message,
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+		total += c.Elapsed
+	}
+	suite.Tests = len(cases)
+	suite.Time = junitSeconds(total)
+
+	report := junitSuites{Tests: suite.Tests, Failures: suite.Failures, Time: suite.Time, Suites: []junitSuite{suite}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitCaseName returns the first line of name, capped at maxJUnitNameLen.
+func junitCaseName(name string) string {
+	name, _, _ = strings.Cut(strings.TrimSpace(xmlText(name)), "\n")
+	if name == "" {
+		return "session"
+	}
+	return truncateLine(name, maxJUnitNameLen)
+}
+
+// junitSeconds formats d as seconds with millisecond precision.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// junitSystemOut summarizes result as "key: value" lines for a case's
+// system-out.
+func junitSystemOut(result *events.ResultEvent) *junitText {
+	if result == nil {
+		return nil
+	}
+	return &junitText{Text: fmt.Sprintf("session_id: %s\nturns: %d\ncost_usd: %.4f\ntool_calls: %d (%d errors)",
+		xmlText(result.SessionID), result.NumTurns, result.TotalCostUSD, result.TotalToolUse, result.TotalToolErrors)}
+}
+
+// xmlText returns s without ANSI sequences and without the characters XML
+// 1.0 doesn't allow (control characters other than tab, newline and
+// carriage return, and invalid UTF-8), which encoding/xml writes into CDATA
+// as they are.
+func xmlText(s string) string {
+	s = StripANSI(s)
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t', r == '\n', r == '\r':
+			return r
+		case r < 0x20, r == utf8.RuneError, r >= 0xd800 && r <= 0xdfff, r == 0xfffe, r == 0xffff:
+			return -1
+		}
+		return r
+	}, s)
+}
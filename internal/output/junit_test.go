@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestWriteJUnitReport(t *testing.T) {
+	cases := []JUnitCase{
+		{
+			Name:    "Check the build\nand the tests",
+			Elapsed: 1500 * time.Millisecond,
+			Result:  &events.ResultEvent{SessionID: "s1", NumTurns: 2, TotalCostUSD: 0.25, TotalToolUse: 3, TotalToolErrors: 1},
+		},
+		{Name: "Fix <everything> & more", ExitCode: 5, Failure: "2 of 3 tool calls failed", Elapsed: 250 * time.Millisecond},
+		{Name: "", ExitCode: 1, Elapsed: time.Second},
+	}
+	var buf bytes.Buffer
+	started := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := WriteJUnitReport(&buf, cases, started); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if report.Tests != 3 || report.Failures != 2 || report.Time != "2.750" || len(report.Suites) != 1 {
+		t.Fatalf("unexpected report totals: %+v", report)
+	}
+	suite := report.Suites[0]
+	if suite.Name != "claude-print" || suite.Timestamp != "2026-01-02T03:04:05" || len(suite.Cases) != 3 {
+		t.Fatalf("unexpected suite: %+v", suite)
+	}
+
+	passed := suite.Cases[0]
+	if passed.Name != "Check the build" || passed.Time != "1.500" || passed.Failure != nil {
+		t.Errorf("unexpected passing case: %+v", passed)
+	}
+	if out := passed.SystemOut; out == nil || !strings.Contains(out.Text, "session_id: s1\n") || !strings.Contains(out.Text, "tool_calls: 3 (1 errors)") {
+		t.Errorf("unexpected system-out: %+v", out)
+	}
+
+	failed := suite.Cases[1]
+	if failed.Name != "Fix <everything> & more" || failed.Failure == nil ||
+		failed.Failure.Message != "2 of 3 tool calls failed" || failed.Failure.Type != "exit 5" {
+		t.Errorf("unexpected failing case: %+v", failed)
+	}
+	if unnamed := suite.Cases[2]; unnamed.Name != "session" || unnamed.Failure.Message != "exit code 1" {
+		t.Errorf("unexpected unnamed case: %+v", unnamed)
+	}
+}
+
+func TestWriteJUnitReport_InvalidXMLCharacters(t *testing.T) {
+	cases := []JUnitCase{{
+		Name:     "Check \x1b[1mthis\x1b[0m\x00",
+		ExitCode: 1,
+		Failure:  "\x1b[31mError:\x1b[0m bad\x07 flag\x1b\xff",
+	}}
+	var buf bytes.Buffer
+	if err := WriteJUnitReport(&buf, cases, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%q", err, buf.String())
+	}
+	c := report.Suites[0].Cases[0]
+	if c.Name != "Check this" || c.Failure == nil || c.Failure.Message != "Error: bad flag" || c.Failure.Detail != "Error: bad flag" {
+		t.Errorf("expected ANSI sequences and invalid characters removed, got %+v %+v", c, c.Failure)
+	}
+}
+
+func TestWriteJUnitReport_FailureWithoutExitCode(t *testing.T) {
+	cases := []JUnitCase{{Name: "tidy up", Failure: "1 of 4 tool calls failed", FailureType: "tool errors"}}
+	var buf bytes.Buffer
+	if err := WriteJUnitReport(&buf, cases, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	f := report.Suites[0].Cases[0].Failure
+	if report.Failures != 1 || f == nil || f.Message != "1 of 4 tool calls failed" || f.Type != "tool errors" {
+		t.Errorf("expected the case to fail on its Failure, got %+v", report)
+	}
+}
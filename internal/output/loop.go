@@ -0,0 +1,57 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// trackToolLoop counts consecutive tool calls with an identical tool name and
+// input. Once the count exceeds LoopGuard, the loop is recorded and reported
+// by ToolLoop. No-op when LoopGuard is zero.
+func (d *Display) trackToolLoop(event events.Event) {
+	if d.LoopGuard <= 0 {
+		return
+	}
+	e, ok := event.(events.AssistantEvent)
+	if !ok {
+		return
+	}
+	for _, block := range e.Message.Content {
+		if block.Type != "tool_use" {
+			continue
+		}
+		input := d.capToolParams(block.Input)
+		data, _ := json.Marshal(input) // map keys are sorted, so equal inputs match
+		signature := block.Name + " " + string(data)
+
+		if signature == d.State.LastToolSignature {
+			d.State.ToolRepeats++
+		} else {
+			d.State.LastToolSignature = signature
+			d.State.ToolRepeats = 1
+		}
+
+		if d.State.ToolRepeats > d.LoopGuard && d.State.ToolLoop == "" {
+			call := block.Name
+			if params := d.formatToolParams(block.Name, input); params != "" {
+				call += " " + params
+			}
+			d.State.ToolLoop = fmt.Sprintf("%s ×%d", call, d.State.ToolRepeats)
+		}
+	}
+}
+
+// ToolLoop reports whether a tool loop has been detected since the last
+// ResetToolLoop, with a description such as "Read main.go ×4".
+func (d *Display) ToolLoop() (string, bool) {
+	return d.State.ToolLoop, d.State.ToolLoop != ""
+}
+
+// ResetToolLoop clears loop tracking, e.g. before starting a new session.
+func (d *Display) ResetToolLoop() {
+	d.State.LastToolSignature = ""
+	d.State.ToolRepeats = 0
+	d.State.ToolLoop = ""
+}
@@ -0,0 +1,186 @@
+package output
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tableSeparatorCell matches a cell of a markdown table's delimiter row,
+// such as "---", ":--", "--:" or ":-:".
+var tableSeparatorCell = regexp.MustCompile(`^:?-+:?$`)
+
+// bufferTableText writes a streamed text delta, holding back lines that look
+// like markdown table rows (--render-tables). Held rows are drawn as one
+// table when a line that isn't a row arrives, or by finishTableText at the
+// end of the text block. Other text streams through unchanged.
+func (d *Display) bufferTableText(text string) {
+	d.State.TablePending += text
+	for d.State.TablePending != "" {
+		s := d.State.TablePending
+		i := strings.IndexByte(s, '\n')
+
+		// Text continuing a line that isn't a table row passes straight through
+		if d.State.TableMidLine {
+			if i < 0 {
+				d.emitStreamText(s)
+				d.State.TablePending = ""
+				return
+			}
+			d.emitStreamText(s[:i+1])
+			d.State.TablePending = s[i+1:]
+			d.State.TableMidLine = false
+			continue
+		}
+
+		if i < 0 {
+			// Hold a partial line until it's clear whether it is a row
+			if start := strings.TrimLeft(s, " \t"); start == "" || strings.HasPrefix(start, "|") {
+				return
+			}
+			d.flushTableRows()
+			d.State.TableMidLine = true
+			continue
+		}
+
+		line := s[:i]
+		d.State.TablePending = s[i+1:]
+		if isTableRow(line) {
+			d.State.TableRows = append(d.State.TableRows, line)
+			continue
+		}
+		d.flushTableRows()
+		d.emitStreamText(line + "\n")
+	}
+}
+
+// finishTableText draws any held table rows and writes any held partial
+// line. Call it when the text block ends.
+func (d *Display) finishTableText() {
+	pending := d.State.TablePending
+	d.State.TablePending = ""
+	d.State.TableMidLine = false
+	if pending != "" && len(d.State.TableRows) > 0 && isTableRow(pending) {
+		d.State.TableRows = append(d.State.TableRows, pending)
+		pending = ""
+	}
+	d.flushTableRows()
+	if pending != "" {
+		d.emitStreamText(pending)
+	}
+}
+
+// flushTableRows draws the held table rows with box-drawing borders, or
+// writes them as they came when they don't form a markdown table.
+func (d *Display) flushTableRows() {
+	rows := d.State.TableRows
+	if len(rows) == 0 {
+		return
+	}
+	d.State.TableRows = nil
+
+	lines, ok := renderMarkdownTable(rows)
+	if !ok {
+		d.emitStreamText(strings.Join(rows, "\n") + "\n")
+		return
+	}
+	// Start the table on its own line (e.g. right after the text bullet)
+	if d.State.TextColumn > 0 {
+		d.Formatter.PlainNoNewline("\n")
+	}
+	d.Formatter.PlainNoNewline("%s\n", strings.Join(lines, "\n"))
+	d.State.TextColumn = 0
+}
+
+// isTableRow reports whether line looks like a markdown table row.
+func isTableRow(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "|")
+}
+
+// splitTableRow returns the trimmed cells of a markdown table row.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	line = strings.TrimSuffix(line, "|")
+	cells := strings.Split(line, "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+// renderMarkdownTable lays out markdown table rows (a header, a delimiter
+// row, then data rows) as a bordered table with columns aligned as the
+// delimiter row asks. It returns false when rows aren't a valid table.
+func renderMarkdownTable(rows []string) ([]string, bool) {
+	if len(rows) < 2 {
+		return nil, false
+	}
+	header := splitTableRow(rows[0])
+	separator := splitTableRow(rows[1])
+	if len(separator) != len(header) {
+		return nil, false
+	}
+	aligns := make([]int, len(header))
+	for i, cell := range separator {
+		if !tableSeparatorCell.MatchString(cell) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			aligns[i] = alignCenter
+		case strings.HasSuffix(cell, ":"):
+			aligns[i] = alignRight
+		}
+	}
+
+	// Data rows are padded or cut to the header's column count
+	body := [][]string{header}
+	for _, row := range rows[2:] {
+		cells := splitTableRow(row)
+		fitted := make([]string, len(header))
+		copy(fitted, cells)
+		body = append(body, fitted)
+	}
+
+	widths := make([]int, len(header))
+	for _, cells := range body {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+
+	border := func(left, mid, right string) string {
+		segments := make([]string, len(widths))
+		for i, w := range widths {
+			segments[i] = strings.Repeat("─", w+2)
+		}
+		return left + strings.Join(segments, mid) + right
+	}
+	row := func(cells []string) string {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = " " + alignCell(cell, widths[i], aligns[i]) + " "
+		}
+		return "│" + strings.Join(padded, "│") + "│"
+	}
+
+	lines := []string{border("┌", "┬", "┐"), row(body[0]), border("├", "┼", "┤")}
+	for _, cells := range body[1:] {
+		lines = append(lines, row(cells))
+	}
+	return append(lines, border("└", "┴", "┘")), true
+}
+
+// alignCell pads cell to width columns with the given alignment.
+func alignCell(cell string, width, align int) string {
+	gap := width - visibleWidth(cell)
+	switch align {
+	case alignRight:
+		return strings.Repeat(" ", gap) + cell
+	case alignCenter:
+		left := gap / 2
+		return strings.Repeat(" ", left) + cell + strings.Repeat(" ", gap-left)
+	default:
+		return cell + strings.Repeat(" ", gap)
+	}
+}
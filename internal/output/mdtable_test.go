@@ -0,0 +1,100 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestRenderMarkdownTable(t *testing.T) {
+	lines, ok := renderMarkdownTable([]string{
+		"| Name | Size | Kind |",
+		"|:-----|-----:|:----:|",
+		"| main.go | 12 | go |",
+		"| README | 3 |",
+	})
+	if !ok {
+		t.Fatal("expected a valid table")
+	}
+	want := []string{
+		"┌─────────┬──────┬──────┐",
+		"│ Name    │ Size │ Kind │",
+		"├─────────┼──────┼──────┤",
+		"│ main.go │   12 │  go  │",
+		"│ README  │    3 │      │",
+		"└─────────┴──────┴──────┘",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRenderMarkdownTable_Invalid(t *testing.T) {
+	for _, rows := range [][]string{
+		{"| just one row |"},
+		{"| a | b |", "| not | a separator |"},
+		{"| a | b |", "|---|"},
+	} {
+		if _, ok := renderMarkdownTable(rows); ok {
+			t.Errorf("expected %q to be rejected", rows)
+		}
+	}
+}
+
+// streamTextEvents returns a text block whose text arrives in the given deltas.
+func streamTextEvents(deltas ...string) []events.Event {
+	start := streamEvent("content_block_start")
+	start.Event.ContentBlock = &events.ContentBlock{Type: "text"}
+	evts := []events.Event{start}
+	for _, text := range deltas {
+		delta := streamEvent("content_block_delta")
+		delta.Event.Delta = &events.Delta{Type: "text_delta", Text: text}
+		evts = append(evts, delta)
+	}
+	return append(evts, streamEvent("content_block_stop"))
+}
+
+func TestRenderTables_Streamed(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.RenderTables = true
+	for _, e := range streamTextEvents("Sizes:\n| a |", " bb |\n|---|--", "-|\n| 1 | 2 |", "\nDone") {
+		d.HandleEvent(e)
+	}
+
+	want := "\n" + Bullet + " Sizes:\n" +
+		"┌───┬────┐\n" +
+		"│ a │ bb │\n" +
+		"├───┼────┤\n" +
+		"│ 1 │ 2  │\n" +
+		"└───┴────┘\n" +
+		"Done\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestRenderTables_TableEndsBlock(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityQuiet)
+	d.RenderTables = true
+	for _, e := range streamTextEvents("| x |\n|---|\n| 1 |") {
+		d.HandleEvent(e)
+	}
+	d.HandleEvent(streamEvent("message_stop"))
+
+	if want := "┌───┐\n│ x │\n├───┤\n│ 1 │\n└───┘\n\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRenderTables_InvalidTableStaysRaw(t *testing.T) {
+	text := "Use | pipes:\n| a | b |\nthat's all"
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.RenderTables = true
+	for _, e := range streamTextEvents(text) {
+		d.HandleEvent(e)
+	}
+	if want := "\n" + Bullet + " " + text + "\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"fmt"
+	"io"
+)
+
+// LineEndWriter wraps the display writer and remembers whether output
+// currently stops mid-line, so the end of a run can be finished cleanly
+// whether or not a trailing blank line is wanted.
+type LineEndWriter struct {
+	w       io.Writer
+	midLine bool
+}
+
+// NewLineEndWriter creates a LineEndWriter writing to w.
+func NewLineEndWriter(w io.Writer) *LineEndWriter {
+	return &LineEndWriter{w: w}
+}
+
+// Write implements io.Writer.
+func (l *LineEndWriter) Write(p []byte) (int, error) {
+	n, err := l.w.Write(p)
+	if n > 0 {
+		l.midLine = p[n-1] != '\n'
+	}
+	return n, err
+}
+
+// Flush forwards to the underlying writer's Flush, if it has one, so a
+// buffering writer beneath it (see CoalescingWriter) can still be flushed
+// through the Formatter.
+func (l *LineEndWriter) Flush() error {
+	if fl, ok := l.w.(interface{ Flush() error }); ok {
+		return fl.Flush()
+	}
+	return nil
+}
+
+// Finish ends the output: a partial last line (e.g. text cut off by an
+// interrupt) is always completed, and blankLine adds the trailing blank
+// line that separates the run from the next shell prompt.
+func (l *LineEndWriter) Finish(blankLine bool) {
+	if l.midLine {
+		fmt.Fprintln(l)
+	}
+	if blankLine {
+		fmt.Fprintln(l)
+	}
+}
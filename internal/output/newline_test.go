@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// runDisplay feeds evts to a display of the given verbosity writing through
+// a LineEndWriter, finishes it, and returns everything written.
+func runDisplay(verbosity Verbosity, evts []events.Event, blankLine bool) string {
+	buf := &bytes.Buffer{}
+	lineEnd := NewLineEndWriter(buf)
+	d := NewDisplayWithWriters(lineEnd, nil, false, false, verbosity)
+	for _, e := range evts {
+		d.HandleEvent(e)
+	}
+	lineEnd.Finish(blankLine)
+	return buf.String()
+}
+
+func TestLineEndWriter_TrailingBytes(t *testing.T) {
+	result := events.ResultEvent{Subtype: "success", Result: "Done."}
+	result.Type = "result"
+	complete := append(textBlockEvents("Done."), streamEvent("message_stop"), result)
+	interrupted := textBlockEvents("Do")[:2]
+
+	tests := []struct {
+		name      string
+		verbosity Verbosity
+		evts      []events.Event
+		blankLine bool
+		wantEnd   string
+	}{
+		{"quiet complete", VerbosityQuiet, complete, false, "$0.0000\n"},
+		{"quiet interrupted", VerbosityQuiet, interrupted, false, "Do\n"},
+		{"normal complete", VerbosityNormal, complete, true, "$0.0000\n\n"},
+		{"normal complete no blank", VerbosityNormal, complete, false, "$0.0000\n"},
+		{"normal interrupted", VerbosityNormal, interrupted, true, "Do\n\n"},
+		{"normal interrupted no blank", VerbosityNormal, interrupted, false, "Do\n"},
+		{"verbose complete no blank", VerbosityVerbose, complete, false, "\n"},
+		{"verbose interrupted no blank", VerbosityVerbose, interrupted, false, "Do\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := runDisplay(tt.verbosity, tt.evts, tt.blankLine)
+			if !strings.HasSuffix(out, tt.wantEnd) {
+				t.Errorf("output should end with %q, got %q", tt.wantEnd, out)
+			}
+			if !tt.blankLine && strings.HasSuffix(out, "\n\n") {
+				t.Errorf("output should not end with a blank line, got %q", out)
+			}
+		})
+	}
+}
+
+func TestLineEndWriter_NothingWritten(t *testing.T) {
+	if out := runDisplay(VerbosityNormal, nil, false); out != "" {
+		t.Errorf("expected no output, got %q", out)
+	}
+	if out := runDisplay(VerbosityNormal, nil, true); out != "\n" {
+		t.Errorf("expected a single blank line, got %q", out)
+	}
+}
+
+func TestLineEndWriter_FlushDelegates(t *testing.T) {
+	out := &syncBuffer{}
+	c := NewCoalescingWriter(out, time.Hour)
+	defer c.Close()
+	f := NewFormatter(false, false, NewLineEndWriter(c))
+
+	f.Plain("hello")
+	if out.String() != "" {
+		t.Fatalf("expected output held by the coalescer, got %q", out.String())
+	}
+	f.Flush()
+	if out.String() != "hello\n" {
+		t.Errorf("expected Formatter.Flush to reach the coalescer, got %q", out.String())
+	}
+
+	// A writer without Flush is fine
+	if err := NewLineEndWriter(&bytes.Buffer{}).Flush(); err != nil {
+		t.Errorf("Flush() = %v", err)
+	}
+}
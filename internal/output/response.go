@@ -0,0 +1,40 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// trackResponseText records whether the session has streamed any visible
+// assistant text, and how many tools it called, for the empty-response note.
+func (d *Display) trackResponseText(event events.Event) {
+	switch e := event.(type) {
+	case events.StreamEvent:
+		if e.Event.Type == "content_block_delta" && e.Event.Delta != nil && strings.TrimSpace(e.Event.Delta.Text) != "" {
+			d.State.TextEmitted = true
+		}
+	case events.AssistantEvent:
+		for _, block := range e.Message.Content {
+			if block.Type == "tool_use" {
+				d.State.ToolCallCount++
+			}
+		}
+	}
+}
+
+// showEmptyResponseNote, when show is set, notes a session that streamed no
+// visible text, so an empty answer isn't mistaken for a display failure:
+// "(no text response — 3 tool calls)". Tracking restarts for the next session.
+func (d *Display) showEmptyResponseNote(show bool) {
+	if show && !d.State.TextEmitted {
+		calls := "1 tool call"
+		if d.State.ToolCallCount != 1 {
+			calls = fmt.Sprintf("%d tool calls", d.State.ToolCallCount)
+		}
+		d.Formatter.Warning("(no text response — %s)", calls)
+	}
+	d.State.TextEmitted = false
+	d.State.ToolCallCount = 0
+}
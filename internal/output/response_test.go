@@ -0,0 +1,71 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func successResult() events.ResultEvent {
+	e := events.ResultEvent{Subtype: "success"}
+	e.Type = "result"
+	return e
+}
+
+func TestEmptyResponseNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		evts     []events.Event
+		wantNote string
+	}{
+		{"text response", textBlockEvents("Done."), ""},
+		{"whitespace only", textBlockEvents(" \n\t"), "(no text response — 0 tool calls)"},
+		{"tool calls only", []events.Event{
+			toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a"}),
+			toolResultEvent("t1", "x", false),
+		}, "(no text response — 1 tool call)"},
+		{"nothing", nil, "(no text response — 0 tool calls)"},
+	}
+
+	for _, verbosity := range []Verbosity{VerbosityQuiet, VerbosityNormal, VerbosityVerbose} {
+		for _, tt := range tests {
+			d, buf := newBufferedDisplay(verbosity)
+			for _, e := range tt.evts {
+				d.HandleEvent(e)
+			}
+			d.HandleEvent(successResult())
+
+			out := buf.String()
+			if tt.wantNote == "" {
+				if strings.Contains(out, "no text response") {
+					t.Errorf("%s (verbosity %d): unexpected note in %q", tt.name, verbosity, out)
+				}
+				continue
+			}
+			note := strings.Index(out, tt.wantNote)
+			summary := strings.Index(out, "Session complete")
+			if note < 0 || summary < note {
+				t.Errorf("%s (verbosity %d): expected %q before the summary, got %q", tt.name, verbosity, tt.wantNote, out)
+			}
+		}
+	}
+}
+
+func TestEmptyResponseNote_ResetsPerSession(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	for _, e := range textBlockEvents("First answer") {
+		d.HandleEvent(e)
+	}
+	d.HandleEvent(successResult())
+	if strings.Contains(buf.String(), "no text response") {
+		t.Fatalf("unexpected note after a text response: %q", buf.String())
+	}
+
+	// A follow-up session (e.g. in the REPL) with no text gets the note
+	buf.Reset()
+	d.HandleEvent(successResult())
+	if !strings.Contains(buf.String(), "(no text response — 0 tool calls)") {
+		t.Errorf("expected the note for the empty follow-up, got %q", buf.String())
+	}
+}
@@ -0,0 +1,209 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// statusLevel selects the color used for a result status line.
+type statusLevel int
+
+const (
+	levelSuccess statusLevel = iota
+	levelWarning
+	levelError
+)
+
+// resultStatus describes how a result subtype is presented and the exit code
+// it implies when the Claude CLI itself exited cleanly.
+type resultStatus struct {
+	Label     string      // Leading text of the status line
+	Level     statusLevel // Color of the status line
+	ShowStats bool        // Whether turns/duration/tokens/cost follow the label
+	ExitCode  int         // Implied exit code for this outcome
+}
+
+// resultStatuses maps known ResultEvent subtypes to their presentation.
+var resultStatuses = map[string]resultStatus{
+	"success":                {Label: "Session complete", Level: levelSuccess, ShowStats: true, ExitCode: 0},
+	"error_max_turns":        {Label: "Max turns reached", Level: levelWarning, ShowStats: true, ExitCode: 1},
+	"error_during_execution": {Label: "Session ended with error during execution", Level: levelError, ShowStats: false, ExitCode: 1},
+}
+
+// lookupResultStatus returns the presentation for a result event. IsError
+// wins over a subtype that implies success: Claude reports API errors as
+// "success" results with is_error set. Unknown or missing subtypes fall back
+// on IsError too.
+func lookupResultStatus(e events.ResultEvent) resultStatus {
+	status, ok := resultStatuses[e.Subtype]
+	if e.IsError && (!ok || status.ExitCode == 0) {
+		return resultStatus{Label: "Session ended with error", Level: levelError, ExitCode: 1}
+	}
+	if ok {
+		return status
+	}
+	return resultStatuses["success"]
+}
+
+// ResultExitCode returns the exit code implied by a result event's subtype:
+// 0 for success and 1 for any error outcome (max turns, execution error).
+func ResultExitCode(e events.ResultEvent) int {
+	return lookupResultStatus(e).ExitCode
+}
+
+// ResultLabel returns the status line text for a result event, such as
+// "Max turns reached".
+func ResultLabel(e events.ResultEvent) string {
+	return lookupResultStatus(e).Label
+}
+
+// showResultStatus prints the status line for a result event. It returns
+// false when the result was an error without stats, in which case callers
+// should skip any further summary output.
+func (d *Display) showResultStatus(e events.ResultEvent) bool {
+	status := lookupResultStatus(e)
+	d.showEmptyResponseNote(status.ShowStats)
+	if !status.ShowStats {
+		d.Formatter.Error("%s", status.Label)
+		if e.Result != "" {
+			d.Formatter.Error("%s", e.Result)
+		}
+		return false
+	}
+
+	baseColor := colorGreen
+	switch status.Level {
+	case levelWarning:
+		baseColor = colorYellow
+	case levelError:
+		baseColor = colorRed
+	}
+
+	// Format duration values, highlighting slow runs
+	totalDuration := d.Formatter.highlight(formatDuration(e.DurationMS),
+		thresholdColor(float64(e.DurationMS), float64(d.WarnDurationMS)), baseColor)
+	apiDuration := formatDuration(e.DurationAPIMS)
+
+	// Format cost as currency, highlighting expensive runs
+	cost := d.Formatter.highlight(formatCost(e.TotalCostUSD),
+		thresholdColor(e.TotalCostUSD, d.WarnCostUSD), baseColor)
+
+	// Calculate total tokens from model usage
+	totalIn, totalOut := calculateTotalTokens(e)
+
+	fields := d.SummaryFields
+	if len(fields) == 0 {
+		fields = config.DefaultSummaryFields
+	}
+	values := summaryValues{result: e, totalDuration: totalDuration, apiDuration: apiDuration, cost: cost, in: totalIn, out: totalOut}
+	if d.ShowCachedTokens {
+		values.cached, _ = cacheTokens(e)
+		values.in += values.cached
+	}
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if render, ok := summaryFieldRenderers[field]; ok {
+			parts = append(parts, render(values))
+		}
+	}
+	line := status.Label + ": " + strings.Join(parts, ", ")
+	if d.SummaryTemplate != "" {
+		line = expandTemplate(d.SummaryTemplate, map[string]string{
+			"status":         status.Label,
+			"turns":          strconv.Itoa(e.NumTurns),
+			"cost":           cost,
+			"total_duration": totalDuration,
+			"api_duration":   apiDuration,
+			"in":             strconv.Itoa(values.in),
+			"out":            strconv.Itoa(totalOut),
+		})
+	}
+	switch status.Level {
+	case levelWarning:
+		d.Formatter.Warning("%s", line)
+	case levelError:
+		d.Formatter.Error("%s", line)
+	default:
+		d.Formatter.Success("%s", line)
+	}
+	return true
+}
+
+// summaryValues are the formatted values the completion line is built from.
+type summaryValues struct {
+	result        events.ResultEvent
+	totalDuration string // Highlighted when over WarnDurationMS
+	apiDuration   string
+	cost          string // Highlighted when over WarnCostUSD
+	in, out       int
+	cached        int // Cache-read share of in, when ShowCachedTokens is set
+}
+
+// summaryFieldRenderers render each field that Display.SummaryFields can
+// select, keyed by the names in config.SummaryFieldNames, which lists the
+// fields config validation accepts.
+var summaryFieldRenderers = map[string]func(v summaryValues) string{
+	"turns":          func(v summaryValues) string { return fmt.Sprintf("%d turns", v.result.NumTurns) },
+	"duration":       func(v summaryValues) string { return fmt.Sprintf("%s total (%s API)", v.totalDuration, v.apiDuration) },
+	"total_duration": func(v summaryValues) string { return v.totalDuration + " total" },
+	"api_duration":   func(v summaryValues) string { return v.apiDuration + " API" },
+	"tokens": func(v summaryValues) string {
+		if v.cached > 0 {
+			return fmt.Sprintf("%d in (%d cached) / %d out", v.in, v.cached, v.out)
+		}
+		return fmt.Sprintf("%d in / %d out", v.in, v.out)
+	},
+	"cache": func(v summaryValues) string {
+		read, created := cacheTokens(v.result)
+		return fmt.Sprintf("%d cache read / %d cache write", read, created)
+	},
+	"tools": func(v summaryValues) string {
+		if v.result.TotalToolErrors > 0 {
+			return fmt.Sprintf("%d tool calls (%d failed)", v.result.TotalToolUse, v.result.TotalToolErrors)
+		}
+		return fmt.Sprintf("%d tool calls", v.result.TotalToolUse)
+	},
+	"cost": func(v summaryValues) string { return v.cost },
+}
+
+// cacheTokens returns the session's cache read and cache creation input
+// tokens, from the aggregate usage or else summed across models.
+func cacheTokens(e events.ResultEvent) (read, created int) {
+	if e.Usage != nil && (e.Usage.CacheReadInputTokens > 0 || e.Usage.CacheCreationInputTokens > 0) {
+		return e.Usage.CacheReadInputTokens, e.Usage.CacheCreationInputTokens
+	}
+	for _, u := range e.ModelUsage {
+		read += u.CacheReadInputTokens
+		created += u.CacheCreationInputTokens
+	}
+	return read, created
+}
+
+// expandTemplate replaces each {name} in tmpl with values[name]. Unknown
+// placeholders are left as-is (config validation rejects them up front).
+func expandTemplate(tmpl string, values map[string]string) string {
+	return config.PlaceholderPattern.ReplaceAllStringFunc(tmpl, func(m string) string {
+		if v, ok := values[m[1:len(m)-1]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// thresholdColor returns the highlight color for a value measured against a
+// warning threshold: yellow once exceeded, red at twice the threshold.
+// Returns "" when the threshold is disabled (zero) or not exceeded.
+func thresholdColor(value, threshold float64) string {
+	switch {
+	case threshold <= 0 || value <= threshold:
+		return ""
+	case value >= 2*threshold:
+		return colorRed
+	default:
+		return colorYellow
+	}
+}
@@ -0,0 +1,256 @@
+package output
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/config"
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestResultSummary_Subtypes(t *testing.T) {
+	tests := []struct {
+		subtype  string
+		isError  bool
+		result   string
+		want     string
+		exitCode int
+	}{
+		{"success", false, "", "Session complete: 3 turns", 0},
+		{"error_max_turns", true, "", "Max turns reached: 3 turns", 1},
+		{"error_during_execution", true, "boom", "Session ended with error during execution", 1},
+		{"success", true, "API Error: 500 Internal Server Error", "Session ended with error", 1},
+		{"", true, "", "Session ended with error", 1},
+		{"", false, "", "Session complete: 3 turns", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subtype, func(t *testing.T) {
+			e := events.ResultEvent{Subtype: tt.subtype, IsError: tt.isError, NumTurns: 3, Result: tt.result}
+			e.Type = "result"
+
+			for _, verbosity := range []Verbosity{VerbosityQuiet, VerbosityNormal} {
+				d, buf := newBufferedDisplay(verbosity)
+				d.HandleEvent(e)
+				if !strings.Contains(buf.String(), tt.want) {
+					t.Errorf("verbosity %d: expected %q in output, got:\n%s", verbosity, tt.want, buf.String())
+				}
+				if tt.result != "" && !strings.Contains(buf.String(), tt.result) {
+					t.Errorf("verbosity %d: expected result text %q in output", verbosity, tt.result)
+				}
+			}
+
+			if got := ResultExitCode(e); got != tt.exitCode {
+				t.Errorf("ResultExitCode = %d, want %d", got, tt.exitCode)
+			}
+		})
+	}
+}
+
+func TestResultSummary_MaxTurnsIsYellow(t *testing.T) {
+	buf := &strings.Builder{}
+	d := NewDisplay(NewFormatter(true, false, buf), VerbosityNormal)
+	d.State.TextEmitted = true // keep the empty-response note out of the way
+
+	e := events.ResultEvent{Subtype: "error_max_turns", IsError: true}
+	e.Type = "result"
+	d.HandleEvent(e)
+
+	if !strings.HasPrefix(buf.String(), colorYellow+"Max turns reached") {
+		t.Errorf("expected yellow max-turns line, got %q", buf.String())
+	}
+}
+
+func TestResultSummary_ThresholdHighlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		cost    float64
+		wantRed bool
+		wantYel bool
+	}{
+		{"under threshold", 0.50, false, false},
+		{"over threshold", 1.50, false, true},
+		{"double threshold", 2.50, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &strings.Builder{}
+			d := NewDisplay(NewFormatter(true, false, buf), VerbosityNormal)
+			d.WarnCostUSD = 1.0
+
+			e := events.ResultEvent{Subtype: "success", TotalCostUSD: tt.cost}
+			e.Type = "result"
+			d.HandleEvent(e)
+
+			cost := formatCost(tt.cost)
+			if got := strings.Contains(buf.String(), colorYellow+cost); got != tt.wantYel {
+				t.Errorf("yellow cost highlight = %v, want %v: %q", got, tt.wantYel, buf.String())
+			}
+			if got := strings.Contains(buf.String(), colorRed+cost); got != tt.wantRed {
+				t.Errorf("red cost highlight = %v, want %v: %q", got, tt.wantRed, buf.String())
+			}
+		})
+	}
+}
+
+func TestResultSummary_Template(t *testing.T) {
+	e := events.ResultEvent{Subtype: "success", NumTurns: 4, DurationMS: 2500, TotalCostUSD: 0.0123}
+	e.Type = "result"
+
+	for _, verbosity := range []Verbosity{VerbosityQuiet, VerbosityNormal} {
+		d, buf := newBufferedDisplay(verbosity)
+		d.SummaryTemplate = "[{status}] turns={turns} cost={cost} time={total_duration} {unknown}"
+		d.HandleEvent(e)
+
+		want := "[Session complete] turns=4 cost=" + formatCost(0.0123) + " time=" + formatDuration(2500) + " {unknown}"
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("verbosity %d: expected %q in output, got:\n%s", verbosity, want, buf.String())
+		}
+	}
+}
+
+func TestResultSummary_Fields(t *testing.T) {
+	e := events.ResultEvent{Subtype: "success", NumTurns: 4, DurationMS: 2500, DurationAPIMS: 2000, TotalCostUSD: 0.0123,
+		Usage:        &events.AggregatedUsage{InputTokens: 1200, OutputTokens: 300, CacheReadInputTokens: 800},
+		TotalToolUse: 4, TotalToolErrors: 1}
+	e.Type = "result"
+	cost := formatCost(0.0123)
+
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{nil, "Session complete: 4 turns, 2.5s total (2.0s API), 1200 in / 300 out, " + cost + "\n"},
+		{[]string{"cost"}, "Session complete: " + cost + "\n"},
+		{[]string{"cost", "cache", "tools", "api_duration"}, "Session complete: " + cost + ", 800 cache read / 0 cache write, 4 tool calls (1 failed), 2.0s API\n"},
+	}
+	for _, tt := range tests {
+		for _, verbosity := range []Verbosity{VerbosityQuiet, VerbosityNormal} {
+			d, buf := newBufferedDisplay(verbosity)
+			d.SummaryFields = tt.fields
+			d.HandleEvent(e)
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("fields %v, verbosity %d: expected %q, got:\n%s", tt.fields, verbosity, tt.want, buf.String())
+			}
+		}
+	}
+}
+
+func TestSummaryFieldRenderers_MatchConfig(t *testing.T) {
+	names := make([]string, 0, len(summaryFieldRenderers))
+	for name := range summaryFieldRenderers {
+		names = append(names, name)
+	}
+	want := slices.Clone(config.SummaryFieldNames)
+	slices.Sort(names)
+	slices.Sort(want)
+	if !slices.Equal(names, want) {
+		t.Errorf("renderers for %v, want one for each of config.SummaryFieldNames %v", names, want)
+	}
+}
+
+func TestResultSummary_CachedTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		event events.ResultEvent
+		want  string
+	}{
+		{
+			name:  "aggregate usage",
+			event: events.ResultEvent{Usage: &events.AggregatedUsage{InputTokens: 300, OutputTokens: 50, CacheReadInputTokens: 900}},
+			want:  "1200 in (900 cached) / 50 out",
+		},
+		{
+			name: "summed across models",
+			event: events.ResultEvent{ModelUsage: map[string]*events.ModelUsage{
+				"claude-sonnet": {InputTokens: 100, OutputTokens: 20, CacheReadInputTokens: 4000},
+				"claude-haiku":  {InputTokens: 50, OutputTokens: 5, CacheReadInputTokens: 1000},
+			}},
+			want: "5150 in (5000 cached) / 25 out",
+		},
+		{
+			name:  "no cache reads",
+			event: events.ResultEvent{Usage: &events.AggregatedUsage{InputTokens: 300, OutputTokens: 50}},
+			want:  "300 in / 50 out",
+		},
+	}
+	for _, tt := range tests {
+		e := tt.event
+		e.Type, e.Subtype = "result", "success"
+		d, buf := newBufferedDisplay(VerbosityNormal)
+		d.ShowCachedTokens = true
+		d.HandleEvent(e)
+		if !strings.Contains(buf.String(), tt.want) {
+			t.Errorf("%s: expected %q, got:\n%s", tt.name, tt.want, buf.String())
+		}
+	}
+
+	// Off by default: the input figure excludes cache reads
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	e := tests[0].event
+	e.Type, e.Subtype = "result", "success"
+	d.HandleEvent(e)
+	if !strings.Contains(buf.String(), "300 in / 50 out") {
+		t.Errorf("expected the compact token figure by default, got:\n%s", buf.String())
+	}
+
+	// A summary template's {in} follows the setting too
+	for _, show := range []bool{false, true} {
+		d, buf := newBufferedDisplay(VerbosityNormal)
+		d.SummaryTemplate = "in={in} out={out}"
+		d.ShowCachedTokens = show
+		d.HandleEvent(e)
+		want := "in=300 out=50"
+		if show {
+			want = "in=1200 out=50"
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("showCachedTokens %v: expected %q, got:\n%s", show, want, buf.String())
+		}
+	}
+}
+
+func TestCalculateTotalTokens_PrefersAggregateUsage(t *testing.T) {
+	models := map[string]*events.ModelUsage{
+		"claude-sonnet": {InputTokens: 100, OutputTokens: 20},
+		"claude-haiku":  {InputTokens: 50, OutputTokens: 5},
+	}
+	tests := []struct {
+		name            string
+		usage           *events.AggregatedUsage
+		wantIn, wantOut int
+	}{
+		{"aggregate present", &events.AggregatedUsage{InputTokens: 400, OutputTokens: 30}, 400, 30},
+		{"no aggregate", nil, 150, 25},
+		{"empty aggregate", &events.AggregatedUsage{}, 150, 25},
+	}
+	for _, tt := range tests {
+		in, out := calculateTotalTokens(events.ResultEvent{Usage: tt.usage, ModelUsage: models})
+		if in != tt.wantIn || out != tt.wantOut {
+			t.Errorf("%s: got %d in / %d out, want %d / %d", tt.name, in, out, tt.wantIn, tt.wantOut)
+		}
+	}
+}
+
+func TestResultSummary_OneTokenTotal(t *testing.T) {
+	e := events.ResultEvent{
+		Subtype:    "success",
+		Usage:      &events.AggregatedUsage{InputTokens: 400, OutputTokens: 30},
+		ModelUsage: map[string]*events.ModelUsage{"claude-sonnet": {InputTokens: 100, OutputTokens: 20}},
+	}
+	e.Type = "result"
+
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.HandleEvent(e)
+	out := buf.String()
+
+	// The summary line and the verbose statistics agree on the totals
+	for _, want := range []string{"400 in / 30 out", "    Input: 400", "    Output: 30",
+		"(models sum to 100 in / 20 out; totals use the aggregate usage)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
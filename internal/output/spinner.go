@@ -0,0 +1,120 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the characters cycled by Spinner.
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// Spinner animates a single-character progress indicator on its writer
+// (normally stderr) until stopped. Stop clears the indicator so later output
+// starts on a clean line; Start may be called again to resume. A nil
+// *Spinner is valid and does nothing.
+type Spinner struct {
+	mu       sync.Mutex
+	w        io.Writer
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+	gen      int // Counts Start and Stop calls, so a Pause can tell if either came since
+}
+
+// NewSpinner creates a stopped Spinner that draws a frame every interval.
+func NewSpinner(w io.Writer, interval time.Duration) *Spinner {
+	return &Spinner{w: w, interval: interval}
+}
+
+// Start begins animating. It is a no-op if the spinner is already running.
+func (s *Spinner) Start() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.start()
+}
+
+// start begins animating; s.mu must be held and the spinner stopped.
+func (s *Spinner) start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	s.gen++
+	go s.loop(s.stop, s.done)
+}
+
+// loop draws frames until stop is closed, then erases the indicator.
+func (s *Spinner) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		fmt.Fprintf(s.w, "\r%s", spinnerFrames[i%len(spinnerFrames)])
+		select {
+		case <-ticker.C:
+		case <-stop:
+			fmt.Fprint(s.w, "\r \r")
+			return
+		}
+	}
+}
+
+// Running reports whether the spinner is animating.
+func (s *Spinner) Running() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stop != nil
+}
+
+// Stop halts the animation and clears the indicator, returning once it has
+// been erased. It is a no-op if the spinner is not running.
+func (s *Spinner) Stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halt()
+	s.gen++
+}
+
+// halt stops the animation if it is running; s.mu must be held.
+func (s *Spinner) halt() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop, s.done = nil, nil
+}
+
+// Pause stops the spinner and returns a function that restarts it, if it
+// was running and nothing has started or stopped it since.
+func (s *Spinner) Pause() (resume func()) {
+	if s == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return func() {}
+	}
+	s.halt()
+	paused := s.gen
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.gen == paused {
+			s.start()
+		}
+	}
+}
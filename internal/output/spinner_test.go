@@ -0,0 +1,75 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpinner_StopClears(t *testing.T) {
+	out := &syncBuffer{}
+	s := NewSpinner(out, time.Millisecond)
+
+	s.Start()
+	s.Start() // already running: no second goroutine
+	if !s.Running() {
+		t.Error("expected Running after Start")
+	}
+	time.Sleep(10 * time.Millisecond)
+	s.Stop()
+	s.Stop() // already stopped: no-op
+	if s.Running() {
+		t.Error("expected not Running after Stop")
+	}
+
+	got := out.String()
+	if !strings.HasPrefix(got, "\r|") {
+		t.Errorf("expected spinner frames, got %q", got)
+	}
+	if !strings.HasSuffix(got, "\r \r") {
+		t.Errorf("expected indicator to be cleared on stop, got %q", got)
+	}
+
+	// Stopped spinner writes nothing further
+	n := len(out.String())
+	time.Sleep(5 * time.Millisecond)
+	if len(out.String()) != n {
+		t.Error("spinner kept writing after Stop")
+	}
+}
+
+func TestSpinner_Nil(t *testing.T) {
+	var s *Spinner
+	s.Start()
+	s.Stop()
+	if s.Running() {
+		t.Error("nil spinner should never be running")
+	}
+}
+
+func TestSpinner_Pause(t *testing.T) {
+	s := NewSpinner(&syncBuffer{}, time.Millisecond)
+	s.Start()
+	resume := s.Pause()
+	if s.Running() {
+		t.Error("expected not Running while paused")
+	}
+	resume()
+	if !s.Running() {
+		t.Error("expected Running once resumed")
+	}
+
+	// A Stop while paused wins over the resume
+	resume = s.Pause()
+	s.Stop()
+	resume()
+	if s.Running() {
+		t.Error("expected a spinner stopped while paused to stay stopped")
+	}
+
+	// Pausing a stopped spinner resumes nothing
+	s.Pause()()
+	if s.Running() {
+		t.Error("expected a stopped spinner to stay stopped")
+	}
+}
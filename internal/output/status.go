@@ -0,0 +1,60 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// clearLine returns the cursor to the start of the line and erases it.
+const clearLine = "\r\033[K"
+
+// ClearToolStatus erases the tool status line and the thinking placeholder
+// if either is showing, so the next output starts on a clean line. Call it
+// when a run ends without a result (e.g. interrupted) before writing
+// anything else.
+func (d *Display) ClearToolStatus() {
+	d.Thinking.Disarm()
+	if !d.State.ToolStatusShown {
+		return
+	}
+	fmt.Fprint(d.Writer(), clearLine)
+	d.State.ToolStatusShown = false
+}
+
+// drawToolStatus shows "⟳ N tools running" on the last line while tool calls
+// are outstanding. The line has no newline, so ClearToolStatus can erase it
+// before the next event's output. Not drawn in quiet mode, mid-text, or
+// after the result.
+func (d *Display) drawToolStatus(event events.Event) {
+	if !d.ShowToolStatus || d.Verbosity == VerbosityQuiet || d.State.InTextBlock {
+		return
+	}
+	if _, ok := event.(events.ResultEvent); ok {
+		return
+	}
+	n := len(d.State.PendingTools)
+	if n == 0 {
+		return
+	}
+	noun := "tools"
+	if n == 1 {
+		noun = "tool"
+	}
+	fmt.Fprint(d.Writer(), d.Formatter.colorize(fmt.Sprintf("%s %d %s running", StatusGlyph, n, noun), colorBlue))
+	d.State.ToolStatusShown = true
+	d.flush()
+}
+
+// armThinking starts the idle "thinking..." placeholder after an event that
+// leaves nothing else on the last line: normal mode only, not mid-text, not
+// while the tool status line is showing, and not after the result.
+func (d *Display) armThinking(event events.Event) {
+	if d.Thinking == nil || d.Verbosity != VerbosityNormal || d.State.InTextBlock || d.State.ToolStatusShown {
+		return
+	}
+	if _, ok := event.(events.ResultEvent); ok {
+		return
+	}
+	d.Thinking.Arm()
+}
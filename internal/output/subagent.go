@@ -0,0 +1,121 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// maxSubagentIndent caps how far nested sub-agent calls are indented, so
+// deep nesting stays readable on narrow terminals.
+const maxSubagentIndent = 6
+
+// hasSubagentCalls reports whether blocks (a tool_result's array content)
+// include tool calls or results made by a sub-agent.
+func hasSubagentCalls(blocks []events.ContentBlock) bool {
+	for _, block := range blocks {
+		if block.Type == "tool_use" || block.Type == "tool_result" {
+			return true
+		}
+	}
+	return false
+}
+
+// showSubagentBlocks renders a sub-agent's tool calls and results from a
+// Task tool_result, indented one level per depth beneath the Task line.
+// Results that carry their own nested blocks are rendered recursively.
+// Text blocks are skipped; the agent's answer is shown as the result content.
+func (d *Display) showSubagentBlocks(blocks []events.ContentBlock, depth int) {
+	for _, block := range blocks {
+		switch block.Type {
+		case "tool_use":
+			d.showSubagentCall(block, depth)
+		case "tool_result":
+			d.showSubagentResult(block, depth)
+		}
+	}
+}
+
+// showSubagentCall renders a sub-agent's tool call at the given depth.
+func (d *Display) showSubagentCall(block events.ContentBlock, depth int) {
+	indent := d.indent(2 * min(depth, maxSubagentIndent))
+	call := block.Name
+	if params := d.formatToolParams(block.Name, d.capToolParams(block.Input)); params != "" {
+		call += "(" + params + ")"
+	}
+	d.Formatter.Plain("%s%s %s", indent, Bullet, truncateLine(call, d.lineLimit(100, len(indent)+2)))
+}
+
+// showSubagentResult renders the first line of a sub-agent's tool result at
+// the given depth, followed by any calls nested inside it.
+func (d *Display) showSubagentResult(block events.ContentBlock, depth int) {
+	indent := d.indent(2 * min(depth, maxSubagentIndent))
+	summary := strings.TrimSpace(sanitizeToolText(block.ContentString))
+	if line, _, found := strings.Cut(summary, "\n"); found {
+		summary = line + " " + Ellipsis
+	}
+	summary = truncateLine(summary, d.lineLimit(100, len(indent)+len(TreeBranch)))
+	switch {
+	case block.IsError:
+		d.Formatter.Error("%s%s%s", indent, TreeBranch, summary)
+	case summary != "":
+		d.Formatter.Plain("%s%s%s", indent, TreeBranch, summary)
+	}
+	if hasSubagentCalls(block.ContentBlocks) {
+		d.showSubagentBlocks(block.ContentBlocks, depth+1)
+	}
+}
+
+// handleVerboseSubagentEvent renders an event a sub-agent produced (one with
+// a ParentToolUseID) beneath the Task call that started the agent, rather
+// than as part of the main conversation. The agent's streamed text is
+// skipped; its answer is shown as the Task's result. Reports whether event
+// came from a sub-agent.
+func (d *Display) handleVerboseSubagentEvent(event events.Event) bool {
+	switch e := event.(type) {
+	case events.StreamEvent:
+		return e.ParentToolUseID != ""
+	case events.AssistantEvent:
+		if e.ParentToolUseID == "" {
+			return false
+		}
+		if d.State.SubagentParents == nil {
+			d.State.SubagentParents = make(map[string]string)
+		}
+		depth := d.subagentDepth(e.ParentToolUseID)
+		for _, block := range e.Message.Content {
+			if block.Type == "tool_use" {
+				d.State.SubagentParents[block.ID] = e.ParentToolUseID
+				d.showSubagentCall(block, depth)
+			}
+		}
+		return true
+	case events.UserEvent:
+		if e.ParentToolUseID == "" {
+			return false
+		}
+		depth := d.subagentDepth(e.ParentToolUseID)
+		for _, block := range e.Message.Content {
+			if block.Type == "tool_result" {
+				d.showSubagentResult(block, depth)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// subagentDepth returns the nesting depth of calls made by the sub-agent
+// that the Task call parentID started: 1 under a top-level Task, plus one
+// for each sub-agent that Task was itself called from.
+func (d *Display) subagentDepth(parentID string) int {
+	depth := 1
+	for id := parentID; depth < maxSubagentIndent; depth++ {
+		parent, ok := d.State.SubagentParents[id]
+		if !ok {
+			break
+		}
+		id = parent
+	}
+	return depth
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"io"
+	"sync"
+)
+
+// SyncWriter serializes writes and flushes to an underlying writer, so the
+// event loop, the thinking indicator and the job-control handler can share
+// the display's writer chain (whose writers keep unlocked state, such as
+// LineEndWriter's position and the ANSI parser's) without interleaving.
+type SyncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewSyncWriter creates a SyncWriter writing to w.
+func NewSyncWriter(w io.Writer) *SyncWriter {
+	return &SyncWriter{w: w}
+}
+
+// Write implements io.Writer.
+func (s *SyncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Flush forwards to the underlying writer's Flush, if it has one.
+func (s *SyncWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return flushWriter(s.w)
+}
+
+// locked calls fn with the underlying writer, holding off every other
+// write until fn returns.
+func (s *SyncWriter) locked(fn func(w io.Writer)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.w)
+}
+
+// flushWriter calls w's Flush, if it has one.
+func flushWriter(w io.Writer) error {
+	if fl, ok := w.(interface{ Flush() error }); ok {
+		return fl.Flush()
+	}
+	return nil
+}
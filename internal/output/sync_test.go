@@ -0,0 +1,52 @@
+package output
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncWriter_ConcurrentWrites(t *testing.T) {
+	out := &syncBuffer{}
+	c := NewCoalescingWriter(out, time.Hour)
+	defer c.Close()
+	w := NewSyncWriter(NewLineEndWriter(c))
+	f := NewFormatter(false, false, w)
+
+	// Writers sharing the chain's unlocked state (run with -race)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				f.Plain("line")
+			}
+		}()
+	}
+	wg.Wait()
+	f.Flush()
+	if got := strings.Count(out.String(), "line\n"); got != 200 {
+		t.Errorf("expected 200 whole lines flushed through, got %d", got)
+	}
+}
+
+func TestSyncWriter_LockedHoldsWrites(t *testing.T) {
+	out := &syncBuffer{}
+	w := NewSyncWriter(out)
+	written := make(chan struct{})
+	w.locked(func(held io.Writer) {
+		go func() {
+			w.Write([]byte("later"))
+			close(written)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		held.Write([]byte("first "))
+	})
+	<-written
+	if out.String() != "first later" {
+		t.Errorf("expected writes held until released, got %q", out.String())
+	}
+}
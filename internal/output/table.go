@@ -0,0 +1,55 @@
+package output
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Column alignments for formatTable.
+const (
+	alignLeft = iota
+	alignRight
+	alignCenter
+)
+
+// visibleWidth returns the number of runes in s that occupy a terminal
+// column, ignoring ANSI escape sequences.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(StripANSI(s))
+}
+
+// formatTable lays out rows as space-separated columns, each padded to the
+// widest cell in that column (measured by visibleWidth) and aligned per
+// aligns; columns beyond len(aligns) are left-aligned. Trailing padding is
+// trimmed. With a single row, each line is just its cells joined by spaces.
+func formatTable(rows [][]string, aligns []int) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if w := visibleWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var b strings.Builder
+		for i, cell := range row {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			pad := strings.Repeat(" ", widths[i]-visibleWidth(cell))
+			if i < len(aligns) && aligns[i] == alignRight {
+				b.WriteString(pad + cell)
+			} else {
+				b.WriteString(cell + pad)
+			}
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return lines
+}
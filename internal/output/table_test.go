@@ -0,0 +1,57 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestFormatTable_IgnoresANSIWidth(t *testing.T) {
+	rows := [][]string{
+		{"\x1b[32mok\x1b[0m", "5"},
+		{"failed", "120"},
+	}
+	got := formatTable(rows, []int{alignLeft, alignRight})
+	want := []string{
+		"\x1b[32mok\x1b[0m       5",
+		"failed 120",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("formatTable = %q, want %q", got, want)
+	}
+}
+
+func TestModelUsageSummary_Aligned(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+
+	result := events.ResultEvent{TotalCostUSD: 1.00, ModelUsage: map[string]*events.ModelUsage{
+		"claude-sonnet-4":  {InputTokens: 12345, OutputTokens: 678, CostUSD: 0.90},
+		"claude-haiku-3-5": {InputTokens: 90, OutputTokens: 12, CostUSD: 0.10},
+	}}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	for _, want := range []string{
+		"  - claude-haiku-3-5:    90 in /  12 out (10%) $0.10",
+		"  - claude-sonnet-4:  12345 in / 678 out (90%) $0.90",
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("expected line %q, got:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestModelUsageSummary_SingleModelUnpadded(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+
+	result := events.ResultEvent{TotalCostUSD: 0.42, ModelUsage: map[string]*events.ModelUsage{
+		"claude-sonnet-4": {InputTokens: 12345, OutputTokens: 678, CostUSD: 0.42},
+	}}
+	result.Type = "result"
+	d.HandleEvent(result)
+
+	if want := "  - claude-sonnet-4: 12345 in / 678 out (100%) $0.42\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("expected %q, got:\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,35 @@
+package output
+
+import "io"
+
+// TeeWriter writes display output to an underlying writer and a copy of it
+// to a second writer, such as a transcript file. Unlike io.MultiWriter it
+// forwards Flush to the underlying writer, so a buffering writer beneath it
+// (see CoalescingWriter) can still be flushed through the Formatter.
+// Failures writing the copy are ignored so they never interrupt the display.
+type TeeWriter struct {
+	w    io.Writer
+	copy io.Writer
+}
+
+// NewTeeWriter creates a TeeWriter writing to w and copying to copy.
+func NewTeeWriter(w, copy io.Writer) *TeeWriter {
+	return &TeeWriter{w: w, copy: copy}
+}
+
+// Write writes p to the underlying writer, then copies what was written.
+func (t *TeeWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	if n > 0 {
+		_, _ = t.copy.Write(p[:n])
+	}
+	return n, err
+}
+
+// Flush forwards to the underlying writer's Flush, if it has one.
+func (t *TeeWriter) Flush() error {
+	if fl, ok := t.w.(interface{ Flush() error }); ok {
+		return fl.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestTeeWriter(t *testing.T) {
+	out := &syncBuffer{}
+	c := NewCoalescingWriter(out, time.Hour)
+	defer c.Close()
+	var copied bytes.Buffer
+	f := NewFormatter(false, false, NewTeeWriter(c, &copied))
+
+	f.Plain("hello")
+	if copied.String() != "hello\n" || out.String() != "" {
+		t.Fatalf("expected the copy written at once and the output held, got %q and %q", copied.String(), out.String())
+	}
+	f.Flush()
+	if out.String() != "hello\n" {
+		t.Errorf("expected Formatter.Flush to reach the coalescer, got %q", out.String())
+	}
+
+	var buf bytes.Buffer
+	if n, err := NewTeeWriter(&buf, failWriter{}).Write([]byte("ok")); n != 2 || err != nil || buf.String() != "ok" {
+		t.Errorf("expected a failing copy to be ignored, got %d, %v, %q", n, err, buf.String())
+	}
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ThinkingLabel is the text shown after the spinner frame while Claude is
+// silently reasoning.
+const ThinkingLabel = "thinking..."
+
+// DefaultThinkingDelay is how long the event stream must be idle before the
+// thinking placeholder appears.
+const DefaultThinkingDelay = time.Second
+
+// thinkingFrameInterval is how often the placeholder's spinner frame advances.
+const thinkingFrameInterval = 150 * time.Millisecond
+
+// ThinkingIndicator shows a dim, animated "thinking..." placeholder on the
+// display's last line once the event stream has been idle for a delay, so a
+// long pause while Claude reasons doesn't look like a hang. The display arms
+// it after each event that leaves the cursor on a clean line and disarms it
+// before writing anything, so the two never write at the same time. A nil
+// *ThinkingIndicator is valid and does nothing.
+type ThinkingIndicator struct {
+	mu    sync.Mutex
+	f     *Formatter
+	delay time.Duration
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewThinkingIndicator creates a disarmed indicator that draws through f once
+// delay passes without Disarm being called.
+func NewThinkingIndicator(f *Formatter, delay time.Duration) *ThinkingIndicator {
+	return &ThinkingIndicator{f: f, delay: delay}
+}
+
+// Arm starts the idle timer. It is a no-op if the indicator is already armed.
+func (t *ThinkingIndicator) Arm() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		return
+	}
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go t.loop(t.stop, t.done)
+}
+
+// loop waits out the delay, then draws frames until stop is closed and
+// erases the placeholder. Nothing is written if stopped during the delay.
+func (t *ThinkingIndicator) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	select {
+	case <-time.After(t.delay):
+	case <-stop:
+		return
+	}
+	ticker := time.NewTicker(thinkingFrameInterval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		frame := spinnerFrames[i%len(spinnerFrames)]
+		fmt.Fprint(t.f.Writer, clearLine+t.f.colorize(frame+" "+ThinkingLabel, colorDim))
+		t.f.Flush()
+		select {
+		case <-ticker.C:
+		case <-stop:
+			fmt.Fprint(t.f.Writer, clearLine)
+			t.f.Flush()
+			return
+		}
+	}
+}
+
+// Disarm cancels the idle timer and erases the placeholder if it is showing,
+// returning once it has been erased. It is a no-op if the indicator is not
+// armed.
+func (t *ThinkingIndicator) Disarm() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop == nil {
+		return
+	}
+	close(t.stop)
+	<-t.done
+	t.stop, t.done = nil, nil
+}
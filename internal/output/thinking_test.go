@@ -0,0 +1,55 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+func TestThinking_ShownWhileIdleAndClearedByNextEvent(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.Thinking = NewThinkingIndicator(d.Formatter, time.Millisecond)
+
+	d.HandleEvent(toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolResultEvent("t1", "package a", false))
+	time.Sleep(20 * time.Millisecond)
+	for _, e := range textBlockEvents("Done") {
+		d.HandleEvent(e)
+	}
+
+	out := buf.String()
+	start := strings.Index(out, ThinkingLabel)
+	if start < 0 {
+		t.Fatalf("expected thinking placeholder while idle, got %q", out)
+	}
+	if !strings.Contains(out[start:], clearLine+"\n● Done") {
+		t.Errorf("expected placeholder erased before the next output, got %q", out)
+	}
+	if strings.Contains(out[strings.LastIndex(out, "Done"):], ThinkingLabel) {
+		t.Errorf("expected no placeholder mid-text, got %q", out)
+	}
+}
+
+func TestThinking_NotShownInQuietOrAfterResult(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityQuiet)
+	d.Thinking = NewThinkingIndicator(d.Formatter, time.Millisecond)
+	d.HandleEvent(toolResultEvent("t1", "package a", false))
+	time.Sleep(10 * time.Millisecond)
+	d.Thinking.Disarm()
+	if strings.Contains(buf.String(), ThinkingLabel) {
+		t.Errorf("expected no placeholder in quiet mode, got %q", buf.String())
+	}
+
+	d, buf = newBufferedDisplay(VerbosityNormal)
+	d.Thinking = NewThinkingIndicator(d.Formatter, time.Millisecond)
+	result := events.ResultEvent{Subtype: "success"}
+	result.Type = "result"
+	d.HandleEvent(result)
+	time.Sleep(10 * time.Millisecond)
+	d.Thinking.Disarm()
+	if strings.Contains(buf.String(), ThinkingLabel) {
+		t.Errorf("expected no placeholder after the result, got %q", buf.String())
+	}
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Todo statuses used by the TodoWrite tool.
+const (
+	todoPending    = "pending"
+	todoInProgress = "in_progress"
+	todoCompleted  = "completed"
+)
+
+// todoItem is one entry of a TodoWrite call's "todos" list.
+type todoItem struct {
+	Content string
+	Status  string
+}
+
+// todoWriteList returns the todo list of a TodoWrite call, or false if the
+// call is another tool or has no list.
+func todoWriteList(toolName string, input map[string]interface{}) ([]todoItem, bool) {
+	if !strings.EqualFold(toolName, "TodoWrite") {
+		return nil, false
+	}
+	return parseTodos(input)
+}
+
+// parseTodos extracts the "todos" list from a TodoWrite input. Returns
+// false if input has no such list.
+func parseTodos(input map[string]interface{}) ([]todoItem, bool) {
+	list, ok := input["todos"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	todos := make([]todoItem, 0, len(list))
+	for _, entry := range list {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		content, _ := fields["content"].(string)
+		status, _ := fields["status"].(string)
+		todos = append(todos, todoItem{Content: content, Status: status})
+	}
+	return todos, true
+}
+
+// todoSummary counts completed todos for the tool call header: "2/5 done".
+func todoSummary(todos []todoItem) string {
+	done := 0
+	for _, todo := range todos {
+		if todo.Status == todoCompleted {
+			done++
+		}
+	}
+	return fmt.Sprintf("%d/%d done", done, len(todos))
+}
+
+// showTodoList renders a TodoWrite list as a checklist beneath its tool
+// call: completed items in green, the item in progress in yellow, pending
+// items plain.
+func (d *Display) showTodoList(todos []todoItem) {
+	indent := d.indent(1)
+	for _, todo := range todos {
+		content := truncateLine(strings.TrimSpace(todo.Content), d.lineLimit(100, len(indent)+4))
+		switch todo.Status {
+		case todoCompleted:
+			d.Formatter.Success("%s%s %s", indent, TodoCompleted, content)
+		case todoInProgress:
+			d.Formatter.Warning("%s%s %s", indent, TodoInProgress, content)
+		default: // todoPending, or a status this version doesn't know
+			d.Formatter.Plain("%s%s %s", indent, TodoPending, content)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+// todoWriteInput returns a TodoWrite input as decoded from JSON.
+func todoWriteInput() map[string]interface{} {
+	todo := func(content, status string) interface{} {
+		return map[string]interface{}{"content": content, "status": status, "activeForm": content + "ing"}
+	}
+	return map[string]interface{}{"todos": []interface{}{
+		todo("Read the failing test", "completed"),
+		todo("Fix the parser", "in_progress"),
+		todo("Run the suite", "pending"),
+	}}
+}
+
+func TestTodoWrite_Checklist(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(toolUseEvent("t1", "TodoWrite", todoWriteInput()))
+
+	want := Bullet + " TodoWrite(1/3 done)\n" +
+		"  " + TodoCompleted + " Read the failing test\n" +
+		"  " + TodoInProgress + " Fix the parser\n" +
+		"  " + TodoPending + " Run the suite\n"
+	if buf.String() != want {
+		t.Errorf("display output mismatch\nwant: %q\ngot:  %q", want, buf.String())
+	}
+}
+
+func TestTodoWrite_VerboseSkipsParameters(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityVerbose)
+	d.HandleEvent(toolUseEvent("t1", "TodoWrite", todoWriteInput()))
+
+	out := buf.String()
+	if strings.Contains(out, "Parameters:") || !strings.Contains(out, TodoInProgress+" Fix the parser") {
+		t.Errorf("expected the checklist instead of raw parameters, got:\n%s", out)
+	}
+}
+
+func TestTodoWrite_OtherToolsUnaffected(t *testing.T) {
+	d, buf := newBufferedDisplay(VerbosityNormal)
+	d.HandleEvent(toolUseEvent("t1", "Custom", todoWriteInput()))
+
+	if strings.Contains(buf.String(), TodoPending) {
+		t.Errorf("expected no checklist for another tool, got %q", buf.String())
+	}
+}
@@ -0,0 +1,83 @@
+package output
+
+import (
+	"bytes"
+	"io"
+)
+
+// TranscriptWriter writes display output as plain text, the way it reads
+// once the run is over. ANSI sequences are removed, and a line redrawn
+// after a carriage return replaces what was on it, so transient lines such
+// as the tool status line and the thinking placeholder (which are erased
+// with clearLine) leave nothing behind. Lines are written once complete;
+// Close writes an unfinished last line.
+type TranscriptWriter struct {
+	strip *ANSIStripWriter
+	lines *transcriptLines
+	cr    bool // A carriage return was seen; the line is replaced unless a newline follows
+}
+
+// transcriptLines collects text without ANSI sequences into lines.
+type transcriptLines struct {
+	w    io.Writer
+	line []byte // Current line, not yet written
+}
+
+// NewTranscriptWriter creates a TranscriptWriter writing to w.
+func NewTranscriptWriter(w io.Writer) *TranscriptWriter {
+	lines := &transcriptLines{w: w}
+	return &TranscriptWriter{strip: NewANSIStripWriter(lines), lines: lines}
+}
+
+// Write implements io.Writer. A carriage return directly followed by a
+// newline is a CRLF line break; any other one starts the line over.
+func (t *TranscriptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if t.cr {
+			t.cr = false
+			if p[0] != '\n' {
+				t.lines.line = t.lines.line[:0]
+			}
+		}
+		i := bytes.IndexByte(p, '\r')
+		if i < 0 {
+			i = len(p)
+		} else {
+			t.cr = true
+		}
+		if _, err := t.strip.Write(p[:i]); err != nil {
+			return 0, err
+		}
+		p = p[min(i+1, len(p)):]
+	}
+	return n, nil
+}
+
+// Close writes the unfinished last line, unless it was erased.
+func (t *TranscriptWriter) Close() error {
+	l := t.lines
+	if t.cr || len(l.line) == 0 {
+		return nil
+	}
+	_, err := l.w.Write(l.line)
+	l.line = nil
+	return err
+}
+
+// Write implements io.Writer.
+func (l *transcriptLines) Write(p []byte) (int, error) {
+	for _, b := range p {
+		switch b {
+		case '\n':
+			l.line = append(l.line, '\n')
+			if _, err := l.w.Write(l.line); err != nil {
+				return 0, err
+			}
+			l.line = l.line[:0]
+		default:
+			l.line = append(l.line, b)
+		}
+	}
+	return len(p), nil
+}
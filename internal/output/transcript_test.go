@@ -0,0 +1,65 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTranscriptWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"plain lines", []string{"one\n", "two\n"}, "one\ntwo\n"},
+		{"colors removed", []string{colorGreen + "ok" + colorReset + "\n"}, "ok\n"},
+		{"erased status line", []string{"done\n", clearLine + "⟳ Bash", clearLine + "⟳ Bash 1s", clearLine, "next\n"}, "done\nnext\n"},
+		{"redrawn line", []string{"50%", "\r100%\n"}, "100%\n"},
+		{"CRLF", []string{"a\r", "\nb\r\n"}, "a\nb\n"},
+		{"unfinished last line", []string{"one\n", "tw", "o"}, "one\ntwo"},
+		{"erased last line", []string{"one\n", clearLine + "⠋ thinking...", clearLine}, "one\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewTranscriptWriter(&buf)
+			for _, chunk := range tt.chunks {
+				if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+					t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptWriter_SkipsThinkingFrames(t *testing.T) {
+	var buf bytes.Buffer
+	transcript := NewTranscriptWriter(&buf)
+	d, out := newBufferedDisplay(VerbosityNormal)
+	d.Formatter.Writer = NewTeeWriter(out, transcript)
+	d.Thinking = NewThinkingIndicator(d.Formatter, time.Millisecond)
+
+	d.HandleEvent(toolUseEvent("t1", "Read", map[string]interface{}{"file_path": "a.go"}))
+	d.HandleEvent(toolResultEvent("t1", "package a", false))
+	time.Sleep(20 * time.Millisecond)
+	for _, e := range textBlockEvents("Done") {
+		d.HandleEvent(e)
+	}
+	d.Thinking.Disarm()
+	transcript.Close()
+
+	if !bytes.Contains(out.Bytes(), []byte(ThinkingLabel)) {
+		t.Fatalf("expected the placeholder on the display, got %q", out.String())
+	}
+	if bytes.Contains(buf.Bytes(), []byte(ThinkingLabel)) || !bytes.Contains(buf.Bytes(), []byte("● Done")) {
+		t.Errorf("expected the transcript without placeholder frames, got %q", buf.String())
+	}
+}
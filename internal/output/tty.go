@@ -60,3 +60,24 @@ func ShouldEnableColor(noColorFlag bool, configColorEnabled bool, displayFile *o
 	// Respect config file setting
 	return configColorEnabled
 }
+
+// ShouldEnableEmoji determines if emoji should be enabled based on:
+// 1. Explicit user flag (noEmojiFlag) - if true, emoji are disabled
+// 2. NO_EMOJI environment variable - if set, emoji are disabled (mirrors NO_COLOR)
+// 3. Config file setting (configEmojiEnabled) - user preference from config
+//
+// The priority is --no-emoji flag > NO_EMOJI env > config.
+func ShouldEnableEmoji(noEmojiFlag bool, configEmojiEnabled bool) bool {
+	// Explicit --no-emoji flag takes highest priority
+	if noEmojiFlag {
+		return false
+	}
+
+	// Respect NO_EMOJI environment variable, following the NO_COLOR convention
+	if _, exists := os.LookupEnv("NO_EMOJI"); exists {
+		return false
+	}
+
+	// Respect config file setting
+	return configEmojiEnabled
+}
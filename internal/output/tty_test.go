@@ -0,0 +1,36 @@
+package output
+
+import (
+	"os"
+	"testing"
+)
+
+func TestShouldEnableEmoji(t *testing.T) {
+	tests := []struct {
+		name        string
+		noEmojiFlag bool
+		config      bool
+		env         bool
+		want        bool
+	}{
+		{"config enabled", false, true, false, true},
+		{"config disabled", false, false, false, false},
+		{"flag overrides config", true, true, false, false},
+		{"env overrides config", false, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env {
+				t.Setenv("NO_EMOJI", "1")
+			} else {
+				// Ensure an inherited NO_EMOJI doesn't leak into the test.
+				t.Setenv("NO_EMOJI", "")
+				os.Unsetenv("NO_EMOJI")
+			}
+			if got := ShouldEnableEmoji(tt.noEmojiFlag, tt.config); got != tt.want {
+				t.Errorf("ShouldEnableEmoji(%v, %v) = %v, want %v", tt.noEmojiFlag, tt.config, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// TurnRecord captures one assistant turn: its message ID (a reference point
+// when choosing where to resume) and its token usage.
+type TurnRecord struct {
+	MessageID string
+	Usage     events.Usage
+}
+
+// trackTurnUsage accumulates the current assistant turn's ID and token usage:
+// input tokens from message_start, output tokens from message_delta (which
+// reports the running total for the message).
+func (d *Display) trackTurnUsage(e events.StreamEvent) {
+	turn := &d.State.CurrentTurn
+	switch e.Event.Type {
+	case "message_start":
+		*turn = TurnRecord{}
+		if m := e.Event.Message; m != nil {
+			turn.MessageID = m.ID
+			if m.Usage != nil {
+				turn.Usage.InputTokens = m.Usage.InputTokens
+				turn.Usage.OutputTokens = m.Usage.OutputTokens
+			}
+		}
+	case "message_delta":
+		if u := e.Event.Usage; u != nil {
+			if u.InputTokens > 0 {
+				turn.Usage.InputTokens = u.InputTokens
+			}
+			if u.OutputTokens > 0 {
+				turn.Usage.OutputTokens = u.OutputTokens
+			}
+		}
+	}
+}
+
+// completeTurnUsage records the current turn when its message stops.
+func (d *Display) completeTurnUsage() {
+	d.State.Turns = append(d.State.Turns, d.State.CurrentTurn)
+	d.State.CurrentTurn = TurnRecord{}
+}
+
+// formatTurnTokens formats a turn's usage as "1,200 in / 340 out".
+func formatTurnTokens(in, out int) string {
+	return fmt.Sprintf("%s in / %s out", formatCount(in), formatCount(out))
+}
+
+// showTurnUsageTable lists every turn's tokens, their sum, and the final
+// authoritative totals from the result event. Any difference (e.g. subagent
+// or background model calls) is shown so the table reconciles. The per-turn
+// record is cleared afterwards so the next session starts fresh.
+func (d *Display) showTurnUsageTable(e events.ResultEvent) {
+	if len(d.State.Turns) == 0 {
+		return
+	}
+
+	d.Formatter.Plain("")
+	d.Formatter.Plain("%sPer-Turn Usage:", d.indent(1))
+	sumIn, sumOut := 0, 0
+	for i, turn := range d.State.Turns {
+		u := turn.Usage
+		if turn.MessageID != "" {
+			d.Formatter.Plain("%sTurn %d [%s]: %s", d.indent(2), i+1, turn.MessageID, formatTurnTokens(u.InputTokens, u.OutputTokens))
+		} else {
+			d.Formatter.Plain("%sTurn %d: %s", d.indent(2), i+1, formatTurnTokens(u.InputTokens, u.OutputTokens))
+		}
+		sumIn += u.InputTokens
+		sumOut += u.OutputTokens
+	}
+	d.Formatter.Plain("%sSum of turns: %s", d.indent(2), formatTurnTokens(sumIn, sumOut))
+
+	finalIn, finalOut := calculateTotalTokens(e)
+	if finalIn != sumIn || finalOut != sumOut {
+		d.Formatter.Plain("%sFinal totals: %s (%+d in / %+d out outside these turns)", d.indent(2),
+			formatTurnTokens(finalIn, finalOut), finalIn-sumIn, finalOut-sumOut)
+	}
+
+	d.State.Turns = nil
+}
+
+// formatCount formats n with thousands separators (1200 -> "1,200").
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
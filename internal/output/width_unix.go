@@ -0,0 +1,58 @@
+//go:build !windows
+
+package output
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// winsize mirrors the kernel's struct winsize for TIOCGWINSZ.
+type winsize struct {
+	Row    uint16
+	Col    uint16
+	Xpixel uint16
+	Ypixel uint16
+}
+
+// TerminalWidth returns the column count of the terminal attached to f,
+// or 0 if f is not a terminal or the size cannot be determined.
+func TerminalWidth(f *os.File) int {
+	if !IsTTY(f) {
+		return 0
+	}
+	var ws winsize
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
+
+// WatchTerminalWidth calls onResize with the new width of f whenever the
+// terminal is resized (SIGWINCH). Returns a function that stops watching.
+func WatchTerminalWidth(f *os.File, onResize func(width int)) (stop func()) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGWINCH)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigChan:
+				if width := TerminalWidth(f); width > 0 {
+					onResize(width)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigChan)
+		close(done)
+	}
+}
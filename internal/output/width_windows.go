@@ -0,0 +1,16 @@
+//go:build windows
+
+package output
+
+import "os"
+
+// TerminalWidth on Windows is not detected; callers fall back to fixed
+// truncation widths.
+func TerminalWidth(f *os.File) int {
+	return 0
+}
+
+// WatchTerminalWidth on Windows is a no-op since there is no SIGWINCH.
+func WatchTerminalWidth(f *os.File, onResize func(width int)) (stop func()) {
+	return func() {}
+}
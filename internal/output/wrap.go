@@ -0,0 +1,53 @@
+package output
+
+import "strings"
+
+// writeStreamText writes a streamed text delta. With Wrap enabled and a
+// known terminal width, a line break is inserted whenever the text would
+// run past the last column, so long unbroken tokens (base64 blobs, URLs)
+// can't break terminal rendering. Only the display is wrapped; the text
+// kept for results and JSON output is unchanged.
+func (d *Display) writeStreamText(text string) {
+	if d.RenderTables {
+		d.bufferTableText(text)
+		return
+	}
+	d.emitStreamText(text)
+}
+
+// emitStreamText writes streamed text, wrapped as described for
+// writeStreamText, and keeps State.TextColumn current.
+func (d *Display) emitStreamText(text string) {
+	width := int(d.width.Load())
+	if !d.Wrap || width <= 0 {
+		if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+			d.State.TextColumn = visibleWidth(text[i+1:])
+		} else {
+			d.State.TextColumn += visibleWidth(text)
+		}
+		d.Formatter.PlainNoNewline("%s", text)
+		return
+	}
+	d.Formatter.PlainNoNewline("%s", d.wrapText(text, width))
+}
+
+// wrapText inserts line breaks into text so no line exceeds width columns,
+// continuing from State.TextColumn and updating it for the next delta.
+func (d *Display) wrapText(text string, width int) string {
+	var b strings.Builder
+	column := d.State.TextColumn
+	for _, r := range text {
+		if r == '\n' {
+			column = 0
+		} else {
+			if column >= width {
+				b.WriteByte('\n')
+				column = 0
+			}
+			column++
+		}
+		b.WriteRune(r)
+	}
+	d.State.TextColumn = column
+	return b.String()
+}
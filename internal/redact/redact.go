@@ -0,0 +1,77 @@
+// Package redact hides secrets in what claude-print writes to its logs:
+// the values of variables and parameters whose names look like they hold a
+// secret, and the credentials in URLs.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces a redacted value.
+const Placeholder = "[REDACTED]"
+
+// secretMarkers mark names whose values are redacted.
+var secretMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "CREDENTIAL"}
+
+// assignment matches NAME=value assignments in a shell command.
+var assignment = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)=("[^"]*"|'[^']*'|\S*)`)
+
+// urlUserinfo matches the scheme and user:password@ part of a URL.
+var urlUserinfo = regexp.MustCompile(`\b([A-Za-z][A-Za-z0-9+.-]*://)[^/?#@\s]+@`)
+
+// IsSecretName reports whether name, e.g. an environment variable or
+// parameter name, looks like it holds a secret.
+func IsSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns Placeholder if name looks like it holds a secret, otherwise
+// value.
+func Value(name, value string) string {
+	if IsSecretName(name) {
+		return Placeholder
+	}
+	return value
+}
+
+// Assignments returns command with the values of its secret-looking
+// NAME=value assignments redacted.
+func Assignments(command string) string {
+	return assignment.ReplaceAllStringFunc(command, func(m string) string {
+		name, _, _ := strings.Cut(m, "=")
+		if IsSecretName(name) {
+			return name + "=" + Placeholder
+		}
+		return m
+	})
+}
+
+// URLCredentials returns s with the userinfo (user:password@) of every URL
+// in it redacted, e.g. "http://[REDACTED]@proxy.corp:3128".
+func URLCredentials(s string) string {
+	return urlUserinfo.ReplaceAllString(s, "${1}"+Placeholder+"@")
+}
+
+// Args returns a copy of a command line with its secrets redacted: URL
+// credentials, and the values of secret-looking NAME=value assignments, also
+// when they are the value of a --flag=value argument.
+func Args(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		arg = URLCredentials(arg)
+		if flag, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(flag, "-") {
+			arg = flag + "=" + Assignments(value)
+		} else {
+			arg = Assignments(arg)
+		}
+		out[i] = arg
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package redact
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct{ name, value, want string }{
+		{"ANTHROPIC_API_KEY", "sk-123", Placeholder},
+		{"aws_session_token", "abc", Placeholder},
+		{"DB_PASSWORD", "hunter2", Placeholder},
+		{"TERM", "xterm", "xterm"},
+	}
+	for _, tt := range tests {
+		if got := Value(tt.name, tt.value); got != tt.want {
+			t.Errorf("Value(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAssignments(t *testing.T) {
+	got := Assignments(`API_KEY=abc DEBUG=1 TOKEN="x y" curl -H "x" https://example.com`)
+	want := `API_KEY=[REDACTED] DEBUG=1 TOKEN=[REDACTED] curl -H "x" https://example.com`
+	if got != want {
+		t.Errorf("Assignments() = %q, want %q", got, want)
+	}
+}
+
+func TestURLCredentials(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"http://user:pw@proxy.corp:3128", "http://[REDACTED]@proxy.corp:3128"},
+		{"--proxy=socks5://alice@10.0.0.1:1080/", "--proxy=socks5://[REDACTED]@10.0.0.1:1080/"},
+		{"http://proxy.corp:3128", "http://proxy.corp:3128"},
+		{"see https://example.com/a@b", "see https://example.com/a@b"},
+	}
+	for _, tt := range tests {
+		if got := URLCredentials(tt.in); got != tt.want {
+			t.Errorf("URLCredentials(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	args := []string{"claude-print", "--proxy", "http://u:pw@proxy:8080", "--env=ANTHROPIC_API_KEY=sk-1", "--env", "DB_TOKEN=x", "--env=DEBUG=1", "hi"}
+	want := []string{"claude-print", "--proxy", "http://[REDACTED]@proxy:8080", "--env=ANTHROPIC_API_KEY=[REDACTED]", "--env", "DB_TOKEN=[REDACTED]", "--env=DEBUG=1", "hi"}
+	if got := Args(args); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Args() = %q, want %q", got, want)
+	}
+	if args[2] != "http://u:pw@proxy:8080" {
+		t.Errorf("Args modified its input: %q", args)
+	}
+}
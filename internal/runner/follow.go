@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// followPollInterval is how often FollowEvents checks a file for new data.
+var followPollInterval = 200 * time.Millisecond
+
+// FollowEvents streams events from the JSONL file at path like "tail -f":
+// the existing lines first, then lines as they are appended, until stop is
+// closed. A truncated file is read again from the start, and a rotated one
+// (path replaced by a new file) is reopened. Debug log "#" lines are
+// skipped. Returns an error if the file cannot be opened.
+func FollowEvents(path string, stop <-chan struct{}) (<-chan events.Event, <-chan error, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := &followReader{path: path, file: file, stop: stop}
+	eventChan, errChan := streamEvents(reader, true)
+
+	// Close whichever file is current once streaming ends
+	closedErrChan := make(chan error, 1)
+	go func() {
+		err := <-errChan
+		reader.file.Close()
+		if err != nil {
+			closedErrChan <- err
+		}
+		close(closedErrChan)
+	}()
+	return eventChan, closedErrChan, nil
+}
+
+// followReader reads a growing file, waiting at EOF for more data instead
+// of returning io.EOF, until stop is closed.
+type followReader struct {
+	path   string
+	file   *os.File
+	offset int64
+	stop   <-chan struct{}
+}
+
+// Read implements io.Reader.
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		if n > 0 {
+			r.offset += int64(n)
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		// At EOF: wait for more data, watching for truncation and rotation
+		select {
+		case <-r.stop:
+			return 0, io.EOF
+		case <-time.After(followPollInterval):
+		}
+		if err := r.checkReplaced(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// checkReplaced rewinds the file if it was truncated, or reopens path if it
+// now names a different file. A path that is briefly missing mid-rotation
+// is waited for.
+func (r *followReader) checkReplaced() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return nil
+	}
+	current, err := r.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(info, current) {
+		file, err := os.Open(r.path)
+		if err != nil {
+			return nil
+		}
+		r.file.Close()
+		r.file, r.offset = file, 0
+		return nil
+	}
+	if info.Size() < r.offset {
+		if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r.offset = 0
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/peakflames/claude-print/internal/events"
+)
+
+// resultLine returns a result event line whose result text is s.
+func resultLine(s string) string {
+	return fmt.Sprintf("{\"type\":\"result\",\"subtype\":\"success\",\"result\":%q}\n", s)
+}
+
+// appendFile appends data to the file at path.
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// nextResult waits for the next event and returns its result text.
+func nextResult(t *testing.T, eventChan <-chan events.Event) string {
+	t.Helper()
+	select {
+	case e, ok := <-eventChan:
+		if !ok {
+			t.Fatal("event channel closed early")
+		}
+		result, ok := e.(events.ResultEvent)
+		if !ok {
+			t.Fatalf("expected ResultEvent, got %T", e)
+		}
+		return result.Result
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return ""
+}
+
+func TestFollowEvents(t *testing.T) {
+	followPollInterval = 5 * time.Millisecond
+	defer func() { followPollInterval = 200 * time.Millisecond }()
+
+	path := filepath.Join(t.TempDir(), "debug.jsonl")
+	appendFile(t, path, "# claude-print test\n"+resultLine("existing"))
+
+	stop := make(chan struct{})
+	eventChan, errChan, err := FollowEvents(path, stop)
+	if err != nil {
+		t.Fatalf("FollowEvents: %v", err)
+	}
+
+	if got := nextResult(t, eventChan); got != "existing" {
+		t.Errorf("got %q, want existing", got)
+	}
+
+	// Appended lines, including one written in two parts
+	appendFile(t, path, resultLine("appended"))
+	if got := nextResult(t, eventChan); got != "appended" {
+		t.Errorf("got %q, want appended", got)
+	}
+	line := resultLine("split")
+	appendFile(t, path, line[:10])
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, line[10:])
+	if got := nextResult(t, eventChan); got != "split" {
+		t.Errorf("got %q, want split", got)
+	}
+
+	// Truncated and rewritten in place
+	if err := os.WriteFile(path, []byte(resultLine("x")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := nextResult(t, eventChan); got != "x" {
+		t.Errorf("got %q after truncation, want x", got)
+	}
+
+	// Rotated: the path now names a new file
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, resultLine("rotated"))
+	if got := nextResult(t, eventChan); got != "rotated" {
+		t.Errorf("got %q after rotation, want rotated", got)
+	}
+
+	close(stop)
+	for range eventChan {
+	}
+	if err := <-errChan; err != nil {
+		t.Errorf("unexpected stream error: %v", err)
+	}
+}
+
+func TestFollowEvents_Missing(t *testing.T) {
+	if _, _, err := FollowEvents(filepath.Join(t.TempDir(), "missing.jsonl"), nil); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,55 @@
+package runner
+
+import "sync"
+
+// historySize is the number of raw event lines kept for crash diagnostics.
+const historySize = 32
+
+// EventHistory is a bounded ring buffer of the most recent raw event lines.
+// It is safe for concurrent use.
+type EventHistory struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// NewEventHistory creates a ring buffer holding at most size lines.
+func NewEventHistory(size int) *EventHistory {
+	if size < 1 {
+		size = 1
+	}
+	return &EventHistory{lines: make([]string, size)}
+}
+
+// Add records a line, evicting the oldest one when the buffer is full.
+func (h *EventHistory) Add(line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lines[h.next] = line
+	h.next = (h.next + 1) % len(h.lines)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Lines returns the buffered lines from oldest to newest.
+func (h *EventHistory) Lines() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]string(nil), h.lines[:h.next]...)
+	}
+	out := make([]string, 0, len(h.lines))
+	out = append(out, h.lines[h.next:]...)
+	return append(out, h.lines[:h.next]...)
+}
+
+// recentEvents holds the last raw lines read by StreamEvents.
+var recentEvents = NewEventHistory(historySize)
+
+// RecentEvents returns the most recent raw event lines read by StreamEvents,
+// oldest first. Used to report context when event handling panics.
+func RecentEvents() []string {
+	return recentEvents.Lines()
+}
@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventHistory(t *testing.T) {
+	h := NewEventHistory(3)
+	if got := h.Lines(); len(got) != 0 {
+		t.Fatalf("expected empty history, got %v", got)
+	}
+
+	h.Add("a")
+	h.Add("b")
+	if got := h.Lines(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("partial history = %v", got)
+	}
+
+	h.Add("c")
+	h.Add("d")
+	h.Add("e")
+	if got := h.Lines(); !reflect.DeepEqual(got, []string{"c", "d", "e"}) {
+		t.Errorf("wrapped history = %v", got)
+	}
+}
+
+func TestStreamEventsRecordsHistory(t *testing.T) {
+	input := `{"type":"system","subtype":"init"}` + "\n" + `not json` + "\n"
+	eventChan, _ := StreamEvents(strings.NewReader(input))
+	for range eventChan {
+	}
+
+	lines := RecentEvents()
+	if len(lines) < 2 {
+		t.Fatalf("expected at least 2 recorded lines, got %v", lines)
+	}
+	last := lines[len(lines)-2:]
+	if last[0] != `{"type":"system","subtype":"init"}` || last[1] != "not json" {
+		t.Errorf("unexpected recent lines: %v", last)
+	}
+}
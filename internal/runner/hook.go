@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// OnErrorTimeout bounds how long the --on-error command may run.
+const OnErrorTimeout = 30 * time.Second
+
+// OnEditTimeout bounds how long the --on-edit command may run for one file.
+const OnEditTimeout = 30 * time.Second
+
+// RunOnErrorHook runs command through the shell with env added to the
+// environment, killing it after OnErrorTimeout. Its output goes to stderr so
+// it can't mix with JSON on stdout.
+func RunOnErrorHook(command string, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), OnErrorTimeout)
+	defer cancel()
+
+	cmd := ShellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", OnErrorTimeout)
+	}
+	return err
+}
+
+// RunOnEditHook runs the --on-edit command for path through the shell,
+// killing it after OnEditTimeout. "{file}" in command is replaced by path,
+// quoted for the shell; path is also in CLAUDE_PRINT_FILE. Output is only
+// kept to explain a failure: the returned error ends with its last line.
+func RunOnEditHook(command, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), OnEditTimeout)
+	defer cancel()
+
+	cmd := ShellCommand(ctx, strings.ReplaceAll(command, "{file}", shellQuote(path)))
+	cmd.Env = append(os.Environ(), "CLAUDE_PRINT_FILE="+path)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", OnEditTimeout)
+	}
+	if err != nil {
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+			return fmt.Errorf("%w: %s", err, last)
+		}
+	}
+	return err
+}
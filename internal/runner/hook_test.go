@@ -0,0 +1,32 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunOnEditHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell command")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "it's a file.go")
+	out := filepath.Join(dir, "out")
+
+	// The path reaches the command as one word, and in the environment
+	if err := RunOnEditHook(`printf '%s|%s' {file} "$CLAUDE_PRINT_FILE" > `+out, path); err != nil {
+		t.Fatalf("RunOnEditHook: %v", err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != path+"|"+path {
+		t.Errorf("command saw %q, want the path twice", got)
+	}
+
+	// A failure is explained by the command's last line of output
+	err := RunOnEditHook("echo checking; echo 'bad syntax' >&2; exit 2", path)
+	if err == nil || !strings.HasSuffix(err.Error(), ": bad syntax") {
+		t.Errorf("expected the last output line in the error, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// ShellCommand returns a command that runs command through sh.
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// shellQuote quotes s as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
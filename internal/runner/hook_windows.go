@@ -0,0 +1,25 @@
+//go:build windows
+
+package runner
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// ShellCommand returns a command that runs command through cmd.exe. The
+// command line is passed as is: cmd doesn't follow the quoting rules
+// os/exec escapes arguments with, so escaped quotes would reach the
+// command. /S makes cmd strip just the outer quotes added here.
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + command + `"`}
+	return cmd
+}
+
+// shellQuote quotes s as a single word for cmd.exe.
+func shellQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -2,11 +2,17 @@ package runner
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/peakflames/claude-print/internal/cli"
 )
@@ -16,13 +22,42 @@ type RunOptions struct {
 	ClaudePath      string
 	Prompt          string
 	PassthroughArgs []string // Args to pass through to Claude unchanged
+	Model           string   // Default model, used only if PassthroughArgs has no --model
+	MaxTurns        int      // Default --max-turns, used only if positive and PassthroughArgs has none
+
+	// CLI dialect overrides for Claude-compatible CLIs. Empty values use the
+	// Claude CLI defaults (DefaultPromptFlag, DefaultRequiredArgs).
+	PromptFlag   string   // Flag that puts the CLI in non-interactive prompt mode
+	RequiredArgs []string // Flags needed for streaming JSON output
+
+	// Env holds extra "KEY=VALUE" environment variables for the Claude
+	// process, merged over the inherited environment; later entries win.
+	Env []string
+}
+
+// DefaultPromptFlag is the Claude CLI's non-interactive prompt flag.
+const DefaultPromptFlag = "-p"
+
+// DefaultRequiredArgs are the Claude CLI flags claude-print needs for
+// streaming JSON output with partial messages.
+var DefaultRequiredArgs = []string{
+	"--include-partial-messages",
+	"--verbose",
+	"--output-format=stream-json",
 }
 
+// stderrWaitDelay bounds how long Wait keeps collecting stderr after Claude
+// exits, in case a process it started still holds stderr open.
+const stderrWaitDelay = 5 * time.Second
+
 // ClaudeProcess represents a running Claude CLI process.
 type ClaudeProcess struct {
 	Cmd    *exec.Cmd
 	Stdout io.ReadCloser
 	stderr *bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
 }
 
 // RunClaude spawns the Claude CLI process with the given options and returns
@@ -40,8 +75,8 @@ func RunClaude(opts RunOptions) (*ClaudeProcess, error) {
 	args := buildArgs(opts)
 	cmd := exec.Command(opts.ClaudePath, args...)
 
-	// Inherit environment variables from parent process
-	cmd.Env = os.Environ()
+	// Inherit environment variables from parent process, plus any overrides
+	cmd.Env = mergeEnv(os.Environ(), opts.Env)
 
 	// Capture stdout as a pipe for streaming
 	stdout, err := cmd.StdoutPipe()
@@ -49,9 +84,11 @@ func RunClaude(opts RunOptions) (*ClaudeProcess, error) {
 		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	// Capture stderr to a buffer for error context
+	// Capture stderr to a buffer for error context. Wait finishes copying it
+	// into the buffer, so stdout ending doesn't mean stderr is complete.
 	var stderrBuf bytes.Buffer
 	cmd.Stderr = &stderrBuf
+	cmd.WaitDelay = stderrWaitDelay
 
 	// Pass prompt via stdin to avoid Windows command-line length limits
 	if opts.Prompt != "" {
@@ -70,9 +107,12 @@ func RunClaude(opts RunOptions) (*ClaudeProcess, error) {
 	}, nil
 }
 
-// Wait waits for the Claude CLI process to complete and returns any error.
+// Wait waits for the Claude CLI process to complete and for its stderr to
+// be fully captured, and returns any error. Read stdout to EOF first. It is
+// safe to call more than once; later calls return the first result.
 func (p *ClaudeProcess) Wait() error {
-	return p.Cmd.Wait()
+	p.waitOnce.Do(func() { p.waitErr = p.Cmd.Wait() })
+	return p.waitErr
 }
 
 // ExitCode returns the exit code of the process after it has completed.
@@ -84,6 +124,14 @@ func (p *ClaudeProcess) ExitCode() int {
 	return p.Cmd.ProcessState.ExitCode()
 }
 
+// Reaped reports whether the process's exit status was lost because
+// something else reaped it first, so Wait failed with ECHILD ("no child
+// processes"). This happens under some process supervisors that reap every
+// child. ExitCode is then -1. Call it after Wait.
+func (p *ClaudeProcess) Reaped() bool {
+	return errors.Is(p.Wait(), syscall.ECHILD)
+}
+
 // Kill terminates the Claude CLI process.
 func (p *ClaudeProcess) Kill() error {
 	if p.Cmd.Process == nil {
@@ -111,33 +159,103 @@ func (p *ClaudeProcess) Terminate() error {
 }
 
 // Stderr returns the stderr output captured from the Claude CLI process.
-// This should be called after the process has completed.
+// It waits for the process (see Wait) so the output is complete even when
+// stdout closed early, e.g. because Claude crashed.
 func (p *ClaudeProcess) Stderr() string {
 	if p.stderr == nil {
 		return ""
 	}
+	_ = p.Wait()
 	return p.stderr.String()
 }
 
+// RequiredArgs returns the flags Claude is always run with: configured, or
+// DefaultRequiredArgs when none are configured.
+func RequiredArgs(configured []string) []string {
+	if len(configured) == 0 {
+		return DefaultRequiredArgs
+	}
+	return configured
+}
+
+// ClaudeArgs returns the arguments the Claude CLI is run with for opts.
+func ClaudeArgs(opts RunOptions) []string {
+	return buildArgs(opts)
+}
+
 // buildArgs constructs the Claude CLI arguments from RunOptions.
 // Required flags for streaming JSON are prepended, then passthrough args, then prompt.
+//
+// With --continue/--resume in the passthrough args:
+//   - a prompt appends that input to the continued session (prompt flag added,
+//     prompt written to stdin);
+//   - no prompt resumes the session without new input (no prompt flag).
 func buildArgs(opts RunOptions) []string {
 	// Required flags for claude-print to work correctly
-	args := []string{
-		"--include-partial-messages",
-		"--verbose",
-		"--output-format=stream-json",
+	required := RequiredArgs(opts.RequiredArgs)
+	args := append([]string{}, required...)
+
+	// Append all passthrough args from user, skipping any already required
+	// (e.g. a --verbose meant for Claude) so none is passed twice
+	for _, arg := range opts.PassthroughArgs {
+		if !containsString(required, arg) {
+			args = append(args, arg)
+		}
 	}
 
-	// Append all passthrough args from user
-	args = append(args, opts.PassthroughArgs...)
+	// Inject the configured default model unless the user chose one explicitly
+	if opts.Model != "" && !cli.HasFlag(opts.PassthroughArgs, "--model") {
+		args = append(args, "--model", opts.Model)
+	}
+
+	// Likewise bound the run by the configured default turn limit
+	if opts.MaxTurns > 0 && !cli.HasFlag(opts.PassthroughArgs, "--max-turns") {
+		args = append(args, "--max-turns", strconv.Itoa(opts.MaxTurns))
+	}
 
 	// Prompt is delivered via stdin to avoid OS command-line length limits.
-	// The -p flag puts claude in non-interactive mode; the actual content is
-	// written to the process's stdin in RunClaude.
+	// The prompt flag (-p by default) puts claude in non-interactive mode; the
+	// actual content is written to the process's stdin in RunClaude.
 	if opts.Prompt != "" {
-		args = append(args, "-p")
+		promptFlag := opts.PromptFlag
+		if promptFlag == "" {
+			promptFlag = DefaultPromptFlag
+		}
+		args = append(args, promptFlag)
 	}
 
 	return args
 }
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeEnv returns base with each "KEY=VALUE" in extra applied: an existing
+// KEY is replaced in place, a new one is appended. Keys compare
+// case-insensitively on Windows, matching its environment semantics.
+func mergeEnv(base, extra []string) []string {
+	merged := append([]string(nil), base...)
+	for _, kv := range extra {
+		key, _, _ := strings.Cut(kv, "=")
+		replaced := false
+		for i, existing := range merged {
+			name, _, _ := strings.Cut(existing, "=")
+			if name == key || (runtime.GOOS == "windows" && strings.EqualFold(name, key)) {
+				merged[i] = kv
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, kv)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,190 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestBuildArgs_DefaultModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		passthrough []string
+		model       string
+		want        string
+	}{
+		{"no default", nil, "", "--include-partial-messages --verbose --output-format=stream-json -p"},
+		{"default injected", nil, "sonnet", "--include-partial-messages --verbose --output-format=stream-json --model sonnet -p"},
+		{"explicit model wins", []string{"--model", "opus"}, "sonnet", "--include-partial-messages --verbose --output-format=stream-json --model opus -p"},
+		{"explicit equals form wins", []string{"--model=opus"}, "sonnet", "--include-partial-messages --verbose --output-format=stream-json --model=opus -p"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildArgs(RunOptions{Prompt: "hi", PassthroughArgs: tt.passthrough, Model: tt.model})
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("buildArgs = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildArgs_DialectOverrides(t *testing.T) {
+	got := buildArgs(RunOptions{
+		Prompt:          "hi",
+		PassthroughArgs: []string{"--max-turns", "3"},
+		PromptFlag:      "--prompt",
+		RequiredArgs:    []string{"--stream", "--format=jsonl"},
+	})
+	want := "--stream --format=jsonl --max-turns 3 --prompt"
+	if strings.Join(got, " ") != want {
+		t.Errorf("buildArgs = %q, want %q", strings.Join(got, " "), want)
+	}
+
+	// Overriding must not mutate the defaults
+	buildArgs(RunOptions{Prompt: "hi", PassthroughArgs: []string{"--x"}})
+	if strings.Join(DefaultRequiredArgs, " ") != "--include-partial-messages --verbose --output-format=stream-json" {
+		t.Errorf("DefaultRequiredArgs mutated: %v", DefaultRequiredArgs)
+	}
+}
+
+func TestBuildArgs_SessionFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		prompt      string
+		passthrough []string
+		want        string
+	}{
+		{"continue alone resumes without input", "", []string{"--continue"}, "--include-partial-messages --verbose --output-format=stream-json --continue"},
+		{"prompt appended to continued session", "add tests", []string{"--continue"}, "--include-partial-messages --verbose --output-format=stream-json --continue -p"},
+		{"resume with prompt", "add tests", []string{"--resume", "abc"}, "--include-partial-messages --verbose --output-format=stream-json --resume abc -p"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildArgs(RunOptions{Prompt: tt.prompt, PassthroughArgs: tt.passthrough})
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("buildArgs = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeEnv(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "ANTHROPIC_BASE_URL=https://api.anthropic.com"}
+	got := mergeEnv(base, []string{"ANTHROPIC_BASE_URL=http://localhost:8080", "HTTPS_PROXY=http://proxy:3128"})
+	want := []string{"PATH=/usr/bin", "ANTHROPIC_BASE_URL=http://localhost:8080", "HTTPS_PROXY=http://proxy:3128"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeEnv = %v, want %v", got, want)
+	}
+	if base[1] != "ANTHROPIC_BASE_URL=https://api.anthropic.com" {
+		t.Error("mergeEnv modified its base slice")
+	}
+}
+
+func TestBuildArgs_DefaultMaxTurns(t *testing.T) {
+	tests := []struct {
+		name        string
+		passthrough []string
+		maxTurns    int
+		want        string
+	}{
+		{"no default", nil, 0, "--include-partial-messages --verbose --output-format=stream-json -p"},
+		{"default injected", nil, 10, "--include-partial-messages --verbose --output-format=stream-json --max-turns 10 -p"},
+		{"explicit limit wins", []string{"--max-turns", "3"}, 10, "--include-partial-messages --verbose --output-format=stream-json --max-turns 3 -p"},
+		{"explicit equals form wins", []string{"--max-turns=3"}, 10, "--include-partial-messages --verbose --output-format=stream-json --max-turns=3 -p"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildArgs(RunOptions{Prompt: "hi", PassthroughArgs: tt.passthrough, MaxTurns: tt.maxTurns})
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("buildArgs = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildArgs_NoDuplicateRequiredArgs(t *testing.T) {
+	got := buildArgs(RunOptions{Prompt: "hi", PassthroughArgs: []string{"--verbose", "--include-partial-messages", "--max-turns", "3"}})
+	want := "--include-partial-messages --verbose --output-format=stream-json --max-turns 3 -p"
+	if strings.Join(got, " ") != want {
+		t.Errorf("buildArgs = %q, want %q", strings.Join(got, " "), want)
+	}
+
+	// Only args among the required ones are dropped
+	got = buildArgs(RunOptions{PassthroughArgs: []string{"--verbose"}, RequiredArgs: []string{"--output-format=stream-json"}})
+	if strings.Join(got, " ") != "--output-format=stream-json --verbose" {
+		t.Errorf("buildArgs = %q", strings.Join(got, " "))
+	}
+}
+
+func TestClaudeProcess_ImmediateExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the Claude CLI")
+	}
+	// Reject the arguments at once, without reading stdin or writing stdout
+	script := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"error: unknown option '--bogus'\" >&2\nexit 2\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Repeat to shake out races between stdout ending and the exit status
+	for i := 0; i < 20; i++ {
+		process, err := RunClaude(RunOptions{ClaudePath: script, Prompt: "hi"})
+		if err != nil {
+			t.Fatalf("RunClaude: %v", err)
+		}
+		eventChan, errChan := StreamEventsFromProcess(process)
+		for event := range eventChan {
+			t.Fatalf("unexpected event %v", event)
+		}
+		if err := <-errChan; err != nil {
+			t.Fatalf("stream error: %v", err)
+		}
+
+		_ = process.Wait()
+		if stderr := process.Stderr(); stderr != "error: unknown option '--bogus'\n" {
+			t.Fatalf("run %d: Stderr() = %q", i, stderr)
+		}
+		if code := process.ExitCode(); code != 2 {
+			t.Fatalf("run %d: ExitCode() = %d, want 2", i, code)
+		}
+	}
+}
+
+func TestClaudeProcess_StderrAfterEarlyStdoutClose(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the Claude CLI")
+	}
+	// Close stdout first, then write a lot to stderr and fail, like a crash
+	script := filepath.Join(t.TempDir(), "claude")
+	body := "#!/bin/sh\nexec 1>&-\nsleep 0.1\n" +
+		"i=0; while [ $i -lt 2000 ]; do echo \"stack frame $i: somewhere in the crashing code\" >&2; i=$((i+1)); done\n" +
+		"echo 'fatal: out of memory' >&2\nexit 3\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	process, err := RunClaude(RunOptions{ClaudePath: script, Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("RunClaude: %v", err)
+	}
+	eventChan, errChan := StreamEventsFromProcess(process)
+	for range eventChan {
+	}
+	<-errChan
+
+	// Read stderr as soon as stdout ends, before waiting explicitly
+	stderr := process.Stderr()
+	if !strings.HasSuffix(stderr, "fatal: out of memory\n") || strings.Count(stderr, "\n") != 2001 {
+		t.Errorf("stderr incomplete: %d lines, ends %q", strings.Count(stderr, "\n"), stderr[max(0, len(stderr)-40):])
+	}
+	_ = process.Wait()
+	if code := process.ExitCode(); code != 3 {
+		t.Errorf("ExitCode() = %d, want 3", code)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package runner
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestClaudeProcess_Reaped(t *testing.T) {
+	// With SIGCHLD ignored the kernel reaps exited children itself, like a
+	// supervisor that reaps every process, so Wait finds no child
+	signal.Ignore(syscall.SIGCHLD)
+	defer signal.Reset(syscall.SIGCHLD)
+
+	script := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	process, err := RunClaude(RunOptions{ClaudePath: script, Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("RunClaude: %v", err)
+	}
+	eventChan, errChan := StreamEventsFromProcess(process)
+	for range eventChan {
+	}
+	<-errChan
+
+	if err := process.Wait(); !process.Reaped() || process.ExitCode() != -1 {
+		t.Errorf("Wait() = %v, Reaped() = %v, ExitCode() = %d; want a reaped process with code -1", err, process.Reaped(), process.ExitCode())
+	}
+}
@@ -2,35 +2,172 @@ package runner
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/peakflames/claude-print/internal/events"
+	"github.com/peakflames/claude-print/internal/redact"
 )
 
 // debugLogFile is the file handle for debug JSON logging (nil if not enabled)
 var debugLogFile *os.File
 
+// debugLogTypes restricts the debug log to these event types (nil logs all)
+var debugLogTypes map[string]bool
+
+// debugHidePrompt redacts the prompt from the argv recorded in the debug log
+// header (--hide-prompt)
+var debugHidePrompt bool
+
+// hiddenPromptArg replaces prompt arguments in the debug log header.
+const hiddenPromptArg = "[prompt hidden]"
+
+// SetDebugHidePrompt controls whether the debug log header redacts the
+// prompt from the recorded argv. Call it before EnableDebugLogging.
+func SetDebugHidePrompt(hide bool) {
+	debugHidePrompt = hide
+}
+
+// SetDebugLogFilter restricts the debug log to lines whose parsed event type
+// is in types. A type matches either the top-level "type" field (e.g.
+// "result", "assistant") or, for stream_event lines, the inner event type
+// (e.g. "message_stop"). Lines that fail to parse are always logged. An empty
+// list logs everything.
+func SetDebugLogFilter(types []string) {
+	debugLogTypes = nil
+	for _, t := range types {
+		if t = strings.TrimSpace(t); t != "" {
+			if debugLogTypes == nil {
+				debugLogTypes = make(map[string]bool)
+			}
+			debugLogTypes[t] = true
+		}
+	}
+}
+
+// debugLogWants reports whether event passes the debug log filter.
+func debugLogWants(event events.Event) bool {
+	if debugLogTypes == nil || debugLogTypes[event.EventType()] {
+		return true
+	}
+	if se, ok := event.(events.StreamEvent); ok {
+		return debugLogTypes[se.Event.Type]
+	}
+	return false
+}
+
+// writeDebugLine appends a raw line to the debug log, if enabled.
+func writeDebugLine(line string) {
+	if debugLogFile != nil {
+		debugLogFile.WriteString(line + "\n")
+		debugLogFile.Sync()
+	}
+}
+
 // EnableDebugLogging creates a timestamped log file in the specified directory
-// and logs all raw JSON lines to it. Call CloseDebugLogging when done.
-func EnableDebugLogging(dir string) error {
+// and logs all raw JSON lines to it. The file starts with a header describing
+// the invocation (see writeDebugHeader). Call CloseDebugLogging when done.
+func EnableDebugLogging(dir, version string, opts RunOptions) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 	timestamp := time.Now().Format("2006-01-02_150405")
 	filename := filepath.Join(dir, "stream-"+timestamp+".jsonl")
-	f, err := os.Create(filename)
+	if err := EnableDebugLogFile(filename, version, opts); err != nil {
+		return err
+	}
+	log.Printf("Debug logging to: %s", filename)
+	return nil
+}
+
+// EnableDebugLogFile is EnableDebugLogging with the log written to path,
+// whose directory must exist. Call CloseDebugLogging when done.
+func EnableDebugLogFile(path, version string, opts RunOptions) error {
+	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	debugLogFile = f
-	log.Printf("Debug logging to: %s", filename)
+	writeDebugHeader(f, version, opts)
 	return nil
 }
 
+// debugEnvPrefixes selects the environment variables recorded in the debug
+// log header.
+var debugEnvPrefixes = []string{"CLAUDE", "ANTHROPIC_", "AWS_", "GOOGLE_", "CLOUD_ML_", "VERTEX_", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "NO_COLOR", "NO_EMOJI", "TERM"}
+
+// writeDebugHeader writes a "#"-prefixed description of the invocation
+// (version, argv, Claude path and args, relevant environment) so the log is
+// self-describing without breaking line-based JSONL parsing. Secrets are
+// redacted throughout (see redact.Args).
+func writeDebugHeader(w io.Writer, version string, opts RunOptions) {
+	fmt.Fprintf(w, "# claude-print %s\n", version)
+	argv := os.Args
+	if debugHidePrompt {
+		argv = redactPromptArgs(argv, opts.Prompt)
+	}
+	fmt.Fprintf(w, "# argv: %q\n", redact.Args(argv))
+	fmt.Fprintf(w, "# claudePath: %s\n", opts.ClaudePath)
+	fmt.Fprintf(w, "# claudeArgs: %q\n", redact.Args(buildArgs(opts)))
+	for _, kv := range debugEnv(os.Environ()) {
+		fmt.Fprintf(w, "# env: %s\n", kv)
+	}
+	for _, kv := range opts.Env {
+		name, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(w, "# extraEnv: %s=%s\n", name, redact.Value(name, redact.URLCredentials(value)))
+	}
+	fmt.Fprintln(w, "# ---")
+}
+
+// debugEnv filters environ to the variables relevant to a Claude run, sorted,
+// with secret values and URL credentials (e.g. in HTTPS_PROXY) redacted.
+func debugEnv(environ []string) []string {
+	var out []string
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !hasAnyPrefix(name, debugEnvPrefixes) {
+			continue
+		}
+		out = append(out, name+"="+redact.Value(name, redact.URLCredentials(value)))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// redactPromptArgs returns a copy of argv with the prompt replaced by
+// hiddenPromptArg: any argument equal to prompt, and every argument after a
+// "--" terminator, since those are joined to form the prompt.
+func redactPromptArgs(argv []string, prompt string) []string {
+	out := make([]string, len(argv))
+	terminated := false
+	for i, arg := range argv {
+		switch {
+		case terminated || (prompt != "" && arg == prompt):
+			out[i] = hiddenPromptArg
+		default:
+			out[i] = arg
+			terminated = i > 0 && arg == "--"
+		}
+	}
+	return out
+}
+
+// hasAnyPrefix reports whether s starts with any of prefixes.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
 // CloseDebugLogging closes the debug log file if it's open
 func CloseDebugLogging() {
 	if debugLogFile != nil {
@@ -41,12 +178,31 @@ func CloseDebugLogging() {
 
 // StreamEvents reads lines from the given reader and emits parsed events
 // through a channel. Each line is expected to be a JSON event from Claude's
-// streaming output. Malformed JSON lines are logged and skipped.
-// The channel is closed when EOF is reached or an error occurs.
-func StreamEvents(reader io.Reader) <-chan events.Event {
+// streaming output. Malformed JSON lines are logged and skipped. Every raw
+// line is also kept in a small ring buffer (see RecentEvents).
+// The event channel is closed when EOF is reached or a read error occurs.
+// The error channel then yields the read error, if any (e.g. a broken pipe
+// or an oversized line), and is closed, so receiving from it after the event
+// channel closes returns nil for a clean EOF.
+func StreamEvents(reader io.Reader) (<-chan events.Event, <-chan error) {
+	return streamEvents(reader, false)
+}
+
+// StreamLogEvents is StreamEvents for a debug log being read back: its "#"
+// lines (the header and parse errors) are skipped.
+func StreamLogEvents(reader io.Reader) (<-chan events.Event, <-chan error) {
+	return streamEvents(reader, true)
+}
+
+// streamEvents is StreamEvents, also skipping "#" lines when skipComments
+// is set (FollowEvents, StreamLogEvents). From Claude, a "#" line is
+// malformed like any other non-JSON line.
+func streamEvents(reader io.Reader, skipComments bool) (<-chan events.Event, <-chan error) {
 	eventChan := make(chan events.Event)
+	errChan := make(chan error, 1)
 
 	go func() {
+		defer close(errChan)
 		defer close(eventChan)
 
 		scanner := bufio.NewScanner(reader)
@@ -55,41 +211,44 @@ func StreamEvents(reader io.Reader) <-chan events.Event {
 		scanner.Buffer(make([]byte, maxTokenSize), maxTokenSize)
 
 		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
+			// ScanLines drops one trailing \r, but CRLF output that went through
+			// another newline translation can leave more; strip them all so
+			// they never reach parsed string fields or the debug log.
+			line := strings.TrimRight(scanner.Text(), "\r")
+			if line == "" || (skipComments && strings.HasPrefix(line, "#")) {
 				continue
 			}
 
-			// Write raw JSON to debug log if enabled
-			if debugLogFile != nil {
-				debugLogFile.WriteString(line + "\n")
-				debugLogFile.Sync()
-			}
+			recentEvents.Add(line)
 
 			event, err := events.ParseEvent(line)
 			if err != nil {
 				log.Printf("Warning: skipping malformed JSON line: %v", err)
-				// Log the actual line content when we have a parse error
-				if debugLogFile != nil {
-					debugLogFile.WriteString("# PARSE ERROR: " + err.Error() + "\n")
-				}
+				// Always log unparseable lines, followed by the parse error
+				writeDebugLine(line)
+				writeDebugLine("# PARSE ERROR: " + err.Error())
 				continue
 			}
 
+			// Write raw JSON to debug log if enabled and not filtered out
+			if debugLogWants(event) {
+				writeDebugLine(line)
+			}
+
 			eventChan <- event
 		}
 
-		// Handle scanner errors (if any)
+		// Report scanner errors so a broken stream isn't mistaken for EOF
 		if err := scanner.Err(); err != nil {
-			log.Printf("Warning: error reading stream: %v", err)
+			errChan <- err
 		}
 	}()
 
-	return eventChan
+	return eventChan, errChan
 }
 
 // StreamEventsFromProcess is a convenience function that streams events
 // from a ClaudeProcess's stdout.
-func StreamEventsFromProcess(process *ClaudeProcess) <-chan events.Event {
+func StreamEventsFromProcess(process *ClaudeProcess) (<-chan events.Event, <-chan error) {
 	return StreamEvents(process.Stdout)
 }
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// watchPollInterval is how often a FileWatcher checks the watched paths, and
+// watchDebounce how long they must stay unchanged before a change is
+// reported, so an editor's save (or a burst of writes) triggers one run.
+var (
+	watchPollInterval = 250 * time.Millisecond
+	watchDebounce     = 300 * time.Millisecond
+)
+
+// fileStamp is what a FileWatcher compares to notice a file has changed.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// FileWatcher reports changes to the files matching glob patterns. It polls
+// like FollowEvents rather than using OS file notifications, which would
+// need a third-party package (fsnotify) in a module that has none. Patterns
+// use filepath.Match syntax, so `**` is not supported: each pattern matches
+// one directory level.
+type FileWatcher struct {
+	patterns []string
+}
+
+// NewFileWatcher creates a FileWatcher for the glob patterns. Returns an
+// error for a malformed pattern.
+func NewFileWatcher(patterns []string) (*FileWatcher, error) {
+	for _, pattern := range patterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	return &FileWatcher{patterns: patterns}, nil
+}
+
+// WaitForChange snapshots the watched files, then waits until some are
+// created, modified or removed and stay quiet for the debounce period, and
+// returns the sorted list of changed paths. Changes made before the call,
+// such as Claude's own edits during the previous run, are not reported.
+// Patterns are re-expanded on every poll, so new files that match are
+// picked up. Returns nil if stop is closed first.
+func (w *FileWatcher) WaitForChange(stop <-chan struct{}) []string {
+	last := snapshotFiles(w.patterns)
+	pending := map[string]bool{}
+	var lastChange time.Time
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(watchPollInterval):
+		}
+
+		current := snapshotFiles(w.patterns)
+		if changed := diffSnapshots(last, current); len(changed) > 0 {
+			for _, path := range changed {
+				pending[path] = true
+			}
+			lastChange = time.Now()
+		}
+		last = current
+
+		if len(pending) == 0 || time.Since(lastChange) < watchDebounce {
+			continue
+		}
+		paths := make([]string, 0, len(pending))
+		for path := range pending {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+}
+
+// snapshotFiles stamps every regular file matching patterns. Files that
+// vanish between the glob and the stat are left out.
+func snapshotFiles(patterns []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, pattern := range patterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+// diffSnapshots returns the paths added, removed or modified between two
+// snapshots.
+func diffSnapshots(before, after map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range after {
+		if old, ok := before[path]; !ok || old != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
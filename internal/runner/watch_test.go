@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// nextChange runs WaitForChange in the background and returns a function
+// that waits for its result.
+func nextChange(t *testing.T, w *FileWatcher) func() []string {
+	t.Helper()
+	result := make(chan []string, 1)
+	go func() { result <- w.WaitForChange(nil) }()
+	// Let the watcher take its snapshot before the test changes anything
+	time.Sleep(10 * watchPollInterval)
+	return func() []string {
+		t.Helper()
+		select {
+		case paths := <-result:
+			return paths
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for a change")
+		}
+		return nil
+	}
+}
+
+func TestFileWatcher(t *testing.T) {
+	watchPollInterval, watchDebounce = 5*time.Millisecond, 30*time.Millisecond
+	defer func() { watchPollInterval, watchDebounce = 250*time.Millisecond, 300*time.Millisecond }()
+
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.go")
+	appendFile(t, main, "package main\n")
+	appendFile(t, filepath.Join(dir, "notes.txt"), "ignored\n")
+
+	w, err := NewFileWatcher([]string{filepath.Join(dir, "*.go")})
+	if err != nil {
+		t.Fatalf("NewFileWatcher: %v", err)
+	}
+
+	// A burst of writes to several files is reported once
+	wait := nextChange(t, w)
+	extra := filepath.Join(dir, "extra.go")
+	appendFile(t, main, "// one\n")
+	appendFile(t, extra, "package main\n")
+	appendFile(t, main, "// two\n")
+	if got, want := wait(), []string{extra, main}; !slices.Equal(got, want) {
+		t.Errorf("first change = %v, want %v", got, want)
+	}
+
+	// Changes made between waits, like Claude's edits during a run, are
+	// not reported
+	appendFile(t, main, "// edited during the run\n")
+
+	// Files that don't match the pattern are ignored; removals are reported
+	wait = nextChange(t, w)
+	appendFile(t, filepath.Join(dir, "notes.txt"), "still ignored\n")
+	if err := os.Remove(extra); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := wait(), []string{extra}; !slices.Equal(got, want) {
+		t.Errorf("second change = %v, want %v", got, want)
+	}
+}
+
+func TestFileWatcher_Stop(t *testing.T) {
+	w, err := NewFileWatcher([]string{filepath.Join(t.TempDir(), "*.go")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	close(stop)
+	if got := w.WaitForChange(stop); got != nil {
+		t.Errorf("WaitForChange() = %v after stop, want nil", got)
+	}
+}
+
+func TestNewFileWatcher_BadPattern(t *testing.T) {
+	if _, err := NewFileWatcher([]string{"[unclosed"}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}